@@ -1,20 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
+	"vitaliiPsl/synthesizer/internal/audio"
 	"vitaliiPsl/synthesizer/internal/auth"
 	"vitaliiPsl/synthesizer/internal/auth/jwt"
+	"vitaliiPsl/synthesizer/internal/auth/session"
 	"vitaliiPsl/synthesizer/internal/auth/sso"
+	"vitaliiPsl/synthesizer/internal/batch"
+	"vitaliiPsl/synthesizer/internal/cache"
 	"vitaliiPsl/synthesizer/internal/database"
 	"vitaliiPsl/synthesizer/internal/email"
 	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/job"
+	"vitaliiPsl/synthesizer/internal/lexicon"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/model"
 	"vitaliiPsl/synthesizer/internal/router"
 	"vitaliiPsl/synthesizer/internal/server"
+	"vitaliiPsl/synthesizer/internal/storage"
 	"vitaliiPsl/synthesizer/internal/synthesis"
 	"vitaliiPsl/synthesizer/internal/token"
 	"vitaliiPsl/synthesizer/internal/users"
@@ -28,10 +39,16 @@ func main() {
 
 	database.SetupDatabase()
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
 	server := server.New()
 
+	sessionRepository := session.NewSessionRepository(database.DB)
+	sessionService := session.NewSessionService(sessionRepository)
+
 	userRepository := users.NewUserRepository(database.DB)
-	userService := users.NewUserService(userRepository)
+	userService := users.NewUserService(userRepository, sessionService)
 
 	tokenRepository := token.NewTokenRepository(database.DB)
 	tokenService := token.NewTokenService(tokenRepository)
@@ -46,26 +63,83 @@ func main() {
 	githubProvider := sso.NewGithubProvider(githubConfig)
 	ssoProviders := map[string]sso.SSOProvider{"github": githubProvider}
 
-	authenticationService := auth.NewAuthService(userService, tokenService, emailService, jwtService, ssoProviders)
+	authenticationService := auth.NewAuthService(userService, tokenService, emailService, jwtService, sessionService, ssoProviders)
 	authenticationControler := auth.NewAuthController(authenticationService, validationService)
-	authenticationMiddleware := auth.NewAuthMiddleware(jwtService, userService)
+	authenticationMiddleware := auth.NewAuthMiddleware(jwtService, userService, sessionService)
 
 	modelRepository := model.NewModelRepository(database.DB)
-	modelService := model.NewModelService(modelRepository)
-	modelController := model.NewModelController(modelService, validationService)
+	cacheRepository := cache.NewCacheRepository(database.DB)
+	synthesisCache := cache.NewSynthesisCache(cacheRepository)
+	cacheController := cache.NewCacheController(synthesisCache)
+
+	blobStorage := storage.NewBlobStorage()
+	replicaBalancer := model.NewReplicaBalancer()
+	modelService := model.NewModelService(modelRepository, blobStorage, replicaBalancer, synthesisCache)
+	replicaService := model.NewReplicaService(modelRepository, replicaBalancer)
+	versionService := model.NewVersionService(modelRepository)
+	registryService := model.NewRegistryService(modelRepository, validationService, synthesisCache)
+	modelController := model.NewModelController(modelService, replicaService, versionService, registryService, validationService)
+	replicaHealthChecker := model.NewReplicaHealthChecker(modelRepository, replicaBalancer)
+	replicaHealthChecker.Start(ctx)
 
 	historyRepository := history.NewHistoryRepository(database.DB)
-	historyService := history.NewHistoryService(historyRepository)
+	historyService := history.NewHistoryService(historyRepository, blobStorage)
 	historyController := history.NewHistoryController(historyService)
 
-	synthesisService := synthesis.NewSynthesisService(modelService, historyService)
-	synthesisController := synthesis.NewSynthesisController(synthesisService, validationService)
-
-	router.SetupRoutes(server.App, authenticationMiddleware, authenticationControler, modelController, synthesisController, historyController)
+	audioEncoders := map[audio.AudioFormat]audio.AudioEncoder{
+		audio.FormatWav: audio.NewWavEncoder(),
+	}
+	if audio.FfmpegAvailable() {
+		for _, format := range []audio.AudioFormat{audio.FormatMp3, audio.FormatOgg, audio.FormatFlac} {
+			audioEncoders[format] = audio.NewFfmpegEncoder(format)
+		}
+	} else {
+		logger.Logger.Warn("ffmpeg not found, only wav output is available")
+	}
+	audioService := audio.NewAudioService(audioEncoders)
+
+	modelHttpClient := &http.Client{}
+	modelBackends := map[model.ModelProtocol]synthesis.ModelBackend{
+		model.ProtocolJson:   synthesis.NewJsonBackend(modelHttpClient),
+		model.ProtocolWav:    synthesis.NewWavBackend(modelHttpClient),
+		model.ProtocolOpenAi: synthesis.NewOpenAiBackend(modelHttpClient),
+		model.ProtocolGrpc:   synthesis.NewGrpcBackend(),
+	}
+	modelClient := synthesis.NewModelClient(replicaBalancer, modelBackends)
+	textNormalizer := synthesis.NewTextNormalizer()
+	lexiconRepository := lexicon.NewLexiconRepository(database.DB)
+	lexiconService := lexicon.NewLexiconService(lexiconRepository)
+	lexiconController := lexicon.NewLexiconController(lexiconService, validationService)
+
+	synthesisService := synthesis.NewSynthesisService(modelService, historyService, audioService, synthesisCache, modelClient, textNormalizer, lexiconService)
+	synthesisController := synthesis.NewSynthesisController(synthesisService, audioService, validationService)
+
+	batchService := batch.NewBatchService(synthesisService, audioService)
+	batchController := batch.NewBatchController(batchService, validationService)
+
+	jobRepository := job.NewJobRepository(database.DB)
+	jobService := job.NewJobService(jobRepository, synthesisService, historyService, audioService)
+	jobController := job.NewJobController(jobService, validationService)
+	jobWorkerPool := job.NewJobWorkerPool(jobService)
+	jobWorkerPool.Start(ctx)
+
+	router.SetupRoutes(server.App, authenticationMiddleware, authenticationControler, modelController, synthesisController, historyController, jobController, cacheController, lexiconController, batchController)
+
+	go func() {
+		<-ctx.Done()
+		logger.Logger.Info("Shutting down Synthesizer...")
+		if err := server.Shutdown(); err != nil {
+			logger.Logger.Error("Failed to shut down server", "error", err)
+		}
+	}()
 
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 	err := server.Listen(fmt.Sprintf(":%d", port))
 	if err != nil {
 		panic(fmt.Sprintf("cannot start server: %s", err))
 	}
+
+	stop()
+	jobWorkerPool.Wait()
+	logger.Logger.Info("Shut down Synthesizer.")
 }
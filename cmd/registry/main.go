@@ -0,0 +1,146 @@
+// Command registry exports the model registry to a file and syncs it back
+// from one, applying the same validation and diff as the admin API.
+//
+//	registry sync -file models.yaml [-dry-run] [-prune]
+//	registry export [-format yaml] [-out models.yaml]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"vitaliiPsl/synthesizer/internal/cache"
+	"vitaliiPsl/synthesizer/internal/database"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/validation"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	// stdout is reserved for the command's output
+	logger.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "sync":
+		err = runSync(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: registry sync -file <path> [-dry-run] [-prune]")
+	fmt.Fprintln(os.Stderr, "       registry export [-format json|yaml] [-out <path>]")
+	os.Exit(2)
+}
+
+func runSync(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	file := flags.String("file", "", "registry file to apply (.json, .yaml or .yml)")
+	dryRun := flags.Bool("dry-run", false, "print the changes without applying them")
+	prune := flags.Bool("prune", false, "archive models missing from the file")
+	flags.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	document, err := model.DecodeRegistry(data, model.RegistryFormatFromPath(*file))
+	if err != nil {
+		return fmt.Errorf("invalid registry file: %w", err)
+	}
+
+	// a checked-in file is the source of truth, its revision is only checked
+	// when it carries one
+	diff, err := newRegistryService().Import(document, model.RegistryImportOptions{DryRun: *dryRun, Prune: *prune})
+	if err != nil {
+		return err
+	}
+
+	printDiff(diff)
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	formatName := flags.String("format", "", "output format, json or yaml (defaults to the -out extension)")
+	out := flags.String("out", "", "file to write, stdout when empty")
+	flags.Parse(args)
+
+	format := model.RegistryFormatFromPath(*out)
+	if *formatName != "" {
+		var ok bool
+		if format, ok = model.ParseRegistryFormat(*formatName); !ok {
+			return fmt.Errorf("unsupported format: %s", *formatName)
+		}
+	}
+
+	document, err := newRegistryService().Export()
+	if err != nil {
+		return err
+	}
+
+	data, err := model.EncodeRegistry(document, format)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return os.WriteFile(*out, data, 0o644)
+}
+
+func newRegistryService() model.RegistryService {
+	database.SetupDatabase()
+
+	modelRepository := model.NewModelRepository(database.DB)
+	synthesisCache := cache.NewSynthesisCache(cache.NewCacheRepository(database.DB))
+
+	return model.NewRegistryService(modelRepository, validation.NewValidationService(), synthesisCache)
+}
+
+func printDiff(diff *model.RegistryDiff) {
+	if len(diff.Changes) == 0 {
+		fmt.Println("registry is up to date")
+	}
+
+	for _, change := range diff.Changes {
+		line := fmt.Sprintf("%-8s %s (%s)", change.Action, change.Name, change.Language)
+		if len(change.Fields) > 0 {
+			line += ": " + strings.Join(change.Fields, ", ")
+		}
+		fmt.Println(line)
+	}
+
+	if diff.DryRun {
+		fmt.Printf("dry run, %d change(s) not applied\n", len(diff.Changes))
+		return
+	}
+
+	fmt.Printf("applied %d change(s), revision %s\n", len(diff.Changes), diff.Revision)
+}
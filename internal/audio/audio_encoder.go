@@ -0,0 +1,16 @@
+package audio
+
+type AudioEncoder interface {
+	Encode(samples []float32, samplingRate int) ([]byte, error)
+}
+
+// implemented by encoders that can store integer PCM at more than one depth
+type BitDepthEncoder interface {
+	EncodeWithBitDepth(samples []float32, samplingRate, bitDepth int) ([]byte, error)
+}
+
+type EncodedAudio struct {
+	Data        []byte
+	Format      AudioFormat
+	ContentType string
+}
@@ -0,0 +1,69 @@
+package audio
+
+import "strings"
+
+type AudioFormat string
+
+const (
+	FormatJson AudioFormat = "json"
+	FormatWav  AudioFormat = "wav"
+	FormatMp3  AudioFormat = "mp3"
+	FormatOgg  AudioFormat = "ogg"
+	FormatFlac AudioFormat = "flac"
+)
+
+var contentTypes = map[AudioFormat]string{
+	FormatJson: "application/json",
+	FormatWav:  "audio/wav",
+	FormatMp3:  "audio/mpeg",
+	FormatOgg:  "audio/ogg",
+	FormatFlac: "audio/flac",
+}
+
+var contentTypeAliases = map[string]AudioFormat{
+	"audio/x-wav":  FormatWav,
+	"audio/wave":   FormatWav,
+	"audio/mp3":    FormatMp3,
+	"audio/x-flac": FormatFlac,
+}
+
+func (f AudioFormat) ContentType() string {
+	return contentTypes[f]
+}
+
+func (f AudioFormat) Extension() string {
+	return string(f)
+}
+
+func ParseAudioFormat(value string) (AudioFormat, bool) {
+	format := AudioFormat(strings.ToLower(strings.TrimSpace(value)))
+	_, ok := contentTypes[format]
+	return format, ok
+}
+
+func FormatFromContentType(contentType string) (AudioFormat, bool) {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	for format, value := range contentTypes {
+		if value == contentType {
+			return format, true
+		}
+	}
+
+	format, ok := contentTypeAliases[contentType]
+	return format, ok
+}
+
+func ContentTypes() []string {
+	return []string{
+		contentTypes[FormatJson],
+		contentTypes[FormatWav],
+		contentTypes[FormatMp3],
+		contentTypes[FormatOgg],
+		contentTypes[FormatFlac],
+		"audio/x-wav",
+		"audio/wave",
+		"audio/mp3",
+		"audio/x-flac",
+	}
+}
@@ -0,0 +1,55 @@
+package audio
+
+import (
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+)
+
+type AudioService interface {
+	Encode(samples []float32, samplingRate int, format AudioFormat) (*EncodedAudio, error)
+	EncodeWithBitDepth(samples []float32, samplingRate, bitDepth int, format AudioFormat) (*EncodedAudio, error)
+	Supports(format AudioFormat) bool
+}
+
+type AudioServiceImpl struct {
+	encoders map[AudioFormat]AudioEncoder
+}
+
+func NewAudioService(encoders map[AudioFormat]AudioEncoder) *AudioServiceImpl {
+	return &AudioServiceImpl{encoders: encoders}
+}
+
+func (s *AudioServiceImpl) Encode(samples []float32, samplingRate int, format AudioFormat) (*EncodedAudio, error) {
+	return s.EncodeWithBitDepth(samples, samplingRate, 0, format)
+}
+
+// a zero bit depth, or one the format's encoder doesn't support, keeps the encoder default
+func (s *AudioServiceImpl) EncodeWithBitDepth(samples []float32, samplingRate, bitDepth int, format AudioFormat) (*EncodedAudio, error) {
+	logger.Logger.Info("Encoding audio...", "format", format, "samples", len(samples), "samplingRate", samplingRate, "bitDepth", bitDepth)
+
+	encoder, ok := s.encoders[format]
+	if !ok {
+		logger.Logger.Error("Unsupported audio format", "format", format)
+		return nil, service_errors.NewErrBadRequest("Unsupported audio format: " + string(format))
+	}
+
+	var data []byte
+	var err error
+	if depthEncoder, ok := encoder.(BitDepthEncoder); ok && bitDepth > 0 {
+		data, err = depthEncoder.EncodeWithBitDepth(samples, samplingRate, bitDepth)
+	} else {
+		data, err = encoder.Encode(samples, samplingRate)
+	}
+	if err != nil {
+		logger.Logger.Error("Failed to encode audio", "format", format, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to encode audio")
+	}
+
+	logger.Logger.Info("Encoded audio.", "format", format, "size", len(data))
+	return &EncodedAudio{Data: data, Format: format, ContentType: format.ContentType()}, nil
+}
+
+func (s *AudioServiceImpl) Supports(format AudioFormat) bool {
+	_, ok := s.encoders[format]
+	return ok
+}
@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+var ffmpegCodecArgs = map[AudioFormat][]string{
+	FormatMp3:  {"-c:a", "libmp3lame", "-q:a", "4", "-f", "mp3"},
+	FormatOgg:  {"-c:a", "libvorbis", "-q:a", "4", "-f", "ogg"},
+	FormatFlac: {"-c:a", "flac", "-f", "flac"},
+}
+
+type FfmpegEncoder struct {
+	binary string
+	format AudioFormat
+}
+
+func NewFfmpegEncoder(format AudioFormat) *FfmpegEncoder {
+	return &FfmpegEncoder{binary: ffmpegBinary(), format: format}
+}
+
+// FfmpegAvailable reports whether the ffmpeg binary can be found, so the
+// compressed formats are only offered where they can be encoded.
+func FfmpegAvailable() bool {
+	_, err := exec.LookPath(ffmpegBinary())
+	return err == nil
+}
+
+func ffmpegBinary() string {
+	binary := os.Getenv("FFMPEG_PATH")
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+
+	return binary
+}
+
+func (e *FfmpegEncoder) Encode(samples []float32, samplingRate int) ([]byte, error) {
+	codecArgs, ok := ffmpegCodecArgs[e.format]
+	if !ok {
+		return nil, fmt.Errorf("ffmpeg encoder does not support format %q", e.format)
+	}
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-f", "f32le", "-ar", strconv.Itoa(samplingRate), "-ac", "1", "-i", "pipe:0",
+	}
+	args = append(args, codecArgs...)
+	args = append(args, "pipe:1")
+
+	input := new(bytes.Buffer)
+	if err := binary.Write(input, binary.LittleEndian, samples); err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(e.binary, args...)
+	cmd.Stdin = input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
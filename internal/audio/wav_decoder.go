@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	wavFormatPcm        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+type DecodedAudio struct {
+	// interleaved when there's more than one channel
+	Samples      []float32
+	SamplingRate int
+	Channels     int
+}
+
+// DecodeWav reads integer PCM (8 to 32 bit) and 32 bit float wav files. A
+// data chunk with an unknown size, as written by streaming servers, runs to
+// the end of the file.
+func DecodeWav(data []byte) (*DecodedAudio, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("not a wav file")
+	}
+
+	var format, channels, bitsPerSample int
+	var samplingRate int
+	haveFormat := false
+
+	offset := 12
+	for offset+8 <= len(data) {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		if size > len(body) || size < 0 {
+			size = len(body)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("wav format chunk is too short")
+			}
+
+			format = int(binary.LittleEndian.Uint16(body[0:2]))
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			samplingRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+			if format == wavFormatExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(body[24:26]))
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, errors.New("wav data chunk precedes format chunk")
+			}
+
+			samples, err := decodePcm(body[:size], format, bitsPerSample)
+			if err != nil {
+				return nil, err
+			}
+
+			return &DecodedAudio{Samples: samples, SamplingRate: samplingRate, Channels: max(1, channels)}, nil
+		}
+
+		// chunks are padded to an even size
+		offset += 8 + size + size%2
+	}
+
+	return nil, errors.New("wav file has no data chunk")
+}
+
+func decodePcm(data []byte, format, bitsPerSample int) ([]float32, error) {
+	if format == wavFormatFloat && bitsPerSample == 32 {
+		samples := make([]float32, len(data)/4)
+		for i := range samples {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+
+		return samples, nil
+	}
+
+	if format != wavFormatPcm {
+		return nil, fmt.Errorf("unsupported wav format %d with %d bits", format, bitsPerSample)
+	}
+
+	width := bitsPerSample / 8
+	if width < 1 || width > 4 || bitsPerSample%8 != 0 {
+		return nil, fmt.Errorf("unsupported wav bit depth %d", bitsPerSample)
+	}
+
+	scale := math.Pow(2, float64(bitsPerSample-1))
+	samples := make([]float32, len(data)/width)
+	for i := range samples {
+		frame := data[i*width : (i+1)*width]
+
+		var value int32
+		if width == 1 {
+			// 8 bit wav is unsigned
+			value = int32(frame[0]) - 128
+		} else {
+			for j := width - 1; j >= 0; j-- {
+				value = value<<8 | int32(frame[j])
+			}
+			// sign extend from the sample width
+			shift := 32 - bitsPerSample
+			value = value << shift >> shift
+		}
+
+		samples[i] = float32(float64(value) / scale)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	wavBitsPerSample = 16
+	wavChannels      = 1
+	wavHeaderSize    = 44
+)
+
+type WavEncoder struct{}
+
+func NewWavEncoder() *WavEncoder {
+	return &WavEncoder{}
+}
+
+func (e *WavEncoder) Encode(samples []float32, samplingRate int) ([]byte, error) {
+	return e.EncodeWithBitDepth(samples, samplingRate, wavBitsPerSample)
+}
+
+func (e *WavEncoder) EncodeWithBitDepth(samples []float32, samplingRate, bitDepth int) ([]byte, error) {
+	if bitDepth != 8 && bitDepth != 16 && bitDepth != 24 && bitDepth != 32 {
+		return nil, fmt.Errorf("unsupported wav bit depth %d", bitDepth)
+	}
+
+	blockAlign := wavChannels * bitDepth / 8
+	dataSize := len(samples) * blockAlign
+
+	buffer := bytes.NewBuffer(make([]byte, 0, wavHeaderSize+dataSize))
+
+	buffer.WriteString("RIFF")
+	binary.Write(buffer, binary.LittleEndian, uint32(wavHeaderSize-8+dataSize))
+	buffer.WriteString("WAVE")
+
+	buffer.WriteString("fmt ")
+	binary.Write(buffer, binary.LittleEndian, uint32(16))
+	binary.Write(buffer, binary.LittleEndian, uint16(1))
+	binary.Write(buffer, binary.LittleEndian, uint16(wavChannels))
+	binary.Write(buffer, binary.LittleEndian, uint32(samplingRate))
+	binary.Write(buffer, binary.LittleEndian, uint32(samplingRate*blockAlign))
+	binary.Write(buffer, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(buffer, binary.LittleEndian, uint16(bitDepth))
+
+	buffer.WriteString("data")
+	binary.Write(buffer, binary.LittleEndian, uint32(dataSize))
+
+	pcm := make([]byte, dataSize)
+	for i, sample := range samples {
+		offset := i * blockAlign
+		value := toPcm(sample, bitDepth)
+
+		switch bitDepth {
+		case 8:
+			// 8 bit wav is unsigned
+			pcm[offset] = byte(value + 128)
+		case 16:
+			binary.LittleEndian.PutUint16(pcm[offset:], uint16(value))
+		case 24:
+			pcm[offset] = byte(value)
+			pcm[offset+1] = byte(value >> 8)
+			pcm[offset+2] = byte(value >> 16)
+		case 32:
+			binary.LittleEndian.PutUint32(pcm[offset:], uint32(value))
+		}
+	}
+	buffer.Write(pcm)
+
+	return buffer.Bytes(), nil
+}
+
+func toPcm(sample float32, bitDepth int) int32 {
+	value := float64(sample)
+	if math.IsNaN(value) {
+		return 0
+	}
+
+	value = math.Max(-1, math.Min(1, value))
+	return int32(math.Round(value * (math.Pow(2, float64(bitDepth-1)) - 1)))
+}
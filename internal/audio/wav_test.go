@@ -0,0 +1,132 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestWavRoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 1, -1, 0.25}
+	encoder := NewWavEncoder()
+
+	for _, bitDepth := range []int{8, 16, 24, 32} {
+		data, err := encoder.EncodeWithBitDepth(samples, 22050, bitDepth)
+		if err != nil {
+			t.Fatalf("%d bit: %v", bitDepth, err)
+		}
+
+		if size := wavHeaderSize + len(samples)*bitDepth/8; len(data) != size {
+			t.Errorf("%d bit: expected %d bytes, got %d", bitDepth, size, len(data))
+		}
+
+		decoded, err := DecodeWav(data)
+		if err != nil {
+			t.Fatalf("%d bit: %v", bitDepth, err)
+		}
+
+		if decoded.SamplingRate != 22050 || decoded.Channels != 1 || len(decoded.Samples) != len(samples) {
+			t.Fatalf("%d bit: unexpected decoded audio %+v", bitDepth, decoded)
+		}
+
+		tolerance := 2 / math.Pow(2, float64(bitDepth-1))
+		for i := range samples {
+			if math.Abs(float64(decoded.Samples[i]-samples[i])) > tolerance {
+				t.Errorf("%d bit: sample %d expected %f, got %f", bitDepth, i, samples[i], decoded.Samples[i])
+			}
+		}
+	}
+}
+
+func TestWavEncoderClampsSamples(t *testing.T) {
+	data, _ := NewWavEncoder().Encode([]float32{2, -2, float32(math.NaN())}, 16000)
+	pcm := data[wavHeaderSize:]
+
+	values := []int16{
+		int16(binary.LittleEndian.Uint16(pcm[0:])),
+		int16(binary.LittleEndian.Uint16(pcm[2:])),
+		int16(binary.LittleEndian.Uint16(pcm[4:])),
+	}
+	if values[0] != math.MaxInt16 || values[1] != -math.MaxInt16 || values[2] != 0 {
+		t.Errorf("unexpected clamped values %v", values)
+	}
+
+	if _, err := NewWavEncoder().EncodeWithBitDepth(nil, 16000, 12); err == nil {
+		t.Error("expected unsupported bit depth to fail")
+	}
+}
+
+func TestDecodeWav(t *testing.T) {
+	float := wavFile(wavFormatFloat, 2, 32, math.Float32bits(0.5), math.Float32bits(-0.25))
+	decoded, err := DecodeWav(float)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Channels != 2 || decoded.Samples[0] != 0.5 || decoded.Samples[1] != -0.25 {
+		t.Errorf("unexpected float wav %+v", decoded)
+	}
+
+	// streaming servers write 0xFFFFFFFF as the data size
+	streamed := wavFile(wavFormatPcm, 1, 16, 0x4000)
+	binary.LittleEndian.PutUint32(streamed[40:], math.MaxUint32)
+	if decoded, err := DecodeWav(streamed); err != nil || len(decoded.Samples) != 2 {
+		t.Errorf("expected open-ended data chunk to be read, got %+v (%v)", decoded, err)
+	}
+
+	invalid := map[string][]byte{
+		"not riff":     []byte("RIFX0000WAVE"),
+		"no data":      wavFile(wavFormatPcm, 1, 16)[:36],
+		"a-law":        wavFile(6, 1, 8, 0),
+		"odd bit size": wavFile(wavFormatPcm, 1, 12, 0),
+	}
+	for name, data := range invalid {
+		if _, err := DecodeWav(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	cases := map[string]AudioFormat{
+		"audio/wav":    FormatWav,
+		"Audio/X-Wav ": FormatWav,
+		"audio/mp3":    FormatMp3,
+		"audio/mpeg":   FormatMp3,
+		"audio/x-flac": FormatFlac,
+	}
+	for contentType, expected := range cases {
+		if format, ok := FormatFromContentType(contentType); !ok || format != expected {
+			t.Errorf("%q: expected %s, got %s", contentType, expected, format)
+		}
+	}
+
+	if _, ok := FormatFromContentType("audio/aac"); ok {
+		t.Error("expected audio/aac to be unsupported")
+	}
+
+	for _, contentType := range ContentTypes() {
+		if _, ok := FormatFromContentType(contentType); !ok {
+			t.Errorf("offered content type %q doesn't map to a format", contentType)
+		}
+	}
+}
+
+func wavFile(format, channels, bitsPerSample int, words ...uint32) []byte {
+	data := make([]byte, 44)
+	copy(data[0:], "RIFF")
+	copy(data[8:], "WAVE")
+	copy(data[12:], "fmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], uint16(format))
+	binary.LittleEndian.PutUint16(data[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(data[24:], 16000)
+	binary.LittleEndian.PutUint16(data[34:], uint16(bitsPerSample))
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], uint32(len(words)*4))
+
+	for _, word := range words {
+		data = binary.LittleEndian.AppendUint32(data, word)
+	}
+
+	return data
+}
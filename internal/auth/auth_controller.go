@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"vitaliiPsl/synthesizer/internal/auth/session"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/requests"
 	"vitaliiPsl/synthesizer/internal/users"
@@ -42,12 +43,12 @@ func (controller *AuthController) HandleSignUp(c *fiber.Ctx) error {
 
 	var req requests.SignUpRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse sign up request", err)
+		logger.Logger.Error("Failed to parse sign up request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
 	if err := controller.validationService.ValidateSignUpRequest(&req); err != nil {
-		logger.Logger.Error("Sign up request didn't pass validation", "message", err.Error(), err)
+		logger.Logger.Error("Sign up request didn't pass validation", "message", err.Error())
 		return err
 	}
 
@@ -65,23 +66,23 @@ func (controller *AuthController) HandleSignIn(c *fiber.Ctx) error {
 
 	var req requests.SignInRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse sign up request", err)
+		logger.Logger.Error("Failed to parse sign up request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
 	if err := controller.validationService.ValidateSignInRequest(&req); err != nil {
-		logger.Logger.Error("Sign in request didn't pass validation", "message", err.Error(), err)
+		logger.Logger.Error("Sign in request didn't pass validation", "message", err.Error())
 		return err
 	}
 
-	token, err := controller.authService.HandleSignIn(&req)
+	tokens, err := controller.authService.HandleSignIn(&req, clientInfo(c))
 	if err != nil {
 		logger.Logger.Error("Failed to handle sign in request", "message", err.Error())
 		return err
 	}
 
 	logger.Logger.Info("Handled sign in request.")
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"token": token})
+	return c.Status(fiber.StatusOK).JSON(tokens)
 }
 
 func (controller *AuthController) HandleSsoSignIn(c *fiber.Ctx) error {
@@ -113,17 +114,75 @@ func (controller *AuthController) HandleSsoCallback(c *fiber.Ctx) error {
 
 	var req requests.SignInWithSSORequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse SSO callback request", err)
+		logger.Logger.Error("Failed to parse SSO callback request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	jwtToken, err := controller.authService.HandleSSOCallback(provider, req.Code)
+	tokens, err := controller.authService.HandleSSOCallback(provider, req.Code, clientInfo(c))
 	if err != nil {
 		return err
 	}
 
 	logger.Logger.Info("Handled SSO callback request.")
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"token": jwtToken})
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (controller *AuthController) HandleRefresh(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling token refresh request...")
+
+	var req requests.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse token refresh request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateRefreshTokenRequest(&req); err != nil {
+		logger.Logger.Error("Token refresh request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	tokens, err := controller.authService.HandleRefresh(&req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle token refresh request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled token refresh request.")
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (controller *AuthController) HandleLogout(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling logout request...")
+
+	sessionId, ok := c.Locals("session").(string)
+	if !ok || sessionId == "" {
+		logger.Logger.Error("No session found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := controller.authService.HandleLogout(sessionId); err != nil {
+		return err
+	}
+
+	logger.Logger.Info("Handled logout request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+func (controller *AuthController) HandleLogoutAll(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling 'logout from all devices' request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := controller.authService.HandleLogoutAll(userDto.Id); err != nil {
+		return err
+	}
+
+	logger.Logger.Info("Handled 'logout from all devices' request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
 
 func (controller *AuthController) HandleEmailVerification(c *fiber.Ctx) error {
@@ -131,7 +190,7 @@ func (controller *AuthController) HandleEmailVerification(c *fiber.Ctx) error {
 
 	var req requests.EmailVerificationRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse email verification request", err)
+		logger.Logger.Error("Failed to parse email verification request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
@@ -154,12 +213,12 @@ func (controller *AuthController) HandleSendPasswordResetToken(c *fiber.Ctx) err
 
 	var req requests.VerificationTokenRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse 'send password verification token' request", err)
+		logger.Logger.Error("Failed to parse 'send password verification token' request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
 	if err := controller.validationService.ValidateVerificationTokenRequest(&req); err != nil {
-		logger.Logger.Error("'Send password reset' request didn't pass validation", "message", err.Error(), err)
+		logger.Logger.Error("'Send password reset' request didn't pass validation", "message", err.Error())
 		return err
 	}
 
@@ -177,7 +236,7 @@ func (controller *AuthController) HandleResetPassword(c *fiber.Ctx) error {
 
 	var req requests.PasswordResetRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse password reset request", err)
+		logger.Logger.Error("Failed to parse password reset request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
@@ -194,3 +253,7 @@ func (controller *AuthController) HandleResetPassword(c *fiber.Ctx) error {
 	logger.Logger.Info("Handled password reset request.")
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
+
+func clientInfo(c *fiber.Ctx) session.ClientInfo {
+	return session.ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IpAddress: c.IP()}
+}
@@ -3,20 +3,23 @@ package auth
 import (
 	"strings"
 	"vitaliiPsl/synthesizer/internal/auth/jwt"
+	"vitaliiPsl/synthesizer/internal/auth/session"
 	"vitaliiPsl/synthesizer/internal/users"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthMiddleware struct {
-	jwtService  jwt.JwtService
-	userService users.UserService
+	jwtService     jwt.JwtService
+	userService    users.UserService
+	sessionService session.SessionService
 }
 
-func NewAuthMiddleware(jwtService jwt.JwtService, userService users.UserService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService jwt.JwtService, userService users.UserService, sessionService session.SessionService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService:  jwtService,
-		userService: userService,
+		jwtService:     jwtService,
+		userService:    userService,
+		sessionService: sessionService,
 	}
 }
 
@@ -29,10 +32,11 @@ func (m *AuthMiddleware) OpenRoute() fiber.Handler {
 
 		token := authorization[len("Bearer "):]
 		claims, err := m.jwtService.ValidateToken(token)
-		if err != nil {
+		if err != nil || !m.checkSession(claims) {
 			return c.Next()
 		}
 
+		c.Locals("session", claims.SessionId)
 		return m.fetchUser(c, claims.Id)
 	}
 }
@@ -50,6 +54,11 @@ func (m *AuthMiddleware) ProtectedRoute(roles ...users.UserRole) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired JWT"})
 		}
 
+		if !m.checkSession(claims) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session has been revoked"})
+		}
+
+		c.Locals("session", claims.SessionId)
 		return m.fetchUser(c, claims.Id, roles...)
 	}
 }
@@ -72,6 +81,17 @@ func (m *AuthMiddleware) fetchUser(c *fiber.Ctx, id string, roles ...users.UserR
 	return c.Next()
 }
 
+// tokens issued before sessions existed carry no session id; they are rejected
+// so that every accepted token can be revoked
+func (m *AuthMiddleware) checkSession(claims *jwt.UserClaims) bool {
+	if claims.SessionId == "" {
+		return false
+	}
+
+	active, err := m.sessionService.GetActiveSession(claims.SessionId)
+	return err == nil && active.UserId == claims.Id
+}
+
 func (m *AuthMiddleware) checkUserRole(userDto *users.UserDto, roles ...users.UserRole) bool {
 	if len(roles) == 0 {
 		return true
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 	"vitaliiPsl/synthesizer/internal/auth/jwt"
+	"vitaliiPsl/synthesizer/internal/auth/session"
 	"vitaliiPsl/synthesizer/internal/auth/sso"
 	"vitaliiPsl/synthesizer/internal/email"
 	service_errors "vitaliiPsl/synthesizer/internal/error"
@@ -18,15 +19,22 @@ import (
 
 const MinPasswordLength = 8
 
+type AuthTokens struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
 type AuthService struct {
 	emailVerificationUrl string
 	passwordResetUrl     string
 
-	userService  users.UserService
-	tokenService token.TokenService
-	emailService email.EmailService
-	jwtService   jwt.JwtService
-	providers    map[string]sso.SSOProvider
+	userService    users.UserService
+	tokenService   token.TokenService
+	emailService   email.EmailService
+	jwtService     jwt.JwtService
+	sessionService session.SessionService
+	providers      map[string]sso.SSOProvider
 }
 
 func NewAuthService(
@@ -34,6 +42,7 @@ func NewAuthService(
 	tokenService token.TokenService,
 	emailService email.EmailService,
 	jwtService jwt.JwtService,
+	sessionService session.SessionService,
 	providers map[string]sso.SSOProvider,
 ) *AuthService {
 	emailVerificationUrl := os.Getenv("EMAIL_VERIFICATION_URL")
@@ -46,6 +55,7 @@ func NewAuthService(
 		tokenService:         tokenService,
 		emailService:         emailService,
 		jwtService:           jwtService,
+		sessionService:       sessionService,
 		providers:            providers,
 	}
 }
@@ -87,7 +97,7 @@ func (s *AuthService) HandleSignUp(req *requests.SignUpRequest) error {
 	return s.sendVerificationEmail(savedUser)
 }
 
-func (s *AuthService) HandleSignIn(req *requests.SignInRequest) (string, error) {
+func (s *AuthService) HandleSignIn(req *requests.SignInRequest, client session.ClientInfo) (*AuthTokens, error) {
 	logger.Logger.Info("Handling sing in req", "email", req.Email)
 
 	user, err := s.userService.FindByEmail(req.Email)
@@ -95,31 +105,31 @@ func (s *AuthService) HandleSignIn(req *requests.SignInRequest) (string, error)
 		var errNotFound *service_errors.ErrNotFound
 		if !errors.As(err, &errNotFound) {
 			logger.Logger.Error("User with given email doesn't exist", "email", req.Email)
-			return "", service_errors.NewErrUnauthorized("Invalid username or password")
+			return nil, service_errors.NewErrUnauthorized("Invalid username or password")
 		}
 
 		logger.Logger.Error("Failed to fetch user", "email", req.Email)
-		return "", err
+		return nil, err
 	}
 
 	if user.Status != users.StatusActive {
 		logger.Logger.Error("User is not active", "email", req.Email, "status", user.Status)
-		return "", service_errors.NewErrUnauthorized("Email not verified")
+		return nil, service_errors.NewErrUnauthorized("Email not verified")
 
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		logger.Logger.Error("Incorrect password", "email", req.Email)
-		return "", service_errors.NewErrUnauthorized("Invalid username or password")
+		return nil, service_errors.NewErrUnauthorized("Invalid username or password")
 	}
 
-	jwtToken, err := s.jwtService.GenerateJWT(user)
+	tokens, err := s.issueTokens(user, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	logger.Logger.Info("Handled sign in.")
-	return jwtToken, nil
+	return tokens, nil
 }
 
 func (s *AuthService) HandleSsoSignIn(providerName string) (string, error) {
@@ -133,23 +143,23 @@ func (s *AuthService) HandleSsoSignIn(providerName string) (string, error) {
 	return provider.AuthCodeURL("state-string"), nil
 }
 
-func (s *AuthService) HandleSSOCallback(providerName, code string) (string, error) {
+func (s *AuthService) HandleSSOCallback(providerName, code string, client session.ClientInfo) (*AuthTokens, error) {
 	logger.Logger.Info("Handling SSO callback", "provider", providerName)
 
 	provider, exists := s.providers[providerName]
 	if !exists {
-		return "", service_errors.NewErrBadRequest("Unsupported SSO provider")
+		return nil, service_errors.NewErrBadRequest("Unsupported SSO provider")
 	}
 
 	token, err := provider.Exchange(code)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var user *users.UserDto
 	user, err = provider.FetchUserInfo(token)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	user.Provider = providerName
@@ -157,17 +167,16 @@ func (s *AuthService) HandleSSOCallback(providerName, code string) (string, erro
 
 	user, err = s.userService.UpsertUser(user)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var jwtToken string
-	jwtToken, err = s.jwtService.GenerateJWT(user)
+	tokens, err := s.issueTokens(user, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	logger.Logger.Info("Handled SSO sign in.")
-	return jwtToken, nil
+	return tokens, nil
 }
 
 func (s *AuthService) HandleEmailVerification(req *requests.EmailVerificationRequest) error {
@@ -245,10 +254,57 @@ func (s *AuthService) HandlePasswordReset(req *requests.PasswordResetRequest) er
 		return err
 	}
 
+	if err = s.sessionService.RevokeUserSessions(user.Id, session.RevokePasswordReset); err != nil {
+		return err
+	}
+
 	logger.Logger.Info("Reset password", "userId", verificationToken.UserID)
 	return nil
 }
 
+func (s *AuthService) HandleRefresh(req *requests.RefreshTokenRequest) (*AuthTokens, error) {
+	logger.Logger.Info("Handling token refresh...")
+
+	refreshed, refreshToken, err := s.sessionService.RefreshSession(req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.FindById(refreshed.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != users.StatusActive {
+		logger.Logger.Error("User is not active", "userId", user.Id, "status", user.Status)
+		if err := s.sessionService.RevokeSession(refreshed.Id, session.RevokeUserInactive); err != nil {
+			return nil, err
+		}
+
+		return nil, service_errors.NewErrUnauthorized("User is not active")
+	}
+
+	jwtToken, expiresAt, err := s.jwtService.GenerateJWT(user, refreshed.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Handled token refresh.", "sessionId", refreshed.Id)
+	return &AuthTokens{Token: jwtToken, ExpiresAt: expiresAt, RefreshToken: refreshToken}, nil
+}
+
+func (s *AuthService) HandleLogout(sessionId string) error {
+	logger.Logger.Info("Handling logout", "sessionId", sessionId)
+
+	return s.sessionService.RevokeSession(sessionId, session.RevokeLogout)
+}
+
+func (s *AuthService) HandleLogoutAll(userId string) error {
+	logger.Logger.Info("Handling logout from all devices", "userId", userId)
+
+	return s.sessionService.RevokeUserSessions(userId, session.RevokeLogoutAll)
+}
+
 func (s *AuthService) HandleSendPasswordResetToken(req *requests.VerificationTokenRequest) error {
 	logger.Logger.Info("Handling resend of password verification token", "email", req.Email)
 
@@ -270,6 +326,20 @@ func (s *AuthService) HandleSendPasswordResetToken(req *requests.VerificationTok
 	return nil
 }
 
+func (s *AuthService) issueTokens(user *users.UserDto, client session.ClientInfo) (*AuthTokens, error) {
+	created, refreshToken, err := s.sessionService.CreateSession(user.Id, client)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtToken, expiresAt, err := s.jwtService.GenerateJWT(user, created.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{Token: jwtToken, ExpiresAt: expiresAt, RefreshToken: refreshToken}, nil
+}
+
 func (s *AuthService) sendVerificationEmail(user *users.UserDto) error {
 	token, err := s.tokenService.CreateVerificationToken(user.Id, token.PurposeEmailVerification)
 	if err != nil {
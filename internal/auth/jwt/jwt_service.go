@@ -1,7 +1,6 @@
 package jwt
 
 import (
-	"fmt"
 	"os"
 	"strconv"
 	"time"
@@ -13,47 +12,59 @@ import (
 )
 
 type JwtService interface {
-	GenerateJWT(user *users.UserDto) (string, error)
+	GenerateJWT(user *users.UserDto, sessionId string) (string, time.Time, error)
 	ValidateToken(tokenString string) (*UserClaims, error)
 }
 
+const defaultExpirationMinutes = 15
+
 type JwtServiceImpl struct {
-	application     string
-	secretKey       string
-	expirationHours int
+	application string
+	secretKey   string
+	expiration  time.Duration
 }
 
 func NewJwtService() *JwtServiceImpl {
 	appName := os.Getenv("APP_NAME")
 	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
 
-	expirationHours, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_HOURS"))
-	if err != nil {
-		logger.Logger.Error("Invalid JWT_EXPIRATION_HOURS value.", "value", expirationHours)
-		panic(fmt.Sprintf("Invalid JWT_EXPIRATION_HOURS value: %v", expirationHours))
+	return &JwtServiceImpl{
+		application: appName,
+		secretKey:   jwtSecretKey,
+		expiration:  expirationFromEnv(),
+	}
+}
+
+// JWT_EXPIRATION_HOURS predates refresh tokens and is still honoured when
+// JWT_EXPIRATION_MINUTES isn't set
+func expirationFromEnv() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
 	}
 
-	return &JwtServiceImpl{
-		application:     appName,
-		secretKey:       jwtSecretKey,
-		expirationHours: expirationHours,
+	if hours, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_HOURS")); err == nil && hours > 0 {
+		logger.Logger.Warn("JWT_EXPIRATION_HOURS is deprecated, use JWT_EXPIRATION_MINUTES.", "hours", hours)
+		return time.Duration(hours) * time.Hour
 	}
+
+	return defaultExpirationMinutes * time.Minute
 }
 
-func (s *JwtServiceImpl) GenerateJWT(user *users.UserDto) (string, error) {
-	logger.Logger.Info("Generating JWT token...", "userId", user.Id)
+func (s *JwtServiceImpl) GenerateJWT(user *users.UserDto, sessionId string) (string, time.Time, error) {
+	logger.Logger.Info("Generating JWT token...", "userId", user.Id, "sessionId", sessionId)
 
-	claims := s.createUserClaims(user)
+	expiresAt := time.Now().Add(s.expiration)
+	claims := s.createUserClaims(user, sessionId, expiresAt)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString([]byte(s.secretKey))
 	if err != nil {
 		logger.Logger.Error("Failed to sign JWT")
-		return "", &service_errors.ErrInternalServer{}
+		return "", time.Time{}, &service_errors.ErrInternalServer{}
 	}
 
 	logger.Logger.Info("Generated JWT token.")
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
 func (s *JwtServiceImpl) ValidateToken(tokenString string) (*UserClaims, error) {
@@ -76,13 +87,15 @@ func (s *JwtServiceImpl) ValidateToken(tokenString string) (*UserClaims, error)
 	}
 }
 
-func (s *JwtServiceImpl) createUserClaims(user *users.UserDto) *UserClaims {
+func (s *JwtServiceImpl) createUserClaims(user *users.UserDto, sessionId string, expiresAt time.Time) *UserClaims {
 	return &UserClaims{
-		Id: user.Id,
+		Id:        user.Id,
+		SessionId: sessionId,
 		StandardClaims: jwt.StandardClaims{
 			Issuer:    s.application,
 			Subject:   user.Id,
-			ExpiresAt: time.Now().Add(time.Duration(s.expirationHours) * time.Hour).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: expiresAt.Unix(),
 		},
 	}
 }
@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpirationFromEnv(t *testing.T) {
+	cases := []struct {
+		name     string
+		minutes  string
+		hours    string
+		expected time.Duration
+	}{
+		{name: "default", expected: defaultExpirationMinutes * time.Minute},
+		{name: "minutes", minutes: "30", expected: 30 * time.Minute},
+		{name: "legacy hours", hours: "2", expected: 2 * time.Hour},
+		{name: "minutes win over hours", minutes: "5", hours: "2", expected: 5 * time.Minute},
+		{name: "invalid minutes fall back to hours", minutes: "soon", hours: "1", expected: time.Hour},
+		{name: "invalid values", minutes: "0", hours: "-1", expected: defaultExpirationMinutes * time.Minute},
+	}
+
+	for _, c := range cases {
+		t.Setenv("JWT_EXPIRATION_MINUTES", c.minutes)
+		t.Setenv("JWT_EXPIRATION_HOURS", c.hours)
+
+		if expiration := expirationFromEnv(); expiration != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, expiration)
+		}
+	}
+}
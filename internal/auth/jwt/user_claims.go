@@ -3,6 +3,7 @@ package jwt
 import "github.com/dgrijalva/jwt-go"
 
 type UserClaims struct {
-	Id string `json:"id"`
+	Id        string `json:"id"`
+	SessionId string `json:"sid"`
 	jwt.StandardClaims
 }
\ No newline at end of file
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *Session, token *RefreshToken) error
+	FindById(id string) (*Session, error)
+	FindRefreshToken(tokenHash string) (*RefreshToken, error)
+	Rotate(session *Session, used *RefreshToken, next *RefreshToken) (bool, error)
+	Revoke(id string, reason RevokeReason, at time.Time) error
+	RevokeByUserId(userId string, reason RevokeReason, at time.Time) error
+}
+
+type SessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepositoryImpl {
+	return &SessionRepositoryImpl{db: db}
+}
+
+func (r *SessionRepositoryImpl) Create(session *Session, token *RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		token.SessionId = session.Id
+		return tx.Create(token).Error
+	})
+}
+
+func (r *SessionRepositoryImpl) FindById(id string) (*Session, error) {
+	var session Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+
+	return &session, err
+}
+
+func (r *SessionRepositoryImpl) FindRefreshToken(tokenHash string) (*RefreshToken, error) {
+	var token RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+
+	return &token, err
+}
+
+// marks the used token only if nobody else did it first, so two concurrent
+// refreshes with the same token can't both succeed
+func (r *SessionRepositoryImpl) Rotate(session *Session, used *RefreshToken, next *RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", used.Id).
+			Update("used_at", used.UsedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&Session{}).
+			Where("id = ?", session.Id).
+			Updates(map[string]interface{}{"last_used_at": session.LastUsedAt, "expires_at": session.ExpiresAt}).Error
+		if err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+
+	return rotated, err
+}
+
+func (r *SessionRepositoryImpl) Revoke(id string, reason RevokeReason, at time.Time) error {
+	return r.db.Model(&Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoke_reason": reason}).Error
+}
+
+func (r *SessionRepositoryImpl) RevokeByUserId(userId string, reason RevokeReason, at time.Time) error {
+	return r.db.Model(&Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Updates(map[string]interface{}{"revoked_at": at, "revoke_reason": reason}).Error
+}
@@ -0,0 +1,236 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/users"
+)
+
+const defaultRefreshTokenExpirationHours = 24 * 30
+
+type SessionService interface {
+	CreateSession(userId string, client ClientInfo) (*SessionDto, string, error)
+	RefreshSession(refreshToken string) (*SessionDto, string, error)
+	GetActiveSession(id string) (*SessionDto, error)
+	RevokeSession(id string, reason RevokeReason) error
+	RevokeUserSessions(userId string, reason RevokeReason) error
+}
+
+type SessionServiceImpl struct {
+	refreshTokenDuration time.Duration
+	repository           SessionRepository
+}
+
+func NewSessionService(repository SessionRepository) *SessionServiceImpl {
+	hours, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_EXPIRATION_HOURS"))
+	if err != nil || hours < 1 {
+		hours = defaultRefreshTokenExpirationHours
+	}
+
+	return &SessionServiceImpl{
+		refreshTokenDuration: time.Duration(hours) * time.Hour,
+		repository:           repository,
+	}
+}
+
+func (s *SessionServiceImpl) CreateSession(userId string, client ClientInfo) (*SessionDto, string, error) {
+	logger.Logger.Info("Creating session...", "userId", userId)
+
+	refreshToken, token, err := s.newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := Session{
+		UserId:     userId,
+		UserAgent:  truncate(client.UserAgent, 512),
+		IpAddress:  truncate(client.IpAddress, 64),
+		LastUsedAt: now,
+		ExpiresAt:  token.ExpiresAt,
+	}
+
+	if err := s.repository.Create(&session, token); err != nil {
+		logger.Logger.Error("Failed to save session", "userId", userId, "error", err)
+		return nil, "", service_errors.NewErrInternalServer("Failed to create session")
+	}
+
+	logger.Logger.Info("Created session.", "userId", userId, "sessionId", session.Id)
+	return ToSessionDto(&session), refreshToken, nil
+}
+
+func (s *SessionServiceImpl) RefreshSession(refreshToken string) (*SessionDto, string, error) {
+	logger.Logger.Info("Refreshing session...")
+
+	used, err := s.repository.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Refresh token not found")
+			return nil, "", service_errors.NewErrUnauthorized("Invalid refresh token")
+		}
+
+		logger.Logger.Error("Failed to fetch refresh token", "error", err)
+		return nil, "", service_errors.NewErrInternalServer("Failed to fetch refresh token")
+	}
+
+	session, err := s.findSession(used.SessionId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !session.Active() {
+		logger.Logger.Error("Session is revoked", "sessionId", session.Id, "reason", session.RevokeReason)
+		return nil, "", service_errors.NewErrUnauthorized("Session has been revoked")
+	}
+
+	if used.UsedAt != nil {
+		return nil, "", s.revokeReused(session)
+	}
+
+	now := time.Now()
+	if now.After(used.ExpiresAt) {
+		logger.Logger.Error("Refresh token expired", "sessionId", session.Id, "expiredAt", used.ExpiresAt)
+		return nil, "", service_errors.NewErrUnauthorized("Refresh token expired")
+	}
+
+	nextRefreshToken, next, err := s.newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	next.SessionId = session.Id
+
+	used.UsedAt = &now
+	session.LastUsedAt = now
+	session.ExpiresAt = next.ExpiresAt
+
+	rotated, err := s.repository.Rotate(session, used, next)
+	if err != nil {
+		logger.Logger.Error("Failed to rotate refresh token", "sessionId", session.Id, "error", err)
+		return nil, "", service_errors.NewErrInternalServer("Failed to refresh session")
+	}
+
+	if !rotated {
+		return nil, "", s.revokeReused(session)
+	}
+
+	logger.Logger.Info("Refreshed session.", "sessionId", session.Id)
+	return ToSessionDto(session), nextRefreshToken, nil
+}
+
+func (s *SessionServiceImpl) GetActiveSession(id string) (*SessionDto, error) {
+	session, err := s.findSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !session.Active() {
+		return nil, service_errors.NewErrUnauthorized("Session has been revoked")
+	}
+
+	return ToSessionDto(session), nil
+}
+
+func (s *SessionServiceImpl) RevokeSession(id string, reason RevokeReason) error {
+	logger.Logger.Info("Revoking session...", "sessionId", id, "reason", reason)
+
+	if err := s.repository.Revoke(id, reason, time.Now()); err != nil {
+		logger.Logger.Error("Failed to revoke session", "sessionId", id, "error", err)
+		return service_errors.NewErrInternalServer("Failed to revoke session")
+	}
+
+	logger.Logger.Info("Revoked session.", "sessionId", id)
+	return nil
+}
+
+func (s *SessionServiceImpl) RevokeUserSessions(userId string, reason RevokeReason) error {
+	logger.Logger.Info("Revoking user's sessions...", "userId", userId, "reason", reason)
+
+	if err := s.repository.RevokeByUserId(userId, reason, time.Now()); err != nil {
+		logger.Logger.Error("Failed to revoke user's sessions", "userId", userId, "error", err)
+		return service_errors.NewErrInternalServer("Failed to revoke sessions")
+	}
+
+	logger.Logger.Info("Revoked user's sessions.", "userId", userId)
+	return nil
+}
+
+// a user who is no longer active loses every session and with it every
+// refresh token
+func (s *SessionServiceImpl) OnUserStatusChanged(userId string, status users.UserStatus) {
+	if status == users.StatusActive {
+		return
+	}
+
+	reason := RevokeUserInactive
+	if status == users.StatusBlocked {
+		reason = RevokeUserBlocked
+	}
+
+	if err := s.RevokeUserSessions(userId, reason); err != nil {
+		logger.Logger.Error("Failed to revoke sessions of inactive user", "userId", userId, "status", status)
+	}
+}
+
+func (s *SessionServiceImpl) findSession(id string) (*Session, error) {
+	session, err := s.repository.FindById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Session not found", "sessionId", id)
+			return nil, service_errors.NewErrUnauthorized("Session not found")
+		}
+
+		logger.Logger.Error("Failed to fetch session", "sessionId", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch session")
+	}
+
+	return session, nil
+}
+
+func (s *SessionServiceImpl) revokeReused(session *Session) error {
+	logger.Logger.Warn("Refresh token reuse detected. Revoking session", "sessionId", session.Id, "userId", session.UserId)
+
+	if err := s.RevokeSession(session.Id, RevokeTokenReuse); err != nil {
+		return err
+	}
+
+	return service_errors.NewErrUnauthorized("Refresh token has already been used")
+}
+
+func (s *SessionServiceImpl) newRefreshToken() (string, *RefreshToken, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		logger.Logger.Error("Failed to generate refresh token", "error", err)
+		return "", nil, service_errors.NewErrInternalServer("Failed to generate refresh token")
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(buffer)
+	token := &RefreshToken{
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTokenDuration),
+	}
+
+	return refreshToken, token, nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	digest := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(digest[:])
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/users"
+)
+
+type memorySessionRepository struct {
+	sessions map[string]*Session
+	tokens   map[string]*RefreshToken
+}
+
+func newMemorySessionRepository() *memorySessionRepository {
+	return &memorySessionRepository{sessions: map[string]*Session{}, tokens: map[string]*RefreshToken{}}
+}
+
+func (r *memorySessionRepository) Create(session *Session, token *RefreshToken) error {
+	session.Id = uuid.NewString()
+	stored := *session
+	r.sessions[session.Id] = &stored
+
+	token.SessionId = session.Id
+	storedToken := *token
+	r.tokens[token.TokenHash] = &storedToken
+	return nil
+}
+
+func (r *memorySessionRepository) FindById(id string) (*Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memorySessionRepository) FindRefreshToken(tokenHash string) (*RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	copied := *token
+	return &copied, nil
+}
+
+func (r *memorySessionRepository) Rotate(session *Session, used *RefreshToken, next *RefreshToken) (bool, error) {
+	stored := r.tokens[used.TokenHash]
+	if stored.UsedAt != nil {
+		return false, nil
+	}
+
+	stored.UsedAt = used.UsedAt
+	storedNext := *next
+	r.tokens[next.TokenHash] = &storedNext
+	r.sessions[session.Id].LastUsedAt = session.LastUsedAt
+	r.sessions[session.Id].ExpiresAt = session.ExpiresAt
+	return true, nil
+}
+
+func (r *memorySessionRepository) Revoke(id string, reason RevokeReason, at time.Time) error {
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		session.RevokeReason = reason
+	}
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeByUserId(userId string, reason RevokeReason, at time.Time) error {
+	for id, session := range r.sessions {
+		if session.UserId == userId {
+			r.Revoke(id, reason, at)
+		}
+	}
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	var errUnauthorized *service_errors.ErrUnauthorized
+	return errors.As(err, &errUnauthorized)
+}
+
+func TestRefreshSessionRotatesToken(t *testing.T) {
+	repository := newMemorySessionRepository()
+	service := NewSessionService(repository)
+
+	created, first, err := service.CreateSession("user-1", ClientInfo{UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, second, err := service.RefreshSession(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.Id != created.Id || second == first {
+		t.Errorf("expected a new token for the same session, got %q for %s", second, refreshed.Id)
+	}
+
+	if _, _, err := service.RefreshSession(second); err != nil {
+		t.Errorf("expected rotated token to be accepted: %v", err)
+	}
+
+	if _, err := service.GetActiveSession(created.Id); err != nil {
+		t.Errorf("expected session to stay active: %v", err)
+	}
+}
+
+func TestRefreshSessionRevokesOnReuse(t *testing.T) {
+	repository := newMemorySessionRepository()
+	service := NewSessionService(repository)
+
+	created, first, _ := service.CreateSession("user-1", ClientInfo{})
+	_, second, _ := service.RefreshSession(first)
+
+	if _, _, err := service.RefreshSession(first); !isUnauthorized(err) {
+		t.Fatalf("expected reused token to be rejected, got %v", err)
+	}
+
+	if _, err := service.GetActiveSession(created.Id); !isUnauthorized(err) {
+		t.Errorf("expected session to be revoked, got %v", err)
+	}
+	if reason := repository.sessions[created.Id].RevokeReason; reason != RevokeTokenReuse {
+		t.Errorf("unexpected revoke reason %q", reason)
+	}
+
+	if _, _, err := service.RefreshSession(second); !isUnauthorized(err) {
+		t.Errorf("expected latest token of a revoked session to be rejected, got %v", err)
+	}
+}
+
+func TestRefreshSessionRejectsInvalidTokens(t *testing.T) {
+	repository := newMemorySessionRepository()
+	service := NewSessionService(repository)
+
+	if _, _, err := service.RefreshSession("unknown"); !isUnauthorized(err) {
+		t.Errorf("expected unknown token to be rejected, got %v", err)
+	}
+
+	_, token, _ := service.CreateSession("user-1", ClientInfo{})
+	repository.tokens[hashRefreshToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+	if _, _, err := service.RefreshSession(token); !isUnauthorized(err) {
+		t.Errorf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	repository := newMemorySessionRepository()
+	service := NewSessionService(repository)
+
+	first, _, _ := service.CreateSession("user-1", ClientInfo{})
+	second, token, _ := service.CreateSession("user-1", ClientInfo{})
+	other, _, _ := service.CreateSession("user-2", ClientInfo{})
+
+	if err := service.RevokeUserSessions("user-1", RevokeLogoutAll); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{first.Id, second.Id} {
+		if _, err := service.GetActiveSession(id); !isUnauthorized(err) {
+			t.Errorf("expected session %s to be revoked, got %v", id, err)
+		}
+	}
+	if _, err := service.GetActiveSession(other.Id); err != nil {
+		t.Errorf("expected other user's session to stay active: %v", err)
+	}
+	if _, _, err := service.RefreshSession(token); !isUnauthorized(err) {
+		t.Errorf("expected refresh of a revoked session to fail, got %v", err)
+	}
+}
+
+func TestBlockedUserLosesSessions(t *testing.T) {
+	repository := newMemorySessionRepository()
+	service := NewSessionService(repository)
+
+	created, token, _ := service.CreateSession("user-1", ClientInfo{})
+
+	service.OnUserStatusChanged("user-1", users.StatusActive)
+	if _, err := service.GetActiveSession(created.Id); err != nil {
+		t.Fatalf("expected activation to keep the session: %v", err)
+	}
+
+	service.OnUserStatusChanged("user-1", users.StatusBlocked)
+	if _, err := service.GetActiveSession(created.Id); !isUnauthorized(err) {
+		t.Errorf("expected session of a blocked user to be revoked, got %v", err)
+	}
+	if reason := repository.sessions[created.Id].RevokeReason; reason != RevokeUserBlocked {
+		t.Errorf("unexpected revoke reason %q", reason)
+	}
+	if _, _, err := service.RefreshSession(token); !isUnauthorized(err) {
+		t.Errorf("expected refresh token of a blocked user to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package batch
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+)
+
+func writeArchive(w io.Writer, result *BatchResult) error {
+	archive := zip.NewWriter(w)
+
+	for _, item := range result.Items {
+		if item.Status != StatusSucceeded {
+			continue
+		}
+
+		file, err := archive.Create(item.FileName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := file.Write(item.Audio); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result.withoutAudio()); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"bytes"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/users"
+	"vitaliiPsl/synthesizer/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const contentTypeZip = "application/zip"
+
+type BatchController struct {
+	service           BatchService
+	validationService *validation.ValidationService
+}
+
+func NewBatchController(batchService BatchService, validationService *validation.ValidationService) *BatchController {
+	return &BatchController{service: batchService, validationService: validationService}
+}
+
+func (controller *BatchController) HandleBatchSynthesis(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling batch synthesis request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.BatchSynthesisRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse batch synthesis request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateBatchSynthesisRequest(&req); err != nil {
+		logger.Logger.Error("Batch synthesis request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	result, err := controller.service.SynthesizeBatch(c.UserContext(), &req, userDto.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to handle batch synthesis request", "message", err.Error())
+		return err
+	}
+
+	c.Vary(fiber.HeaderAccept)
+	if !wantsArchive(c, req.Output) {
+		logger.Logger.Info("Handled batch synthesis request.", "output", "json")
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+
+	var archive bytes.Buffer
+	if err := writeArchive(&archive, result); err != nil {
+		logger.Logger.Error("Failed to write batch archive", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write batch archive"})
+	}
+
+	c.Set(fiber.HeaderContentType, contentTypeZip)
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=\"batch.zip\"")
+
+	logger.Logger.Info("Handled batch synthesis request.", "output", "zip")
+	return c.Status(fiber.StatusOK).Send(archive.Bytes())
+}
+
+// an explicit output field wins, otherwise the Accept header decides and JSON is the default
+func wantsArchive(c *fiber.Ctx, output string) bool {
+	if output != "" {
+		return output == "zip"
+	}
+
+	return c.Accepts(fiber.MIMEApplicationJSON, contentTypeZip) == contentTypeZip
+}
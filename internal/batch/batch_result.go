@@ -0,0 +1,36 @@
+package batch
+
+type ItemStatus string
+
+const (
+	StatusSucceeded ItemStatus = "succeeded"
+	StatusFailed    ItemStatus = "failed"
+)
+
+type BatchItemResult struct {
+	Id          string     `json:"id"`
+	ModelId     string     `json:"model_id"`
+	Status      ItemStatus `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	FileName    string     `json:"file_name,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	DurationMs  int        `json:"duration_ms,omitempty"`
+	Audio       []byte     `json:"audio,omitempty"`
+}
+
+type BatchResult struct {
+	Items     []BatchItemResult `json:"items"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// manifest without the audio itself, for the ZIP where audio lives in files
+func (r *BatchResult) withoutAudio() *BatchResult {
+	items := make([]BatchItemResult, len(r.Items))
+	for i, item := range r.Items {
+		item.Audio = nil
+		items[i] = item
+	}
+
+	return &BatchResult{Items: items, Succeeded: r.Succeeded, Failed: r.Failed}
+}
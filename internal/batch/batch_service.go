@@ -0,0 +1,248 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/synthesis"
+)
+
+const (
+	defaultUserConcurrency = 4
+	defaultTimeoutSeconds  = 300
+	defaultMaxMegabytes    = 256
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+var (
+	errBatchTimedOut = errors.New("batch timed out")
+	errBatchTooLarge = errors.New("batch output is too large")
+)
+
+type BatchService interface {
+	SynthesizeBatch(ctx context.Context, req *requests.BatchSynthesisRequest, userId string) (*BatchResult, error)
+}
+
+type BatchServiceImpl struct {
+	synthesisService synthesis.SynthesisService
+	audioService     audio.AudioService
+	limiter          *userLimiter
+	timeout          time.Duration
+	maxBytes         int
+}
+
+func NewBatchService(synthesisService synthesis.SynthesisService, audioService audio.AudioService) *BatchServiceImpl {
+	concurrency, err := strconv.Atoi(os.Getenv("SYNTHESIS_BATCH_USER_CONCURRENCY"))
+	if err != nil || concurrency < 1 {
+		concurrency = defaultUserConcurrency
+	}
+
+	timeoutSeconds, err := strconv.Atoi(os.Getenv("SYNTHESIS_BATCH_TIMEOUT_SECONDS"))
+	if err != nil || timeoutSeconds < 1 {
+		timeoutSeconds = defaultTimeoutSeconds
+	}
+
+	maxMegabytes, err := strconv.Atoi(os.Getenv("SYNTHESIS_BATCH_MAX_MB"))
+	if err != nil || maxMegabytes < 1 {
+		maxMegabytes = defaultMaxMegabytes
+	}
+
+	return &BatchServiceImpl{
+		synthesisService: synthesisService,
+		audioService:     audioService,
+		limiter:          newUserLimiter(concurrency),
+		timeout:          time.Duration(timeoutSeconds) * time.Second,
+		maxBytes:         maxMegabytes << 20,
+	}
+}
+
+func (s *BatchServiceImpl) SynthesizeBatch(ctx context.Context, req *requests.BatchSynthesisRequest, userId string) (*BatchResult, error) {
+	logger.Logger.Info("Handling batch synthesis...", "userId", userId, "items", len(req.Items))
+
+	format := audio.AudioFormat(req.Format)
+	if format == "" {
+		format = audio.FormatWav
+	}
+
+	if !s.audioService.Supports(format) {
+		logger.Logger.Error("Unsupported audio format", "format", format)
+		return nil, service_errors.NewErrBadRequest("Unsupported audio format: " + string(format))
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.Id] {
+			logger.Logger.Error("Duplicate batch item id", "id", item.Id)
+			return nil, service_errors.NewErrBadRequest("Duplicate batch item id: " + item.Id)
+		}
+		seen[item.Id] = true
+	}
+
+	fileNames := fileNamesFor(req.Items, format)
+	results := make([]BatchItemResult, len(req.Items))
+
+	// all results are held in memory until the response is written, so the
+	// batch stops once its audio outgrows the budget
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, s.timeout, errBatchTimedOut)
+	defer cancelTimeout()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	budget := &byteBudget{remaining: s.maxBytes}
+
+	semaphore := s.limiter.acquire(userId)
+	defer s.limiter.release(userId)
+
+	var wg sync.WaitGroup
+	for i, item := range req.Items {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			results[i] = failedItem(item, stoppedMessage(ctx))
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, item requests.BatchSynthesisItem) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			results[i] = s.synthesizeItem(ctx, item, userId, format, fileNames[i], budget, cancel)
+		}(i, item)
+	}
+	wg.Wait()
+
+	result := &BatchResult{Items: results}
+	for _, item := range results {
+		if item.Status == StatusSucceeded {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+
+	logger.Logger.Info("Handled batch synthesis.", "userId", userId, "succeeded", result.Succeeded, "failed", result.Failed)
+	return result, nil
+}
+
+func (s *BatchServiceImpl) synthesizeItem(ctx context.Context, item requests.BatchSynthesisItem, userId string, format audio.AudioFormat, fileName string, budget *byteBudget, cancel context.CancelCauseFunc) BatchItemResult {
+	req := &requests.SynthesisRequest{Text: item.Text, ModelId: item.ModelId}
+
+	response, err := s.synthesisService.HandleSynthesisRequest(ctx, req, userId)
+	if err != nil {
+		logger.Logger.Error("Batch item failed", "id", item.Id, "userId", userId, "error", err)
+		if ctx.Err() != nil {
+			return failedItem(item, stoppedMessage(ctx))
+		}
+
+		return failedItem(item, itemErrorMessage(err))
+	}
+
+	encoded, err := s.audioService.Encode(response.Samples, response.SamplingRate, format)
+	if err != nil {
+		logger.Logger.Error("Failed to encode batch item", "id", item.Id, "userId", userId, "error", err)
+		return failedItem(item, "Failed to encode audio")
+	}
+
+	if !budget.reserve(len(encoded.Data)) {
+		logger.Logger.Error("Batch output is too large", "id", item.Id, "userId", userId, "maxBytes", s.maxBytes)
+		cancel(errBatchTooLarge)
+		return failedItem(item, stoppedMessage(ctx))
+	}
+
+	durationMs := 0
+	if response.SamplingRate > 0 {
+		durationMs = len(response.Samples) * 1000 / response.SamplingRate
+	}
+
+	return BatchItemResult{
+		Id:          item.Id,
+		ModelId:     item.ModelId,
+		Status:      StatusSucceeded,
+		FileName:    fileName,
+		ContentType: encoded.ContentType,
+		DurationMs:  durationMs,
+		Audio:       encoded.Data,
+	}
+}
+
+func failedItem(item requests.BatchSynthesisItem, message string) BatchItemResult {
+	return BatchItemResult{Id: item.Id, ModelId: item.ModelId, Status: StatusFailed, Error: message}
+}
+
+func stoppedMessage(ctx context.Context) string {
+	switch context.Cause(ctx) {
+	case errBatchTooLarge:
+		return "Batch output exceeds the size limit"
+	case errBatchTimedOut:
+		return "Batch timed out"
+	default:
+		return "Batch was cancelled"
+	}
+}
+
+type byteBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+func (b *byteBudget) reserve(size int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size > b.remaining {
+		return false
+	}
+
+	b.remaining -= size
+	return true
+}
+
+// only errors meant for the client are passed through
+func itemErrorMessage(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return "Batch was cancelled"
+	}
+
+	switch e := err.(type) {
+	case *service_errors.ErrNotFound, *service_errors.ErrBadRequest, *service_errors.ErrForbidden, *service_errors.ErrBadGateway, *service_errors.ErrGone, *service_errors.ErrServiceUnavailable:
+		return e.Error()
+	default:
+		return "Failed to synthesize speech"
+	}
+}
+
+// ids may contain anything, file names inside the archive must be safe and unique
+func fileNamesFor(items []requests.BatchSynthesisItem, format audio.AudioFormat) []string {
+	names := make([]string, len(items))
+	used := make(map[string]bool, len(items))
+
+	for i, item := range items {
+		base := unsafeFileNameChars.ReplaceAllString(item.Id, "_")
+		if base == "" || base == "." || base == ".." {
+			base = "item"
+		}
+
+		name := fmt.Sprintf("%s.%s", base, format.Extension())
+		for suffix := 2; used[name]; suffix++ {
+			name = fmt.Sprintf("%s-%d.%s", base, suffix, format.Extension())
+		}
+
+		used[name] = true
+		names[i] = name
+	}
+
+	return names
+}
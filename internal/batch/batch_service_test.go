@@ -0,0 +1,197 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/synthesis"
+)
+
+type fakeSynthesisService struct {
+	synthesis.SynthesisService
+	delay time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]int
+	peak     map[string]int
+}
+
+func newFakeSynthesisService(delay time.Duration) *fakeSynthesisService {
+	return &fakeSynthesisService{delay: delay, inFlight: map[string]int{}, peak: map[string]int{}}
+}
+
+func (s *fakeSynthesisService) HandleSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string) (*synthesis.SynthesisResponse, error) {
+	s.mu.Lock()
+	s.inFlight[userId]++
+	s.peak[userId] = max(s.peak[userId], s.inFlight[userId])
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inFlight[userId]--
+		s.mu.Unlock()
+	}()
+
+	switch req.ModelId {
+	case "missing":
+		return nil, service_errors.NewErrNotFound("Model not found")
+	case "broken":
+		return nil, service_errors.NewErrInternalServer("connection refused to 10.0.0.3")
+	case "hanging":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &synthesis.SynthesisResponse{Samples: make([]float32, 1000), SamplingRate: 1000}, nil
+}
+
+func (s *fakeSynthesisService) HandleStreamingSynthesisRequest(context.Context, *requests.SynthesisRequest, string, func(*synthesis.SynthesisChunk) error) (*history.HistoryRecordDto, error) {
+	return nil, nil
+}
+
+func newTestBatchService(t *testing.T, synthesisService synthesis.SynthesisService) *BatchServiceImpl {
+	t.Setenv("SYNTHESIS_BATCH_USER_CONCURRENCY", "2")
+	audioService := audio.NewAudioService(map[audio.AudioFormat]audio.AudioEncoder{audio.FormatWav: audio.NewWavEncoder()})
+	return NewBatchService(synthesisService, audioService)
+}
+
+func batchOf(modelIds ...string) *requests.BatchSynthesisRequest {
+	req := &requests.BatchSynthesisRequest{}
+	for i, modelId := range modelIds {
+		req.Items = append(req.Items, requests.BatchSynthesisItem{Id: fmt.Sprintf("item-%d", i), Text: "Hello", ModelId: modelId})
+	}
+	return req
+}
+
+func TestSynthesizeBatchPartialFailure(t *testing.T) {
+	service := newTestBatchService(t, newFakeSynthesisService(0))
+
+	result, err := service.SynthesizeBatch(context.Background(), batchOf("anna", "missing", "broken", "anna"), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Succeeded != 2 || result.Failed != 2 {
+		t.Fatalf("expected 2 succeeded and 2 failed, got %+v", result)
+	}
+
+	expected := []struct {
+		status ItemStatus
+		error  string
+	}{
+		{StatusSucceeded, ""},
+		{StatusFailed, "Model not found"},
+		{StatusFailed, "Failed to synthesize speech"},
+		{StatusSucceeded, ""},
+	}
+	for i, item := range result.Items {
+		if item.Status != expected[i].status || item.Error != expected[i].error {
+			t.Errorf("item %d: expected %+v, got %s %q", i, expected[i], item.Status, item.Error)
+		}
+		if item.Status == StatusSucceeded && (len(item.Audio) == 0 || item.FileName != fmt.Sprintf("item-%d.wav", i) || item.DurationMs != 1000) {
+			t.Errorf("item %d: unexpected result %+v", i, item)
+		}
+	}
+}
+
+func TestSynthesizeBatchLimitsConcurrencyPerUser(t *testing.T) {
+	synthesisService := newFakeSynthesisService(20 * time.Millisecond)
+	service := newTestBatchService(t, synthesisService)
+
+	var wg sync.WaitGroup
+	for _, userId := range []string{"user-1", "user-1", "user-2"} {
+		wg.Add(1)
+		go func(userId string) {
+			defer wg.Done()
+			service.SynthesizeBatch(context.Background(), batchOf("anna", "anna", "anna", "anna", "anna"), userId)
+		}(userId)
+	}
+	wg.Wait()
+
+	if peak := synthesisService.peak["user-1"]; peak != 2 {
+		t.Errorf("expected user-1 to be capped at 2 items across batches, peaked at %d", peak)
+	}
+	if peak := synthesisService.peak["user-2"]; peak != 2 {
+		t.Errorf("expected user-2 to get its own slots, peaked at %d", peak)
+	}
+	if len(service.limiter.slots) != 0 {
+		t.Errorf("expected limiter slots to be released, got %d", len(service.limiter.slots))
+	}
+}
+
+func TestSynthesizeBatchTimeout(t *testing.T) {
+	service := newTestBatchService(t, newFakeSynthesisService(0))
+	service.timeout = 30 * time.Millisecond
+
+	result, err := service.SynthesizeBatch(context.Background(), batchOf("anna", "hanging", "hanging", "anna"), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Items[0].Status != StatusSucceeded {
+		t.Errorf("expected first item to finish before the deadline, got %+v", result.Items[0])
+	}
+	for _, item := range result.Items[1:] {
+		if item.Status != StatusFailed || item.Error != "Batch timed out" {
+			t.Errorf("expected %s to time out, got %s %q", item.Id, item.Status, item.Error)
+		}
+	}
+}
+
+func TestSynthesizeBatchCancellation(t *testing.T) {
+	service := newTestBatchService(t, newFakeSynthesisService(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, _ := service.SynthesizeBatch(ctx, batchOf("anna", "anna"), "user-1")
+	for _, item := range result.Items {
+		if item.Error != "Batch was cancelled" {
+			t.Errorf("expected %s to be cancelled, got %q", item.Id, item.Error)
+		}
+	}
+}
+
+func TestSynthesizeBatchSizeLimit(t *testing.T) {
+	service := newTestBatchService(t, newFakeSynthesisService(0))
+	// one second of 16 bit audio at 1 kHz plus the header
+	service.maxBytes = 2*2044 + 100
+
+	result, err := service.SynthesizeBatch(context.Background(), batchOf("anna", "anna", "anna", "anna", "anna"), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Succeeded != 2 || result.Failed != 3 {
+		t.Fatalf("expected the budget to fit 2 items, got %+v", result)
+	}
+	for _, item := range result.Items {
+		if item.Status == StatusFailed && item.Error != "Batch output exceeds the size limit" {
+			t.Errorf("unexpected error for %s: %q", item.Id, item.Error)
+		}
+	}
+}
+
+func TestFileNamesFor(t *testing.T) {
+	items := []requests.BatchSynthesisItem{{Id: "intro"}, {Id: "in/tro"}, {Id: "in tro"}, {Id: ".."}, {Id: "intro"}}
+
+	names := fileNamesFor(items, audio.FormatMp3)
+	expected := []string{"intro.mp3", "in_tro.mp3", "in_tro-2.mp3", "item.mp3", "intro-2.mp3"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("item %d: expected %s, got %s", i, expected[i], names[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package batch
+
+import "sync"
+
+// userLimiter caps the number of batch items synthesized at the same time
+// for one user, across all of that user's running batches
+type userLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]*userSlots
+}
+
+type userSlots struct {
+	semaphore chan struct{}
+	refs      int
+}
+
+func newUserLimiter(limit int) *userLimiter {
+	return &userLimiter{limit: limit, slots: make(map[string]*userSlots)}
+}
+
+func (l *userLimiter) acquire(userId string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[userId]
+	if !ok {
+		slots = &userSlots{semaphore: make(chan struct{}, l.limit)}
+		l.slots[userId] = slots
+	}
+	slots.refs++
+
+	return slots.semaphore
+}
+
+func (l *userLimiter) release(userId string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[userId]
+	if !ok {
+		return
+	}
+
+	slots.refs--
+	if slots.refs == 0 {
+		delete(l.slots, userId)
+	}
+}
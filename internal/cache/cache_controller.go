@@ -0,0 +1,36 @@
+package cache
+
+import (
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CacheController struct {
+	cache SynthesisCache
+}
+
+func NewCacheController(cache SynthesisCache) *CacheController {
+	return &CacheController{cache: cache}
+}
+
+func (controller *CacheController) HandleFetchCacheStats(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling cache stats request...")
+
+	stats := controller.cache.Stats()
+
+	logger.Logger.Info("Handled cache stats request.")
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+func (controller *CacheController) HandleClearCache(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling clear cache request...")
+
+	if err := controller.cache.Clear(); err != nil {
+		return service_errors.NewErrInternalServer("Failed to clear synthesis cache")
+	}
+
+	logger.Logger.Info("Handled clear cache request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
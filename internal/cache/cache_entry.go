@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"time"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+type CacheEntry struct {
+	Hash         string             `gorm:"type:varchar(64);primaryKey;"`
+	ModelId      string             `gorm:"type:varchar(256);not null;index"`
+	Samples      []byte             `gorm:"type:bytea;not null"`
+	SamplingRate int                `gorm:"not null"`
+	Words        []subtitles.Timing `gorm:"type:jsonb;serializer:json"`
+	Sentences    []subtitles.Timing `gorm:"type:jsonb;serializer:json"`
+	CreatedAt    time.Time          `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	AccessedAt   time.Time          `gorm:"column:last_accessed_at;type:timestamp;default:CURRENT_TIMESTAMP;index"`
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CacheRepository interface {
+	Save(entry *CacheEntry) error
+	FindByHash(hash string) (*CacheEntry, error)
+	Touch(hash string) error
+	EvictLeastRecentlyUsed(maxBytes int64) (int, error)
+	DeleteByModelId(modelId string) error
+	DeleteAll() error
+}
+
+type CacheRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCacheRepository(db *gorm.DB) *CacheRepositoryImpl {
+	return &CacheRepositoryImpl{db: db}
+}
+
+func (r *CacheRepositoryImpl) Save(entry *CacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error
+}
+
+func (r *CacheRepositoryImpl) FindByHash(hash string) (*CacheEntry, error) {
+	var entry CacheEntry
+
+	if err := r.db.First(&entry, "hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *CacheRepositoryImpl) Touch(hash string) error {
+	return r.db.Model(&CacheEntry{}).Where("hash = ?", hash).Update("last_accessed_at", time.Now()).Error
+}
+
+// EvictLeastRecentlyUsed deletes the least recently used entries until the
+// remaining ones fit into maxBytes
+func (r *CacheRepositoryImpl) EvictLeastRecentlyUsed(maxBytes int64) (int, error) {
+	result := r.db.Exec(`DELETE FROM cache_entries WHERE hash IN (
+		SELECT hash FROM (
+			SELECT hash, SUM(octet_length(samples)) OVER (ORDER BY last_accessed_at DESC, hash) AS total
+			FROM cache_entries
+		) ranked WHERE total > ?
+	)`, maxBytes)
+
+	return int(result.RowsAffected), result.Error
+}
+
+func (r *CacheRepositoryImpl) DeleteByModelId(modelId string) error {
+	return r.db.Delete(&CacheEntry{}, "model_id = ?", modelId).Error
+}
+
+func (r *CacheRepositoryImpl) DeleteAll() error {
+	return r.db.Where("1 = 1").Delete(&CacheEntry{}).Error
+}
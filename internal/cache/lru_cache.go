@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+type lruEntry struct {
+	key   CacheKey
+	audio *CachedAudio
+	size  int64
+}
+
+type LruCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	entries  map[CacheKey]*list.Element
+}
+
+func NewLruCache(maxBytes int64) *LruCache {
+	return &LruCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[CacheKey]*list.Element),
+	}
+}
+
+func (c *LruCache) Get(key CacheKey) (*CachedAudio, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).audio, true
+}
+
+func (c *LruCache) Put(key CacheKey, audio *CachedAudio) {
+	size := int64(len(audio.Samples)) * 4
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, audio: audio, size: size})
+	c.size += size
+
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LruCache) InvalidateModel(modelId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.entries {
+		if key.ModelId == modelId {
+			c.remove(element)
+		}
+	}
+}
+
+func (c *LruCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[CacheKey]*list.Element)
+	c.size = 0
+}
+
+func (c *LruCache) Usage() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries), c.size
+}
+
+func (c *LruCache) remove(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
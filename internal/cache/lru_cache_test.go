@@ -0,0 +1,88 @@
+package cache
+
+import "testing"
+
+// each sample takes four bytes
+func audioOfSize(bytes int) *CachedAudio {
+	return &CachedAudio{Samples: make([]float32, bytes/4), SamplingRate: 16000}
+}
+
+func lruKey(name string) CacheKey {
+	return CacheKey{ModelId: "model", Hash: name}
+}
+
+func TestLruCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLruCache(300)
+
+	cache.Put(lruKey("a"), audioOfSize(100))
+	cache.Put(lruKey("b"), audioOfSize(100))
+	cache.Put(lruKey("c"), audioOfSize(100))
+
+	// reading a makes b the least recently used entry
+	if _, ok := cache.Get(lruKey("a")); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	cache.Put(lruKey("d"), audioOfSize(100))
+	if _, ok := cache.Get(lruKey("b")); ok {
+		t.Error("expected b to be evicted")
+	}
+
+	cache.Put(lruKey("e"), audioOfSize(200))
+	for _, name := range []string{"c", "a"} {
+		if _, ok := cache.Get(lruKey(name)); ok {
+			t.Errorf("expected %s to be evicted", name)
+		}
+	}
+	for _, name := range []string{"d", "e"} {
+		if _, ok := cache.Get(lruKey(name)); !ok {
+			t.Errorf("expected %s to stay cached", name)
+		}
+	}
+
+	if entries, size := cache.Usage(); entries != 2 || size != 300 {
+		t.Errorf("expected 2 entries of 300 bytes, got %d of %d", entries, size)
+	}
+}
+
+func TestLruCacheReplacesEntry(t *testing.T) {
+	cache := NewLruCache(300)
+
+	cache.Put(lruKey("a"), audioOfSize(100))
+	cache.Put(lruKey("a"), audioOfSize(200))
+
+	if entries, size := cache.Usage(); entries != 1 || size != 200 {
+		t.Errorf("expected 1 entry of 200 bytes, got %d of %d", entries, size)
+	}
+}
+
+func TestLruCacheSkipsOversizedEntries(t *testing.T) {
+	cache := NewLruCache(300)
+
+	cache.Put(lruKey("a"), audioOfSize(100))
+	cache.Put(lruKey("huge"), audioOfSize(400))
+
+	if _, ok := cache.Get(lruKey("huge")); ok {
+		t.Error("expected an entry larger than the cache to be skipped")
+	}
+	if _, ok := cache.Get(lruKey("a")); !ok {
+		t.Error("expected an oversized entry to leave the cache intact")
+	}
+}
+
+func TestLruCacheInvalidateModel(t *testing.T) {
+	cache := NewLruCache(1000)
+
+	cache.Put(CacheKey{ModelId: "first", Hash: "a"}, audioOfSize(100))
+	cache.Put(CacheKey{ModelId: "first", Hash: "b"}, audioOfSize(100))
+	cache.Put(CacheKey{ModelId: "second", Hash: "a"}, audioOfSize(100))
+
+	cache.InvalidateModel("first")
+
+	if entries, size := cache.Usage(); entries != 1 || size != 100 {
+		t.Errorf("expected only the other model's entry to remain, got %d entries of %d bytes", entries, size)
+	}
+	if _, ok := cache.Get(CacheKey{ModelId: "second", Hash: "a"}); !ok {
+		t.Error("expected the other model's entry to stay cached")
+	}
+}
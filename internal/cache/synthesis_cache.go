@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type CacheKey struct {
+	ModelId string
+	Hash    string
+}
+
+type CachedAudio struct {
+	Samples      []float32
+	SamplingRate int
+	Words        []subtitles.Timing
+	Sentences    []subtitles.Timing
+}
+
+type CacheStats struct {
+	Hits           int64   `json:"hits"`
+	Misses         int64   `json:"misses"`
+	MemoryHits     int64   `json:"memory_hits"`
+	PersistentHits int64   `json:"persistent_hits"`
+	HitRatio       float64 `json:"hit_ratio"`
+	Entries        int     `json:"entries"`
+	SizeBytes      int64   `json:"size_bytes"`
+	MaxSizeBytes   int64   `json:"max_size_bytes"`
+	Persistent     bool    `json:"persistent"`
+}
+
+type SynthesisCache interface {
+	Get(key CacheKey) (*CachedAudio, bool)
+	Put(key CacheKey, audio *CachedAudio)
+	InvalidateModel(modelId string)
+	Clear() error
+	Stats() *CacheStats
+}
+
+func NewCacheKey(modelId, versionId, modelUrl, text, variant string) CacheKey {
+	hash := sha256.New()
+	hash.Write([]byte(modelId))
+	hash.Write([]byte{0})
+	hash.Write([]byte(versionId))
+	hash.Write([]byte{0})
+	hash.Write([]byte(modelUrl))
+	hash.Write([]byte{0})
+	hash.Write([]byte(NormalizeText(text)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(variant))
+
+	return CacheKey{ModelId: modelId, Hash: hex.EncodeToString(hash.Sum(nil))}
+}
+
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(text)), " ")
+}
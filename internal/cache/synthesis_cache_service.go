@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"vitaliiPsl/synthesizer/internal/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCacheMaxMegabytes           = 256
+	defaultPersistentCacheMaxMegabytes = 2048
+	// the persistent tier is trimmed after this many writes
+	persistentEvictionInterval = 100
+)
+
+type SynthesisCacheImpl struct {
+	memory         *LruCache
+	repository     CacheRepository
+	persistentMax  int64
+	writes         atomic.Int64
+	hits           atomic.Int64
+	misses         atomic.Int64
+	memoryHits     atomic.Int64
+	persistentHits atomic.Int64
+}
+
+func NewSynthesisCache(repository CacheRepository) *SynthesisCacheImpl {
+	maxMegabytes, err := strconv.Atoi(os.Getenv("SYNTHESIS_CACHE_MAX_MB"))
+	if err != nil || maxMegabytes < 0 {
+		maxMegabytes = defaultCacheMaxMegabytes
+	}
+
+	if persistent, _ := strconv.ParseBool(os.Getenv("SYNTHESIS_CACHE_PERSISTENT")); !persistent {
+		repository = nil
+	}
+
+	persistentMaxMegabytes, err := strconv.Atoi(os.Getenv("SYNTHESIS_CACHE_PERSISTENT_MAX_MB"))
+	if err != nil || persistentMaxMegabytes < 0 {
+		persistentMaxMegabytes = defaultPersistentCacheMaxMegabytes
+	}
+
+	logger.Logger.Info("Configured synthesis cache.", "maxMegabytes", maxMegabytes, "persistent", repository != nil, "persistentMaxMegabytes", persistentMaxMegabytes)
+	return &SynthesisCacheImpl{
+		memory:        NewLruCache(int64(maxMegabytes) * 1024 * 1024),
+		repository:    repository,
+		persistentMax: int64(persistentMaxMegabytes) * 1024 * 1024,
+	}
+}
+
+func (c *SynthesisCacheImpl) Get(key CacheKey) (*CachedAudio, bool) {
+	if audio, ok := c.memory.Get(key); ok {
+		c.hits.Add(1)
+		c.memoryHits.Add(1)
+		return audio, true
+	}
+
+	if c.repository != nil {
+		entry, err := c.repository.FindByHash(key.Hash)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Failed to read persistent cache entry", "hash", key.Hash, "error", err)
+		}
+
+		if entry != nil {
+			audio := &CachedAudio{
+				Samples:      decodeSamples(entry.Samples),
+				SamplingRate: entry.SamplingRate,
+				Words:        entry.Words,
+				Sentences:    entry.Sentences,
+			}
+			c.memory.Put(key, audio)
+
+			if err := c.repository.Touch(key.Hash); err != nil {
+				logger.Logger.Error("Failed to touch persistent cache entry", "hash", key.Hash, "error", err)
+			}
+
+			c.hits.Add(1)
+			c.persistentHits.Add(1)
+			return audio, true
+		}
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+func (c *SynthesisCacheImpl) Put(key CacheKey, audio *CachedAudio) {
+	c.memory.Put(key, audio)
+
+	if c.repository == nil {
+		return
+	}
+
+	entry := &CacheEntry{
+		Hash:         key.Hash,
+		ModelId:      key.ModelId,
+		Samples:      encodeSamples(audio.Samples),
+		SamplingRate: audio.SamplingRate,
+		Words:        audio.Words,
+		Sentences:    audio.Sentences,
+	}
+
+	if err := c.repository.Save(entry); err != nil {
+		logger.Logger.Error("Failed to write persistent cache entry", "hash", key.Hash, "error", err)
+		return
+	}
+
+	if c.writes.Add(1)%persistentEvictionInterval == 0 {
+		c.evictPersistent()
+	}
+}
+
+func (c *SynthesisCacheImpl) evictPersistent() {
+	evicted, err := c.repository.EvictLeastRecentlyUsed(c.persistentMax)
+	if err != nil {
+		logger.Logger.Error("Failed to evict persistent cache entries", "error", err)
+		return
+	}
+
+	if evicted > 0 {
+		logger.Logger.Info("Evicted persistent cache entries.", "count", evicted, "maxBytes", c.persistentMax)
+	}
+}
+
+func (c *SynthesisCacheImpl) InvalidateModel(modelId string) {
+	logger.Logger.Info("Invalidating synthesis cache...", "modelId", modelId)
+
+	c.memory.InvalidateModel(modelId)
+
+	if c.repository != nil {
+		if err := c.repository.DeleteByModelId(modelId); err != nil {
+			logger.Logger.Error("Failed to invalidate persistent cache entries", "modelId", modelId, "error", err)
+		}
+	}
+
+	logger.Logger.Info("Invalidated synthesis cache.", "modelId", modelId)
+}
+
+func (c *SynthesisCacheImpl) OnModelChanged(modelId string) {
+	c.InvalidateModel(modelId)
+}
+
+func (c *SynthesisCacheImpl) Clear() error {
+	logger.Logger.Info("Clearing synthesis cache...")
+
+	c.memory.Clear()
+
+	if c.repository != nil {
+		if err := c.repository.DeleteAll(); err != nil {
+			logger.Logger.Error("Failed to clear persistent cache", "error", err)
+			return err
+		}
+	}
+
+	logger.Logger.Info("Cleared synthesis cache.")
+	return nil
+}
+
+func (c *SynthesisCacheImpl) Stats() *CacheStats {
+	entries, size := c.memory.Usage()
+	hits, misses := c.hits.Load(), c.misses.Load()
+
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+
+	return &CacheStats{
+		Hits:           hits,
+		Misses:         misses,
+		MemoryHits:     c.memoryHits.Load(),
+		PersistentHits: c.persistentHits.Load(),
+		HitRatio:       ratio,
+		Entries:        entries,
+		SizeBytes:      size,
+		MaxSizeBytes:   c.memory.maxBytes,
+		Persistent:     c.repository != nil,
+	}
+}
+
+func encodeSamples(samples []float32) []byte {
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, samples)
+	return buffer.Bytes()
+}
+
+func decodeSamples(data []byte) []float32 {
+	samples := make([]float32, len(data)/4)
+	binary.Read(bytes.NewReader(data), binary.LittleEndian, samples)
+	return samples
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestCacheKeyIsStableAcrossSpellings(t *testing.T) {
+	base := NewCacheKey("model", "version", "http://model", "Café au lait", "")
+
+	equivalent := []string{
+		// decomposed: e followed by a combining acute accent
+		"Cafe\u0301 au lait",
+		"  Caf\u00e9   au\tlait\n",
+		"Caf\u00e9\u00a0au lait",
+	}
+	for _, text := range equivalent {
+		if key := NewCacheKey("model", "version", "http://model", text, ""); key != base {
+			t.Errorf("expected %q to share the key of %q", text, "Café au lait")
+		}
+	}
+
+	different := map[string]CacheKey{
+		"text":    NewCacheKey("model", "version", "http://model", "café au lait", ""),
+		"model":   NewCacheKey("other", "version", "http://model", "Café au lait", ""),
+		"version": NewCacheKey("model", "other", "http://model", "Café au lait", ""),
+		"url":     NewCacheKey("model", "version", "http://other", "Café au lait", ""),
+		"variant": NewCacheKey("model", "version", "http://model", "Café au lait", "rate=1.5"),
+	}
+	for name, key := range different {
+		if key.Hash == base.Hash {
+			t.Errorf("expected a different %s to change the key", name)
+		}
+	}
+
+	// fields are separated, so moving a character between them changes the key
+	if NewCacheKey("ab", "c", "", "text", "").Hash == NewCacheKey("a", "bc", "", "text", "").Hash {
+		t.Error("expected field boundaries to be part of the key")
+	}
+}
+
+type memoryCacheRepository struct {
+	entries   map[string]*CacheEntry
+	err       error
+	deleted   []string
+	touched   []string
+	evictions []int64
+	clock     int
+}
+
+func newMemoryCacheRepository() *memoryCacheRepository {
+	return &memoryCacheRepository{entries: map[string]*CacheEntry{}}
+}
+
+// tick stands in for the current time, so that every access is ordered
+func (r *memoryCacheRepository) tick() time.Time {
+	r.clock++
+	return time.Unix(int64(r.clock), 0)
+}
+
+func (r *memoryCacheRepository) Save(entry *CacheEntry) error {
+	entry.AccessedAt = r.tick()
+	r.entries[entry.Hash] = entry
+	return r.err
+}
+
+func (r *memoryCacheRepository) Touch(hash string) error {
+	r.touched = append(r.touched, hash)
+	if entry, ok := r.entries[hash]; ok {
+		entry.AccessedAt = r.tick()
+	}
+	return r.err
+}
+
+func (r *memoryCacheRepository) EvictLeastRecentlyUsed(maxBytes int64) (int, error) {
+	r.evictions = append(r.evictions, maxBytes)
+
+	entries := make([]*CacheEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b *CacheEntry) int {
+		return b.AccessedAt.Compare(a.AccessedAt)
+	})
+
+	var total int64
+	evicted := 0
+	for _, entry := range entries {
+		total += int64(len(entry.Samples))
+		if total > maxBytes {
+			delete(r.entries, entry.Hash)
+			evicted++
+		}
+	}
+
+	return evicted, r.err
+}
+
+func (r *memoryCacheRepository) FindByHash(hash string) (*CacheEntry, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	entry, ok := r.entries[hash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return entry, nil
+}
+
+func (r *memoryCacheRepository) DeleteByModelId(modelId string) error {
+	r.deleted = append(r.deleted, modelId)
+	for hash, entry := range r.entries {
+		if entry.ModelId == modelId {
+			delete(r.entries, hash)
+		}
+	}
+	return r.err
+}
+
+func (r *memoryCacheRepository) DeleteAll() error {
+	r.entries = map[string]*CacheEntry{}
+	return r.err
+}
+
+func newPersistentCache(t *testing.T, repository CacheRepository) *SynthesisCacheImpl {
+	t.Setenv("SYNTHESIS_CACHE_PERSISTENT", "true")
+	t.Setenv("SYNTHESIS_CACHE_MAX_MB", "1")
+	return NewSynthesisCache(repository)
+}
+
+func TestSynthesisCacheFallsBackToPersistentTier(t *testing.T) {
+	repository := newMemoryCacheRepository()
+	key := NewCacheKey("model", "", "", "hello", "")
+	audio := &CachedAudio{Samples: []float32{0.25, -0.5, 1}, SamplingRate: 22050}
+
+	// written by another instance, or before a restart
+	newPersistentCache(t, repository).Put(key, audio)
+	cache := newPersistentCache(t, repository)
+
+	cached, ok := cache.Get(key)
+	if !ok {
+		t.Fatal("expected a hit from the persistent tier")
+	}
+	if !reflect.DeepEqual(cached.Samples, audio.Samples) || cached.SamplingRate != audio.SamplingRate {
+		t.Errorf("expected %+v, got %+v", audio, cached)
+	}
+
+	// the entry is promoted to memory
+	repository.err = errors.New("connection refused")
+	if _, ok := cache.Get(key); !ok {
+		t.Fatal("expected a hit from memory")
+	}
+
+	stats := cache.Stats()
+	if stats.PersistentHits != 1 || stats.MemoryHits != 1 || stats.Misses != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSynthesisCacheTreatsPersistentFailureAsMiss(t *testing.T) {
+	repository := newMemoryCacheRepository()
+	repository.err = errors.New("connection refused")
+	cache := newPersistentCache(t, repository)
+
+	if _, ok := cache.Get(NewCacheKey("model", "", "", "hello", "")); ok {
+		t.Error("expected a miss")
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Hits != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSynthesisCacheWithoutPersistentTier(t *testing.T) {
+	t.Setenv("SYNTHESIS_CACHE_PERSISTENT", "")
+	repository := newMemoryCacheRepository()
+	cache := NewSynthesisCache(repository)
+
+	cache.Put(NewCacheKey("model", "", "", "hello", ""), &CachedAudio{Samples: []float32{1}})
+	if len(repository.entries) != 0 {
+		t.Error("expected nothing to be written to the persistent tier")
+	}
+	if cache.Stats().Persistent {
+		t.Error("expected the cache to report no persistent tier")
+	}
+}
+
+func TestSynthesisCacheOnModelChanged(t *testing.T) {
+	repository := newMemoryCacheRepository()
+	cache := newPersistentCache(t, repository)
+
+	changed := NewCacheKey("changed", "", "", "hello", "")
+	other := NewCacheKey("other", "", "", "hello", "")
+	cache.Put(changed, &CachedAudio{Samples: []float32{1}})
+	cache.Put(other, &CachedAudio{Samples: []float32{1}})
+
+	cache.OnModelChanged("changed")
+
+	if _, ok := cache.Get(changed); ok {
+		t.Error("expected the changed model's audio to be invalidated in both tiers")
+	}
+	if _, ok := cache.Get(other); !ok {
+		t.Error("expected other models' audio to stay cached")
+	}
+	if !reflect.DeepEqual(repository.deleted, []string{"changed"}) {
+		t.Errorf("expected persistent entries of the changed model to be deleted, got %v", repository.deleted)
+	}
+}
+
+func TestSynthesisCacheCapsPersistentTier(t *testing.T) {
+	repository := newMemoryCacheRepository()
+	t.Setenv("SYNTHESIS_CACHE_PERSISTENT_MAX_MB", "1")
+	cache := newPersistentCache(t, repository)
+
+	// a quarter of a megabyte per entry, so only four fit
+	audio := &CachedAudio{Samples: make([]float32, 64*1024), SamplingRate: 22050}
+	keys := make([]CacheKey, persistentEvictionInterval)
+	for i := range keys {
+		keys[i] = NewCacheKey("model", "", "", fmt.Sprintf("sentence %d", i), "")
+		cache.Put(keys[i], audio)
+
+		if i == 0 {
+			// read back through a fresh instance, which bumps the entry
+			if _, ok := newPersistentCache(t, repository).Get(keys[0]); !ok {
+				t.Fatal("expected a persistent hit")
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(repository.touched, []string{keys[0].Hash}) {
+		t.Errorf("expected the persistent hit to be touched, got %v", repository.touched)
+	}
+	if !reflect.DeepEqual(repository.evictions, []int64{1024 * 1024}) {
+		t.Fatalf("expected one eviction capped at 1MB, got %v", repository.evictions)
+	}
+
+	if len(repository.entries) != 4 {
+		t.Errorf("expected 4 entries to remain, got %d", len(repository.entries))
+	}
+	for _, key := range keys[len(keys)-4:] {
+		if _, ok := repository.entries[key.Hash]; !ok {
+			t.Errorf("expected recent entry %s to remain", key.Hash)
+		}
+	}
+}
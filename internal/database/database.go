@@ -3,7 +3,11 @@ package database
 import (
 	"os"
 	"time"
+	"vitaliiPsl/synthesizer/internal/auth/session"
+	"vitaliiPsl/synthesizer/internal/cache"
 	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/job"
+	"vitaliiPsl/synthesizer/internal/lexicon"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/token"
 	"vitaliiPsl/synthesizer/internal/users"
@@ -38,6 +42,6 @@ func SetupDatabase() {
 	logger.Logger.Info("Connected to the database.")
 
 	logger.Logger.Info("Migrating models...")
-	DB.AutoMigrate(&users.User{}, &token.Token{}, &history.HistoryRecord{}, &model.Model{})
+	DB.AutoMigrate(&users.User{}, &token.Token{}, &session.Session{}, &session.RefreshToken{}, &history.HistoryRecord{}, &model.Model{}, &model.ModelVersion{}, &model.ModelReplica{}, &model.ModelLanguageRule{}, &job.SynthesisJob{}, &job.SynthesisJobResult{}, &cache.CacheEntry{}, &lexicon.Lexicon{}, &lexicon.LexiconEntry{})
 	logger.Logger.Info("Migrated models.")
 }
@@ -0,0 +1,86 @@
+package dsp
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSilenceThresholdDb = -50.0
+	defaultSilencePadding     = 50 * time.Millisecond
+	defaultPeakCeilingDb      = -1.0
+)
+
+// Options select the post-processing steps; zero values leave audio untouched
+type Options struct {
+	SamplingRate int
+	TrimSilence  bool
+	LoudnessLufs float64
+	BitDepth     int
+}
+
+type ProcessedAudio struct {
+	Samples      []float32
+	SamplingRate int
+	BitDepth     int
+	// milliseconds cut from the start by silence trimming
+	TrimmedMs int
+}
+
+// AudioProcessor runs the post-processing chain in a fixed order: down-mix,
+// trim silence, resample, normalize loudness and quantize. Loudness is
+// measured after resampling so it reflects the delivered audio.
+type AudioProcessor struct {
+	silenceThresholdDb float64
+	silencePadding     time.Duration
+	peakCeilingDb      float64
+}
+
+func NewAudioProcessor() *AudioProcessor {
+	processor := &AudioProcessor{
+		silenceThresholdDb: defaultSilenceThresholdDb,
+		silencePadding:     defaultSilencePadding,
+		peakCeilingDb:      defaultPeakCeilingDb,
+	}
+
+	if value, err := strconv.ParseFloat(os.Getenv("DSP_SILENCE_THRESHOLD_DB"), 64); err == nil {
+		processor.silenceThresholdDb = value
+	}
+
+	if value, err := strconv.Atoi(os.Getenv("DSP_SILENCE_PADDING_MS")); err == nil && value >= 0 {
+		processor.silencePadding = time.Duration(value) * time.Millisecond
+	}
+
+	if value, err := strconv.ParseFloat(os.Getenv("DSP_PEAK_CEILING_DB"), 64); err == nil && value <= 0 {
+		processor.peakCeilingDb = value
+	}
+
+	return processor
+}
+
+func (p *AudioProcessor) Process(samples []float32, samplingRate, channels int, options Options) *ProcessedAudio {
+	processed := &ProcessedAudio{Samples: Downmix(samples, channels), SamplingRate: samplingRate}
+
+	if options.TrimSilence {
+		trimmed, removed := TrimSilence(processed.Samples, processed.SamplingRate, p.silenceThresholdDb, p.silencePadding)
+		processed.Samples = trimmed
+		processed.TrimmedMs = removed * 1000 / max(1, processed.SamplingRate)
+	}
+
+	if options.SamplingRate > 0 && options.SamplingRate != processed.SamplingRate {
+		processed.Samples = Resample(processed.Samples, processed.SamplingRate, options.SamplingRate)
+		processed.SamplingRate = options.SamplingRate
+	}
+
+	if options.LoudnessLufs != 0 {
+		processed.Samples = NormalizeLoudness(processed.Samples, processed.SamplingRate, options.LoudnessLufs, p.peakCeilingDb)
+	}
+
+	if options.BitDepth > 0 {
+		processed.Samples = Quantize(processed.Samples, options.BitDepth)
+		processed.BitDepth = options.BitDepth
+	}
+
+	return processed
+}
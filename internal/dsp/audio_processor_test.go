@@ -0,0 +1,84 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDownmix(t *testing.T) {
+	mono := Downmix([]float32{1, 0, 0.5, 0.5, -1, 1}, 2)
+
+	expected := []float32{0.5, 0.5, 0}
+	if len(mono) != len(expected) {
+		t.Fatalf("expected %d samples, got %d", len(expected), len(mono))
+	}
+	for i := range expected {
+		if mono[i] != expected[i] {
+			t.Errorf("sample %d: expected %f, got %f", i, expected[i], mono[i])
+		}
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	tests := []struct {
+		name     string
+		bitDepth int
+		input    float32
+		expected float32
+	}{
+		{"8 bit", 8, 0.5, 64.0 / 127},
+		{"16 bit", 16, 0.1, float32(math.Round(0.1*32767) / 32767)},
+		{"clips above full scale", 16, 1.5, 1},
+		{"clips below full scale", 16, -1.5, -1},
+		{"nan becomes silence", 16, float32(math.NaN()), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if quantized := Quantize([]float32{test.input}, test.bitDepth); quantized[0] != test.expected {
+				t.Errorf("expected %f, got %f", test.expected, quantized[0])
+			}
+		})
+	}
+}
+
+func TestProcessWithoutOptions(t *testing.T) {
+	samples := sine(440, 0.5, 16000, 0.5)
+
+	processed := NewAudioProcessor().Process(samples, 16000, 1, Options{})
+	if processed.SamplingRate != 16000 || processed.TrimmedMs != 0 || processed.BitDepth != 0 {
+		t.Errorf("unexpected processing: %+v", processed)
+	}
+	for i := range samples {
+		if processed.Samples[i] != samples[i] {
+			t.Fatalf("sample %d changed", i)
+		}
+	}
+}
+
+func TestProcessChain(t *testing.T) {
+	samples := append(make([]float32, 8000), sine(440, 0.05, 16000, 2)...)
+
+	processed := NewAudioProcessor().Process(samples, 16000, 1, Options{
+		SamplingRate: 48000,
+		TrimSilence:  true,
+		LoudnessLufs: -20,
+		BitDepth:     16,
+	})
+
+	if processed.SamplingRate != 48000 {
+		t.Errorf("expected 48000 Hz, got %d", processed.SamplingRate)
+	}
+	if processed.TrimmedMs != 450 {
+		t.Errorf("expected 450ms trimmed, got %d", processed.TrimmedMs)
+	}
+	if expected := (2000 + 50) * 48; len(processed.Samples) != expected {
+		t.Errorf("expected %d samples, got %d", expected, len(processed.Samples))
+	}
+	if loudness := IntegratedLoudness(processed.Samples, 48000); math.Abs(loudness+20) > 0.1 {
+		t.Errorf("expected -20 LUFS, got %.2f", loudness)
+	}
+	if processed.BitDepth != 16 {
+		t.Errorf("expected 16 bit, got %d", processed.BitDepth)
+	}
+}
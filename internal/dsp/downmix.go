@@ -0,0 +1,19 @@
+package dsp
+
+// Downmix averages interleaved channels into a single one
+func Downmix(samples []float32, channels int) []float32 {
+	if channels <= 1 {
+		return samples
+	}
+
+	mono := make([]float32, len(samples)/channels)
+	for i := range mono {
+		var sum float32
+		for _, sample := range samples[i*channels : (i+1)*channels] {
+			sum += sample
+		}
+		mono[i] = sum / float32(channels)
+	}
+
+	return mono
+}
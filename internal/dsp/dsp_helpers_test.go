@@ -0,0 +1,12 @@
+package dsp
+
+import "math"
+
+func sine(frequency, amplitude float64, samplingRate int, seconds float64) []float32 {
+	samples := make([]float32, int(seconds*float64(samplingRate)))
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(samplingRate)))
+	}
+
+	return samples
+}
@@ -0,0 +1,161 @@
+package dsp
+
+import "math"
+
+const (
+	loudnessBlock        = 0.4
+	loudnessBlockStep    = 0.1
+	loudnessAbsoluteGate = -70.0
+	loudnessRelativeGate = -10.0
+	loudnessOffset       = -0.691
+	shelfFrequency       = 1681.974450955533
+	shelfGainDb          = 3.999843853973347
+	shelfQ               = 0.7071752369554196
+	highPassFrequency    = 38.13547087602444
+	highPassQ            = 0.5003270373238773
+)
+
+// IntegratedLoudness measures programme loudness in LUFS as described in
+// ITU-R BS.1770-4: K-weighting followed by gated 400ms blocks overlapping by
+// 75%. Audio shorter than a block is measured as a single block. Silence
+// measures as negative infinity.
+func IntegratedLoudness(samples []float32, samplingRate int) float64 {
+	if len(samples) == 0 || samplingRate <= 0 {
+		return math.Inf(-1)
+	}
+
+	weighted := kWeight(samples, samplingRate)
+
+	blockSize := int(loudnessBlock * float64(samplingRate))
+	step := int(loudnessBlockStep * float64(samplingRate))
+
+	var powers []float64
+	if len(weighted) < blockSize {
+		powers = append(powers, meanSquare(weighted))
+	}
+	for start := 0; start+blockSize <= len(weighted); start += step {
+		powers = append(powers, meanSquare(weighted[start:start+blockSize]))
+	}
+
+	absolute := gatedMean(powers, powerForLoudness(loudnessAbsoluteGate))
+	if absolute == 0 {
+		return math.Inf(-1)
+	}
+
+	relative := gatedMean(powers, max(powerForLoudness(loudnessAbsoluteGate), absolute*math.Pow(10, loudnessRelativeGate/10)))
+	if relative == 0 {
+		return math.Inf(-1)
+	}
+
+	return loudnessOffset + 10*math.Log10(relative)
+}
+
+// NormalizeLoudness applies the gain that brings the integrated loudness to
+// targetLufs. The gain is lowered when it would push the sample peak above
+// ceilingDb, so quiet targets are met exactly and loud ones may fall short
+// rather than clip.
+func NormalizeLoudness(samples []float32, samplingRate int, targetLufs, ceilingDb float64) []float32 {
+	loudness := IntegratedLoudness(samples, samplingRate)
+	if math.IsInf(loudness, -1) {
+		return samples
+	}
+
+	gainDb := targetLufs - loudness
+	if peak := Peak(samples); peak > 0 {
+		gainDb = math.Min(gainDb, ceilingDb-20*math.Log10(peak))
+	}
+
+	gain := math.Pow(10, gainDb/20)
+	normalized := make([]float32, len(samples))
+	for i, sample := range samples {
+		normalized[i] = float32(float64(sample) * gain)
+	}
+
+	return normalized
+}
+
+// Peak returns the largest absolute sample value
+func Peak(samples []float32) float64 {
+	var peak float64
+	for _, sample := range samples {
+		peak = math.Max(peak, math.Abs(float64(sample)))
+	}
+
+	return peak
+}
+
+// K-weighting is a high shelf modelling the head followed by a high pass,
+// with coefficients derived for the given sampling rate
+func kWeight(samples []float32, samplingRate int) []float64 {
+	k := math.Tan(math.Pi * shelfFrequency / float64(samplingRate))
+	vh := math.Pow(10, shelfGainDb/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * highPassFrequency / float64(samplingRate))
+	a0 = 1 + k/highPassQ + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/highPassQ + k*k) / a0,
+	}
+
+	weighted := make([]float64, len(samples))
+	for i, sample := range samples {
+		weighted[i] = highPass.process(shelf.process(float64(sample)))
+	}
+
+	return weighted
+}
+
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+func meanSquare(samples []float64) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += sample * sample
+	}
+
+	return sum / float64(max(1, len(samples)))
+}
+
+// mean of the block powers above the gate, zero when none pass
+func gatedMean(powers []float64, gate float64) float64 {
+	var sum float64
+	count := 0
+	for _, power := range powers {
+		if power > gate {
+			sum += power
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return sum / float64(count)
+}
+
+func powerForLoudness(lufs float64) float64 {
+	return math.Pow(10, (lufs-loudnessOffset)/10)
+}
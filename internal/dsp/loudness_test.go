@@ -0,0 +1,70 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntegratedLoudness(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []float32
+		rate     int
+		expected float64
+	}{
+		// BS.1770 reference: a full scale 1kHz sine reads -3.01 LUFS
+		{"full scale sine", sine(1000, 1, 48000, 2), 48000, -3.01},
+		{"full scale sine at 44.1kHz", sine(1000, 1, 44100, 2), 44100, -3.01},
+		{"-20 dBFS sine", sine(1000, 0.1, 48000, 2), 48000, -23.01},
+		{"shorter than a block", sine(1000, 1, 48000, 0.2), 48000, -3.01},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if loudness := IntegratedLoudness(test.samples, test.rate); math.Abs(loudness-test.expected) > 0.05 {
+				t.Errorf("expected %.2f LUFS, got %.2f", test.expected, loudness)
+			}
+		})
+	}
+}
+
+func TestIntegratedLoudnessOfSilence(t *testing.T) {
+	if loudness := IntegratedLoudness(make([]float32, 48000), 48000); !math.IsInf(loudness, -1) {
+		t.Errorf("expected negative infinity, got %f", loudness)
+	}
+}
+
+func TestIntegratedLoudnessGatesSilence(t *testing.T) {
+	tone := sine(1000, 0.1, 48000, 2)
+	padded := append(append(make([]float32, 48000*2), tone...), make([]float32, 48000*2)...)
+
+	// ungated, two thirds of silence would lower it by 4.8 LU; only the blocks
+	// straddling the tone edges should count
+	if diff := IntegratedLoudness(padded, 48000) - IntegratedLoudness(tone, 48000); math.Abs(diff) > 1 {
+		t.Errorf("silence changed loudness by %.2f LU", diff)
+	}
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	normalized := NormalizeLoudness(sine(1000, 0.05, 48000, 2), 48000, -16, -1)
+
+	if loudness := IntegratedLoudness(normalized, 48000); math.Abs(loudness+16) > 0.05 {
+		t.Errorf("expected -16 LUFS, got %.2f", loudness)
+	}
+}
+
+func TestNormalizeLoudnessRespectsCeiling(t *testing.T) {
+	normalized := NormalizeLoudness(sine(1000, 0.05, 48000, 2), 48000, 0, -1)
+
+	if peak := Peak(normalized); peak > math.Pow(10, -1.0/20)+1e-6 {
+		t.Errorf("expected peak below -1 dBFS, got %f", peak)
+	}
+}
+
+func TestNormalizeLoudnessLeavesSilence(t *testing.T) {
+	silence := make([]float32, 48000)
+
+	if normalized := NormalizeLoudness(silence, 48000, -16, -1); Peak(normalized) != 0 {
+		t.Error("expected silence to stay silent")
+	}
+}
@@ -0,0 +1,26 @@
+package dsp
+
+import "math"
+
+// Quantize rounds samples to the signed PCM grid of the given bit depth,
+// clipping anything outside [-1, 1]. The result is what an integer encoder of
+// that depth would store, so later encoding doesn't round twice.
+func Quantize(samples []float32, bitDepth int) []float32 {
+	if bitDepth <= 0 {
+		return samples
+	}
+
+	scale := math.Pow(2, float64(bitDepth-1)) - 1
+	quantized := make([]float32, len(samples))
+	for i, sample := range samples {
+		value := float64(sample)
+		if math.IsNaN(value) {
+			continue
+		}
+
+		value = math.Max(-1, math.Min(1, value))
+		quantized[i] = float32(math.Round(value*scale) / scale)
+	}
+
+	return quantized
+}
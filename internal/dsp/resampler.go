@@ -0,0 +1,53 @@
+package dsp
+
+import "math"
+
+// zero crossings of the sinc kernel on each side at the lower of the two rates
+const resampleZeroCrossings = 16
+
+// Resample converts samples between sampling rates with a Blackman windowed
+// sinc. The kernel cuts off at the lower Nyquist frequency, so downsampling
+// doesn't alias.
+func Resample(samples []float32, from, to int) []float32 {
+	if from == to || from <= 0 || to <= 0 || len(samples) == 0 {
+		return samples
+	}
+
+	ratio := float64(to) / float64(from)
+	cutoff := math.Min(1, ratio)
+	halfWidth := resampleZeroCrossings / cutoff
+
+	resampled := make([]float32, int(math.Round(float64(len(samples))*ratio)))
+	for i := range resampled {
+		center := float64(i) / ratio
+		first := max(0, int(math.Ceil(center-halfWidth)))
+		last := min(len(samples)-1, int(math.Floor(center+halfWidth)))
+
+		var sum float64
+		for j := first; j <= last; j++ {
+			distance := float64(j) - center
+			sum += float64(samples[j]) * cutoff * sinc(cutoff*distance) * blackman(distance/halfWidth)
+		}
+
+		resampled[i] = float32(sum)
+	}
+
+	return resampled
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman window over [-1, 1]
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
@@ -0,0 +1,50 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResampleLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     int
+		to       int
+		input    int
+		expected int
+	}{
+		{"upsample", 24000, 48000, 24000, 48000},
+		{"downsample", 48000, 16000, 48000, 16000},
+		{"fractional", 22050, 16000, 22050, 16000},
+		{"same rate", 16000, 16000, 1234, 1234},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resampled := Resample(make([]float32, test.input), test.from, test.to)
+			if len(resampled) != test.expected {
+				t.Errorf("expected %d samples, got %d", test.expected, len(resampled))
+			}
+		})
+	}
+}
+
+func TestResamplePreservesTone(t *testing.T) {
+	resampled := Resample(sine(440, 0.5, 22050, 1), 22050, 48000)
+	expected := sine(440, 0.5, 48000, 1)
+
+	// edges lack half the kernel, compare the middle
+	for i := 4800; i < len(expected)-4800; i++ {
+		if diff := math.Abs(float64(resampled[i] - expected[i])); diff > 2e-3 {
+			t.Fatalf("sample %d differs by %f", i, diff)
+		}
+	}
+}
+
+func TestResampleRemovesFrequenciesAboveNyquist(t *testing.T) {
+	resampled := Resample(sine(10000, 0.5, 48000, 1), 48000, 16000)
+
+	if peak := Peak(resampled[1600 : len(resampled)-1600]); peak > 0.01 {
+		t.Errorf("expected aliasing to be suppressed, peak is %f", peak)
+	}
+}
@@ -0,0 +1,50 @@
+package dsp
+
+import (
+	"math"
+	"time"
+)
+
+// TrimSilence drops leading and trailing audio whose level stays below
+// thresholdDb (dBFS) over 10ms frames, keeping padding around what remains.
+// It returns the trimmed samples and the number of samples removed from the
+// start. Audio that is silent throughout is trimmed to nothing.
+func TrimSilence(samples []float32, samplingRate int, thresholdDb float64, padding time.Duration) ([]float32, int) {
+	if len(samples) == 0 || samplingRate <= 0 {
+		return samples, 0
+	}
+
+	frameSize := max(1, samplingRate/100)
+	frames := (len(samples) + frameSize - 1) / frameSize
+
+	first, last := -1, -1
+	for frame := 0; frame < frames; frame++ {
+		start := frame * frameSize
+		if levelDb(samples[start:min(start+frameSize, len(samples))]) > thresholdDb {
+			if first < 0 {
+				first = frame
+			}
+			last = frame
+		}
+	}
+
+	if first < 0 {
+		return samples[:0], 0
+	}
+
+	paddingSamples := int(padding.Seconds() * float64(samplingRate))
+	start := max(0, first*frameSize-paddingSamples)
+	end := min(len(samples), (last+1)*frameSize+paddingSamples)
+
+	return samples[start:end], start
+}
+
+// root mean square level in dBFS
+func levelDb(samples []float32) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+
+	return 10 * math.Log10(sum/float64(len(samples)))
+}
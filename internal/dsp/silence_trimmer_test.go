@@ -0,0 +1,48 @@
+package dsp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrimSilence(t *testing.T) {
+	const rate = 16000
+
+	tone := sine(440, 0.5, rate, 1)
+	audio := append(append(make([]float32, rate/2), tone...), make([]float32, rate*3/10)...)
+
+	tests := []struct {
+		name           string
+		samples        []float32
+		padding        time.Duration
+		expectedStart  int
+		expectedLength int
+	}{
+		{"leading and trailing silence", audio, 0, rate / 2, rate},
+		{"padding", audio, 50 * time.Millisecond, rate/2 - 800, rate + 1600},
+		{"padding beyond the edges", tone, 50 * time.Millisecond, 0, rate},
+		{"silence only", make([]float32, rate), 0, 0, 0},
+		{"empty", nil, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trimmed, start := TrimSilence(test.samples, rate, -50, test.padding)
+			if start != test.expectedStart {
+				t.Errorf("expected start %d, got %d", test.expectedStart, start)
+			}
+			if len(trimmed) != test.expectedLength {
+				t.Errorf("expected %d samples, got %d", test.expectedLength, len(trimmed))
+			}
+		})
+	}
+}
+
+func TestTrimSilenceKeepsQuietSpeechAboveThreshold(t *testing.T) {
+	quiet := sine(440, 0.01, 16000, 0.5)
+
+	trimmed, _ := TrimSilence(quiet, 16000, -50, 0)
+	if len(trimmed) != len(quiet) {
+		t.Errorf("expected %d samples, got %d", len(quiet), len(trimmed))
+	}
+}
@@ -17,6 +17,16 @@ func ErrorHandler(ctx *fiber.Ctx, err error) error {
 		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": e.Error()})
 	case *ErrUnauthorized:
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": e.Error()})
+	case *ErrBadGateway:
+		return ctx.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": e.Error()})
+	case *ErrNotAcceptable:
+		return ctx.Status(fiber.StatusNotAcceptable).JSON(fiber.Map{"error": e.Error()})
+	case *ErrConflict:
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+	case *ErrGone:
+		return ctx.Status(fiber.StatusGone).JSON(fiber.Map{"error": e.Error()})
+	case *ErrServiceUnavailable:
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": e.Error()})
 	default:
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
 	}
@@ -70,7 +70,6 @@ func NewErrInternalServer(message string) *ErrInternalServer {
 
 type ErrBadGateway struct {
 	ErrInternal
-	Message string
 }
 
 func NewErrBadGateway(message string) *ErrBadGateway {
@@ -80,3 +79,51 @@ func NewErrBadGateway(message string) *ErrBadGateway {
 		},
 	}
 }
+
+type ErrServiceUnavailable struct {
+	ErrInternal
+}
+
+func NewErrServiceUnavailable(message string) *ErrServiceUnavailable {
+	return &ErrServiceUnavailable{
+		ErrInternal: ErrInternal{
+			Message: message,
+		},
+	}
+}
+
+type ErrGone struct {
+	ErrInternal
+}
+
+func NewErrGone(message string) *ErrGone {
+	return &ErrGone{
+		ErrInternal: ErrInternal{
+			Message: message,
+		},
+	}
+}
+
+type ErrConflict struct {
+	ErrInternal
+}
+
+func NewErrConflict(message string) *ErrConflict {
+	return &ErrConflict{
+		ErrInternal: ErrInternal{
+			Message: message,
+		},
+	}
+}
+
+type ErrNotAcceptable struct {
+	ErrInternal
+}
+
+func NewErrNotAcceptable(message string) *ErrNotAcceptable {
+	return &ErrNotAcceptable{
+		ErrInternal: ErrInternal{
+			Message: message,
+		},
+	}
+}
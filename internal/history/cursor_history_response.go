@@ -0,0 +1,7 @@
+package history
+
+type CursorHistoryResponse struct {
+	Records    []HistoryRecordDto `json:"records"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	HasMore    bool               `json:"hasMore"`
+}
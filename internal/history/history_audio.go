@@ -0,0 +1,7 @@
+package history
+
+type HistoryAudio struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
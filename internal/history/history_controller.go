@@ -1,7 +1,11 @@
 package history
 
 import (
+	"strings"
+	"time"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/subtitles"
 	"vitaliiPsl/synthesizer/internal/users"
 
 	"github.com/gofiber/fiber/v2"
@@ -30,17 +34,18 @@ func (controller *HistoryController) HandleFetchHistory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
 	}
 
-	page := c.QueryInt("page", 1)
-	if page < 1 {
-		page = 1
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		logger.Logger.Error("Failed to parse history query", "message", err.Error())
+		return err
 	}
 
-	limit := c.QueryInt("limit", 10)
-	if limit < 1 {
-		limit = 10
+	var response interface{}
+	if c.Context().QueryArgs().Has("cursor") {
+		response, err = controller.service.GetHistoryRecordsByCursor(userDto, query)
+	} else {
+		response, err = controller.service.GetHistoryRecordsByUserId(userDto, query)
 	}
-
-	response, err := controller.service.GetHistoryRecordsByUserId(userDto, page, limit)
 	if err != nil {
 		logger.Logger.Error("Failed to handle history request", "message", err.Error())
 		return err
@@ -98,3 +103,164 @@ func (controller *HistoryController) DeleteHistoryRecord(c *fiber.Ctx) error {
 	logger.Logger.Info("Handled delete history record request.")
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
+
+func (controller *HistoryController) HandleFetchHistoryRecordAudio(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling history record audio request...")
+
+	tempUser := c.Locals("user")
+	if tempUser == nil {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	userDto, ok := tempUser.(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	recordId := c.Params("id")
+	if recordId == "" {
+		logger.Logger.Error("Record Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Record Id is required",
+		})
+	}
+
+	audio, err := controller.service.GetHistoryRecordAudio(recordId, userDto.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to handle history record audio request", "message", err.Error())
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, audio.ContentType)
+
+	logger.Logger.Info("Handled history record audio request.", "id", recordId)
+	return c.Status(fiber.StatusOK).Send(audio.Data)
+}
+
+func (controller *HistoryController) HandleFetchHistoryRecordSubtitles(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling history record subtitles request...")
+
+	tempUser := c.Locals("user")
+	if tempUser == nil {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	userDto, ok := tempUser.(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	recordId := c.Params("id")
+	if recordId == "" {
+		logger.Logger.Error("Record Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Record Id is required",
+		})
+	}
+
+	format, err := subtitles.ParseFormat(c.Query("format"))
+	if err != nil {
+		return service_errors.NewErrBadRequest(err.Error())
+	}
+
+	granularity, err := subtitles.ParseGranularity(c.Query("granularity"))
+	if err != nil {
+		return service_errors.NewErrBadRequest(err.Error())
+	}
+
+	rendered, err := controller.service.GetHistoryRecordSubtitles(recordId, userDto.Id, format, granularity)
+	if err != nil {
+		logger.Logger.Error("Failed to handle history record subtitles request", "message", err.Error())
+		return err
+	}
+
+	c.Attachment(recordId + "." + format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+
+	logger.Logger.Info("Handled history record subtitles request.", "id", recordId)
+	return c.Status(fiber.StatusOK).SendString(rendered)
+}
+
+const (
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 100
+)
+
+func parseHistoryQuery(c *fiber.Ctx) (*HistoryQuery, error) {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	query := &HistoryQuery{
+		Search:     strings.TrimSpace(c.Query("q")),
+		ModelId:    c.Query("modelId"),
+		Language:   c.Query("language"),
+		Sort:       HistorySort(c.Query("sort", string(SortCreatedAt))),
+		Descending: true,
+		Page:       page,
+		Limit:      limit,
+	}
+
+	if _, ok := query.Sort.Column(); !ok {
+		return nil, service_errors.NewErrBadRequest("Unsupported sort: " + string(query.Sort))
+	}
+
+	switch strings.ToLower(c.Query("order", "desc")) {
+	case "asc":
+		query.Descending = false
+	case "desc":
+		query.Descending = true
+	default:
+		return nil, service_errors.NewErrBadRequest("Order must be either 'asc' or 'desc'")
+	}
+
+	var err error
+	if query.From, err = parseHistoryDate(c.Query("from"), false); err != nil {
+		return nil, err
+	}
+
+	if query.To, err = parseHistoryDate(c.Query("to"), true); err != nil {
+		return nil, err
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		query.Cursor, err = DecodeHistoryCursor(cursor)
+		if err != nil {
+			return nil, service_errors.NewErrBadRequest("Invalid cursor")
+		}
+	}
+
+	return query, nil
+}
+
+func parseHistoryDate(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, service_errors.NewErrBadRequest("Invalid date: " + value)
+	}
+
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+
+	return &parsed, nil
+}
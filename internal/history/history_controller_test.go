@@ -0,0 +1,45 @@
+package history
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseHistoryQueryLimit(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected int
+	}{
+		{query: "", expected: defaultHistoryLimit},
+		{query: "?limit=0", expected: defaultHistoryLimit},
+		{query: "?limit=-5", expected: defaultHistoryLimit},
+		{query: "?limit=25", expected: 25},
+		{query: "?limit=100", expected: maxHistoryLimit},
+		{query: "?limit=1000000", expected: maxHistoryLimit},
+	}
+
+	for _, c := range cases {
+		app := fiber.New()
+		var limit int
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			query, err := parseHistoryQuery(ctx)
+			if err != nil {
+				return err
+			}
+
+			limit = query.Limit
+			return ctx.SendStatus(fiber.StatusOK)
+		})
+
+		res, err := app.Test(httptest.NewRequest("GET", "/"+c.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != fiber.StatusOK || limit != c.expected {
+			t.Errorf("%q: expected limit %d, got %d (status %d)", c.query, c.expected, limit, res.StatusCode)
+		}
+	}
+}
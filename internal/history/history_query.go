@@ -0,0 +1,101 @@
+package history
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+type HistorySort string
+
+const (
+	SortCreatedAt      HistorySort = "created_at"
+	SortDuration       HistorySort = "duration"
+	SortCharacterCount HistorySort = "characters"
+)
+
+var sortColumns = map[HistorySort]string{
+	SortCreatedAt:      "created_at",
+	SortDuration:       "duration_ms",
+	SortCharacterCount: "character_count",
+}
+
+type HistoryQuery struct {
+	Search     string
+	ModelId    string
+	Language   string
+	From       *time.Time
+	To         *time.Time
+	Sort       HistorySort
+	Descending bool
+	Page       int
+	Limit      int
+	Cursor     *HistoryCursor
+}
+
+type HistoryCursor struct {
+	Sort       HistorySort `json:"s"`
+	Descending bool        `json:"d,omitempty"`
+	Value      string      `json:"v"`
+	Id         string      `json:"id"`
+}
+
+// matches tells whether the cursor was issued for the same ordering, keyset
+// paging with another one would skip or repeat records
+func (cursor *HistoryCursor) matches(query *HistoryQuery) bool {
+	return cursor.Sort == query.Sort && cursor.Descending == query.Descending
+}
+
+func (sort HistorySort) Column() (string, bool) {
+	column, ok := sortColumns[sort]
+	return column, ok
+}
+
+func (sort HistorySort) cursorValue(record *HistoryRecord) string {
+	switch sort {
+	case SortDuration:
+		return strconv.Itoa(record.DurationMs)
+	case SortCharacterCount:
+		return strconv.Itoa(record.CharacterCount)
+	default:
+		return record.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func (sort HistorySort) parseCursorValue(value string) (interface{}, error) {
+	switch sort {
+	case SortDuration, SortCharacterCount:
+		return strconv.Atoi(value)
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+func EncodeHistoryCursor(cursor *HistoryCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeHistoryCursor(value string) (*HistoryCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	var cursor HistoryCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, errors.New("malformed cursor")
+	}
+
+	if _, ok := cursor.Sort.Column(); !ok {
+		return nil, errors.New("malformed cursor")
+	}
+
+	if _, err := cursor.Sort.parseCursorValue(cursor.Value); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,55 @@
+package history
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestHistoryCursorRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		cursor HistoryCursor
+	}{
+		{name: "created at", cursor: HistoryCursor{Sort: SortCreatedAt, Value: time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC).Format(time.RFC3339Nano), Id: "r1"}},
+		{name: "created at descending", cursor: HistoryCursor{Sort: SortCreatedAt, Descending: true, Value: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Format(time.RFC3339Nano), Id: "r2"}},
+		{name: "duration", cursor: HistoryCursor{Sort: SortDuration, Value: "1500", Id: "r3"}},
+		{name: "characters descending", cursor: HistoryCursor{Sort: SortCharacterCount, Descending: true, Value: "42", Id: "r4"}},
+	}
+
+	for _, c := range cases {
+		decoded, err := DecodeHistoryCursor(EncodeHistoryCursor(&c.cursor))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+
+		if *decoded != c.cursor {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.cursor, *decoded)
+		}
+	}
+}
+
+func TestDecodeHistoryCursorRejectsMalformed(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "%%%"},
+		{name: "not json", value: encode("cursor")},
+		{name: "missing id", value: encode(`{"s":"duration","v":"10"}`)},
+		{name: "unknown sort", value: encode(`{"s":"name","v":"a","id":"r1"}`)},
+		{name: "bad value", value: encode(`{"s":"duration","v":"long","id":"r1"}`)},
+		{name: "bad time", value: encode(`{"s":"created_at","v":"yesterday","id":"r1"}`)},
+	}
+
+	for _, c := range cases {
+		if _, err := DecodeHistoryCursor(c.value); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
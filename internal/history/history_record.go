@@ -2,17 +2,33 @@ package history
 
 import (
 	"time"
+	"vitaliiPsl/synthesizer/internal/subtitles"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type HistoryRecord struct {
-	Id        string    `gorm:"type:varchar(256);not null;primaryKey;"`
-	UserId    string    `gorm:"type:varchar(256);not null;index"`
-	Text      string    `gorm:"type:varchar(2056);not null"`
-	Language  string    `gorm:"type:varchar(256);"`
-	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	Id                   string             `gorm:"type:varchar(256);not null;primaryKey;"`
+	UserId               string             `gorm:"type:varchar(256);not null;index"`
+	Text                 string             `gorm:"type:text;not null"`
+	Language             string             `gorm:"type:varchar(256);"`
+	ModelId              string             `gorm:"type:varchar(256);index"`
+	ModelName            string             `gorm:"type:varchar(255);"`
+	ModelVersionId       string             `gorm:"type:varchar(256);"`
+	ModelVersion         int                `gorm:"not null;default:0"`
+	DurationMs           int                `gorm:"not null;default:0"`
+	SampleRate           int                `gorm:"not null;default:0"`
+	CharacterCount       int                `gorm:"not null;default:0"`
+	LatencyMs            int                `gorm:"not null;default:0"`
+	GlobalLexiconVersion int                `gorm:"not null;default:0"`
+	UserLexiconVersion   int                `gorm:"not null;default:0"`
+	Words                []subtitles.Timing `gorm:"type:jsonb;serializer:json"`
+	Sentences            []subtitles.Timing `gorm:"type:jsonb;serializer:json"`
+	AudioKey             string             `gorm:"type:varchar(512);"`
+	AudioContentType     string             `gorm:"type:varchar(64);"`
+	CreatedAt            time.Time          `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index"`
+	SearchVector         string             `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce(text, ''))) STORED;index:idx_history_records_search_vector,type:gin"`
 }
 
 func (record *HistoryRecord) BeforeCreate(tx *gorm.DB) (err error) {
@@ -1,31 +1,76 @@
 package history
 
-import "time"
+import (
+	"time"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
 
 type HistoryRecordDto struct {
-	Id        string    `json:"id"`
-	UserId    string    `json:"user_id"`
-	Text      string    `json:"text"`
-	Language  string    `json:"language"`
-	CreatedAt time.Time `json:"created_at"`
+	Id                   string             `json:"id"`
+	UserId               string             `json:"user_id"`
+	Text                 string             `json:"text"`
+	Language             string             `json:"language"`
+	ModelId              string             `json:"model_id"`
+	ModelName            string             `json:"model_name"`
+	ModelVersionId       string             `json:"model_version_id,omitempty"`
+	ModelVersion         int                `json:"model_version,omitempty"`
+	DurationMs           int                `json:"duration_ms"`
+	SampleRate           int                `json:"sample_rate"`
+	CharacterCount       int                `json:"character_count"`
+	LatencyMs            int                `json:"latency_ms"`
+	GlobalLexiconVersion int                `json:"global_lexicon_version"`
+	UserLexiconVersion   int                `json:"user_lexicon_version"`
+	Words                []subtitles.Timing `json:"-"`
+	Sentences            []subtitles.Timing `json:"-"`
+	AudioKey             string             `json:"-"`
+	AudioContentType     string             `json:"audio_content_type,omitempty"`
+	CreatedAt            time.Time          `json:"created_at"`
 }
 
 func ToHistoryRecordModel(dto *HistoryRecordDto) *HistoryRecord {
 	return &HistoryRecord{
-		Id:        dto.Id,
-		UserId:    dto.UserId,
-		Text:      dto.Text,
-		Language:  dto.Language,
-		CreatedAt: dto.CreatedAt,
+		Id:                   dto.Id,
+		UserId:               dto.UserId,
+		Text:                 dto.Text,
+		Language:             dto.Language,
+		ModelId:              dto.ModelId,
+		ModelName:            dto.ModelName,
+		ModelVersionId:       dto.ModelVersionId,
+		ModelVersion:         dto.ModelVersion,
+		DurationMs:           dto.DurationMs,
+		SampleRate:           dto.SampleRate,
+		CharacterCount:       dto.CharacterCount,
+		LatencyMs:            dto.LatencyMs,
+		GlobalLexiconVersion: dto.GlobalLexiconVersion,
+		UserLexiconVersion:   dto.UserLexiconVersion,
+		Words:                dto.Words,
+		Sentences:            dto.Sentences,
+		AudioKey:             dto.AudioKey,
+		AudioContentType:     dto.AudioContentType,
+		CreatedAt:            dto.CreatedAt,
 	}
 }
 
 func ToHistoryRecordDto(model *HistoryRecord) *HistoryRecordDto {
 	return &HistoryRecordDto{
-		Id:        model.Id,
-		UserId:    model.UserId,
-		Text:      model.Text,
-		Language:  model.Language,
-		CreatedAt: model.CreatedAt,
+		Id:                   model.Id,
+		UserId:               model.UserId,
+		Text:                 model.Text,
+		Language:             model.Language,
+		ModelId:              model.ModelId,
+		ModelName:            model.ModelName,
+		ModelVersionId:       model.ModelVersionId,
+		ModelVersion:         model.ModelVersion,
+		DurationMs:           model.DurationMs,
+		SampleRate:           model.SampleRate,
+		CharacterCount:       model.CharacterCount,
+		LatencyMs:            model.LatencyMs,
+		GlobalLexiconVersion: model.GlobalLexiconVersion,
+		UserLexiconVersion:   model.UserLexiconVersion,
+		Words:                model.Words,
+		Sentences:            model.Sentences,
+		AudioKey:             model.AudioKey,
+		AudioContentType:     model.AudioContentType,
+		CreatedAt:            model.CreatedAt,
 	}
 }
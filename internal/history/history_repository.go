@@ -1,13 +1,17 @@
 package history
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
 type HistoryRepository interface {
 	Save(record *HistoryRecord) error
-	FindByUserId(userId string, offset, limit int) ([]HistoryRecord, error)
-	CountByUserId(userId string) (int, error)
+	FindById(id, userId string) (*HistoryRecord, error)
+	FindByQuery(userId string, query *HistoryQuery, offset, limit int) ([]HistoryRecord, error)
+	FindAudioKeysByUserId(userId string) ([]string, error)
+	CountByQuery(userId string, query *HistoryQuery) (int, error)
 	DeleteByUserId(userId string) error
 	DeleteById(id, userId string) error
 }
@@ -25,19 +29,60 @@ func (r *HistoryRepositoryImpl) Save(record *HistoryRecord) error {
 	return result.Error
 }
 
-func (r *HistoryRepositoryImpl) FindByUserId(userId string, offset, limit int) ([]HistoryRecord, error) {
+func (r *HistoryRepositoryImpl) FindById(id, userId string) (*HistoryRecord, error) {
+	var record HistoryRecord
+
+	if err := r.db.First(&record, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (r *HistoryRepositoryImpl) FindByQuery(userId string, query *HistoryQuery, offset, limit int) ([]HistoryRecord, error) {
 	var records []HistoryRecord
 
-	result := r.db.Where("user_id = ?", userId).Offset(offset).Limit(limit).Order("created_at desc").Find(&records)
+	column, ok := query.Sort.Column()
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", query.Sort)
+	}
+
+	direction, comparison := "asc", ">"
+	if query.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	db := r.filter(userId, query)
+
+	if query.Cursor != nil {
+		value, err := query.Cursor.Sort.parseCursorValue(query.Cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, query.Cursor.Id)
+	}
+
+	result := db.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).Offset(offset).Limit(limit).Find(&records)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return records, nil
 }
 
-func (rep *HistoryRepositoryImpl) CountByUserId(userId string) (int, error) {
+func (r *HistoryRepositoryImpl) FindAudioKeysByUserId(userId string) ([]string, error) {
+	var keys []string
+
+	result := r.db.Model(&HistoryRecord{}).Where("user_id = ? AND audio_key <> ''", userId).Pluck("audio_key", &keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+func (r *HistoryRepositoryImpl) CountByQuery(userId string, query *HistoryQuery) (int, error) {
 	var count int64
-	result := rep.db.Model(&HistoryRecord{}).Where("user_id = ?", userId).Count(&count)
+	result := r.filter(userId, query).Count(&count)
 	if result.Error != nil {
 		return 0, result.Error
 	}
@@ -53,3 +98,29 @@ func (r *HistoryRepositoryImpl) DeleteById(id, userId string) error {
 	result := r.db.Delete(&HistoryRecord{}, "id = ? AND user_id = ?", id, userId)
 	return result.Error
 }
+
+func (r *HistoryRepositoryImpl) filter(userId string, query *HistoryQuery) *gorm.DB {
+	db := r.db.Model(&HistoryRecord{}).Where("user_id = ?", userId)
+
+	if query.Search != "" {
+		db = db.Where("search_vector @@ websearch_to_tsquery('simple', ?)", query.Search)
+	}
+
+	if query.ModelId != "" {
+		db = db.Where("model_id = ?", query.ModelId)
+	}
+
+	if query.Language != "" {
+		db = db.Where("language = ?", query.Language)
+	}
+
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+
+	return db
+}
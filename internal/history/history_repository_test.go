@@ -0,0 +1,77 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunRepository builds queries without a database and records them
+func dryRunRepository(t *testing.T) (*HistoryRepositoryImpl, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{}
+	err = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, fmt.Sprintf("%s %v", tx.Statement.SQL.String(), tx.Statement.Vars))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewHistoryRepository(db), &statements
+}
+
+func TestFindByQueryKeysetPaging(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    HistoryQuery
+		expected []string
+	}{
+		{
+			name:     "first page",
+			query:    HistoryQuery{Sort: SortCreatedAt},
+			expected: []string{"user_id = $1", "ORDER BY created_at asc, id asc LIMIT $2"},
+		},
+		{
+			name:     "ascending",
+			query:    HistoryQuery{Sort: SortDuration, Cursor: &HistoryCursor{Sort: SortDuration, Value: "1500", Id: "r1"}},
+			expected: []string{"(duration_ms, id) > ($2, $3)", "ORDER BY duration_ms asc, id asc", "[user-1 1500 r1"},
+		},
+		{
+			name:     "descending",
+			query:    HistoryQuery{Sort: SortCharacterCount, Descending: true, Cursor: &HistoryCursor{Sort: SortCharacterCount, Descending: true, Value: "42", Id: "r2"}},
+			expected: []string{"(character_count, id) < ($2, $3)", "ORDER BY character_count desc, id desc", "[user-1 42 r2"},
+		},
+		{
+			name:     "search",
+			query:    HistoryQuery{Sort: SortCreatedAt, Search: "hello world", Language: "en"},
+			expected: []string{"search_vector @@ websearch_to_tsquery('simple', $2)", "language = $3", "[user-1 hello world en"},
+		},
+	}
+
+	for _, c := range cases {
+		repository, statements := dryRunRepository(t)
+
+		if _, err := repository.FindByQuery("user-1", &c.query, 0, 11); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+
+		if len(*statements) != 1 {
+			t.Errorf("%s: expected one query, got %v", c.name, *statements)
+			continue
+		}
+
+		for _, fragment := range c.expected {
+			if !strings.Contains((*statements)[0], fragment) {
+				t.Errorf("%s: expected %q in %s", c.name, fragment, (*statements)[0])
+			}
+		}
+	}
+}
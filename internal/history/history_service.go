@@ -1,34 +1,56 @@
 package history
 
 import (
+	"errors"
+	"fmt"
 	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/storage"
+	"vitaliiPsl/synthesizer/internal/subtitles"
 	"vitaliiPsl/synthesizer/internal/users"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type HistoryService interface {
-	SaveHistoryRecord(dto *HistoryRecordDto) (*HistoryRecordDto, error)
-	GetHistoryRecordsByUserId(userDto *users.UserDto, page, limit int) (*PaginatedHistoryResponse, error)
+	SaveHistoryRecord(dto *HistoryRecordDto, audio *HistoryAudio) (*HistoryRecordDto, error)
+	GetHistoryRecordsByUserId(userDto *users.UserDto, query *HistoryQuery) (*PaginatedHistoryResponse, error)
+	GetHistoryRecordsByCursor(userDto *users.UserDto, query *HistoryQuery) (*CursorHistoryResponse, error)
+	GetHistoryRecordAudio(id, userId string) (*HistoryAudio, error)
+	GetHistoryRecordSubtitles(id, userId string, format subtitles.Format, granularity subtitles.Granularity) (string, error)
 	DeleteHistory(userId string) error
 	DeleteHistoryRecordById(id, userId string) error
 }
 
 type HistoryServiceImpl struct {
-	repository HistoryRepository
+	repository  HistoryRepository
+	blobStorage storage.BlobStorage
 }
 
-func NewHistoryService(repository HistoryRepository) *HistoryServiceImpl {
-	return &HistoryServiceImpl{repository: repository}
+func NewHistoryService(repository HistoryRepository, blobStorage storage.BlobStorage) *HistoryServiceImpl {
+	return &HistoryServiceImpl{repository: repository, blobStorage: blobStorage}
 }
 
-func (s *HistoryServiceImpl) SaveHistoryRecord(dto *HistoryRecordDto) (*HistoryRecordDto, error) {
+func (s *HistoryServiceImpl) SaveHistoryRecord(dto *HistoryRecordDto, audio *HistoryAudio) (*HistoryRecordDto, error) {
 	logger.Logger.Info("Saving history record...", "userId", dto.UserId)
 
 	historyRecord := ToHistoryRecordModel(dto)
 
+	if audio != nil {
+		historyRecord.AudioKey = fmt.Sprintf("history/%s/%s.%s", dto.UserId, uuid.NewString(), audio.Extension)
+		historyRecord.AudioContentType = audio.ContentType
+
+		if err := s.blobStorage.Put(historyRecord.AudioKey, audio.Data, audio.ContentType); err != nil {
+			logger.Logger.Error("Failed to store history audio", "userId", dto.UserId, "error", err)
+			return nil, service_errors.NewErrInternalServer("Failed to store history audio")
+		}
+	}
+
 	err := s.repository.Save(historyRecord)
 	if err != nil {
 		logger.Logger.Error("Failed to save history record", "userId", dto.UserId)
+		s.deleteAudio(historyRecord.AudioKey)
 		return nil, service_errors.NewErrInternalServer("Failed to save history record")
 	}
 
@@ -36,20 +58,22 @@ func (s *HistoryServiceImpl) SaveHistoryRecord(dto *HistoryRecordDto) (*HistoryR
 	return ToHistoryRecordDto(historyRecord), nil
 }
 
-func (s *HistoryServiceImpl) GetHistoryRecordsByUserId(userDto *users.UserDto, page, limit int) (*PaginatedHistoryResponse, error) {
-	logger.Logger.Info("Fetching history records...", "userId", userDto.Id, "page", page, "limit", limit)
+func (s *HistoryServiceImpl) GetHistoryRecordsByUserId(userDto *users.UserDto, query *HistoryQuery) (*PaginatedHistoryResponse, error) {
+	logger.Logger.Info("Fetching history records...", "userId", userDto.Id, "page", query.Page, "limit", query.Limit)
 
+	page, limit := query.Page, query.Limit
 	offset := (page - 1) * limit
 
-	records, err := s.repository.FindByUserId(userDto.Id, offset, limit)
+	records, err := s.repository.FindByQuery(userDto.Id, query, offset, limit)
 	if err != nil {
-		logger.Logger.Error("Failed to fetch history records", "userId", userDto.Id)
+		logger.Logger.Error("Failed to fetch history records", "userId", userDto.Id, "error", err)
 		return nil, service_errors.NewErrInternalServer("Failed to fetch history records")
 	}
 
-	totalRecords, err := s.repository.CountByUserId(userDto.Id)
+	totalRecords, err := s.repository.CountByQuery(userDto.Id, query)
 	if err != nil {
-		return nil, err
+		logger.Logger.Error("Failed to count history records", "userId", userDto.Id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to count history records")
 	}
 
 	totalPages := totalRecords / limit
@@ -74,15 +98,112 @@ func (s *HistoryServiceImpl) GetHistoryRecordsByUserId(userDto *users.UserDto, p
 	return response, nil
 }
 
+func (s *HistoryServiceImpl) GetHistoryRecordsByCursor(userDto *users.UserDto, query *HistoryQuery) (*CursorHistoryResponse, error) {
+	logger.Logger.Info("Fetching history records by cursor...", "userId", userDto.Id, "limit", query.Limit)
+
+	if query.Cursor != nil && !query.Cursor.matches(query) {
+		logger.Logger.Error("Cursor doesn't match sort order", "userId", userDto.Id, "sort", query.Sort, "descending", query.Descending)
+		return nil, service_errors.NewErrBadRequest("Cursor doesn't match the requested sort order")
+	}
+
+	records, err := s.repository.FindByQuery(userDto.Id, query, 0, query.Limit+1)
+	if err != nil {
+		logger.Logger.Error("Failed to fetch history records", "userId", userDto.Id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch history records")
+	}
+
+	hasMore := len(records) > query.Limit
+	if hasMore {
+		records = records[:query.Limit]
+	}
+
+	dtos := make([]HistoryRecordDto, len(records))
+	for i, record := range records {
+		dtos[i] = *ToHistoryRecordDto(&record)
+	}
+
+	response := &CursorHistoryResponse{Records: dtos, HasMore: hasMore}
+	if hasMore {
+		last := &records[len(records)-1]
+		response.NextCursor = EncodeHistoryCursor(&HistoryCursor{
+			Sort:       query.Sort,
+			Descending: query.Descending,
+			Value:      query.Sort.cursorValue(last),
+			Id:         last.Id,
+		})
+	}
+
+	logger.Logger.Info("Fetched history records by cursor", "userId", userDto.Id, "size", len(dtos))
+	return response, nil
+}
+
+func (s *HistoryServiceImpl) GetHistoryRecordAudio(id, userId string) (*HistoryAudio, error) {
+	logger.Logger.Info("Fetching history record audio...", "id", id, "userId", userId)
+
+	record, err := s.findRecord(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.AudioKey == "" {
+		logger.Logger.Error("History record has no audio", "id", id)
+		return nil, service_errors.NewErrNotFound("History record has no audio")
+	}
+
+	data, err := s.blobStorage.Get(record.AudioKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			logger.Logger.Error("History audio not found", "id", id, "key", record.AudioKey)
+			return nil, service_errors.NewErrNotFound("History audio not found")
+		}
+
+		logger.Logger.Error("Failed to fetch history audio", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch history audio")
+	}
+
+	logger.Logger.Info("Fetched history record audio.", "id", id, "size", len(data))
+	return &HistoryAudio{Data: data, ContentType: record.AudioContentType}, nil
+}
+
+func (s *HistoryServiceImpl) GetHistoryRecordSubtitles(id, userId string, format subtitles.Format, granularity subtitles.Granularity) (string, error) {
+	logger.Logger.Info("Fetching history record subtitles...", "id", id, "userId", userId, "format", format)
+
+	record, err := s.findRecord(id, userId)
+	if err != nil {
+		return "", err
+	}
+
+	// records saved before timings were tracked get a single cue for the whole text
+	sentences := record.Sentences
+	if len(sentences) == 0 {
+		sentences = []subtitles.Timing{{Text: record.Text, StartMs: 0, EndMs: record.DurationMs}}
+	}
+
+	rendered := subtitles.Render(format, granularity, sentences, record.Words)
+
+	logger.Logger.Info("Fetched history record subtitles.", "id", id, "size", len(rendered))
+	return rendered, nil
+}
+
 func (s *HistoryServiceImpl) DeleteHistory(userId string) error {
 	logger.Logger.Info("Deleting history...", "userId", userId)
 
-	err := s.repository.DeleteByUserId(userId)
+	keys, err := s.repository.FindAudioKeysByUserId(userId)
+	if err != nil {
+		logger.Logger.Error("Failed to fetch history audio keys", "userId", userId)
+		return service_errors.NewErrInternalServer("Failed to delete history")
+	}
+
+	err = s.repository.DeleteByUserId(userId)
 	if err != nil {
 		logger.Logger.Error("Failed to delete history", "userId", userId)
 		return service_errors.NewErrInternalServer("Failed to delete history")
 	}
 
+	for _, key := range keys {
+		s.deleteAudio(key)
+	}
+
 	logger.Logger.Info("Deleted history.", "userId", userId)
 	return nil
 }
@@ -90,12 +211,50 @@ func (s *HistoryServiceImpl) DeleteHistory(userId string) error {
 func (s *HistoryServiceImpl) DeleteHistoryRecordById(id, userId string) error {
 	logger.Logger.Info("Deleting history record...", "id", id, "userId", userId)
 
-	err := s.repository.DeleteById(id, userId)
+	record, err := s.repository.FindById(id, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("History record not found", "id", id, "userId", userId)
+			return service_errors.NewErrNotFound("History record not found")
+		}
+
+		logger.Logger.Error("Failed to fetch history record", "id", id, "userId", userId)
+		return service_errors.NewErrInternalServer("Failed to fetch history record")
+	}
+
+	err = s.repository.DeleteById(id, userId)
 	if err != nil {
 		logger.Logger.Error("Failed to delete history record", "id", id, "userId", userId)
 		return service_errors.NewErrInternalServer("Failed to delete history record")
 	}
 
+	s.deleteAudio(record.AudioKey)
+
 	logger.Logger.Info("Deleted history record.", "id", id, "userId", userId)
 	return nil
 }
+
+func (s *HistoryServiceImpl) deleteAudio(key string) {
+	if key == "" {
+		return
+	}
+
+	if err := s.blobStorage.Delete(key); err != nil {
+		logger.Logger.Error("Failed to delete history audio", "key", key, "error", err)
+	}
+}
+
+func (s *HistoryServiceImpl) findRecord(id, userId string) (*HistoryRecord, error) {
+	record, err := s.repository.FindById(id, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("History record not found", "id", id, "userId", userId)
+			return nil, service_errors.NewErrNotFound("History record not found")
+		}
+
+		logger.Logger.Error("Failed to fetch history record", "id", id, "userId", userId)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch history record")
+	}
+
+	return record, nil
+}
@@ -0,0 +1,181 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/storage"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+	"vitaliiPsl/synthesizer/internal/users"
+)
+
+// memoryHistoryRepository pages over records kept in the order the query asks for
+type memoryHistoryRepository struct {
+	HistoryRepository
+	records []HistoryRecord
+	queries []HistoryQuery
+}
+
+func (r *memoryHistoryRepository) Save(record *HistoryRecord) error {
+	record.Id = fmt.Sprintf("r%d", len(r.records))
+	r.records = append(r.records, *record)
+	return nil
+}
+
+type memoryBlobStorage struct {
+	storage.BlobStorage
+	blobs map[string][]byte
+}
+
+func (s *memoryBlobStorage) Put(key string, data []byte, contentType string) error {
+	s.blobs[key] = data
+	return nil
+}
+
+func (r *memoryHistoryRepository) FindByQuery(userId string, query *HistoryQuery, offset, limit int) ([]HistoryRecord, error) {
+	r.queries = append(r.queries, *query)
+
+	start := 0
+	if query.Cursor != nil {
+		for i, record := range r.records {
+			if record.Id == query.Cursor.Id {
+				start = i + 1
+			}
+		}
+	}
+
+	return r.records[start:min(start+limit, len(r.records))], nil
+}
+
+func TestGetHistoryRecordsByCursorPages(t *testing.T) {
+	repository := &memoryHistoryRepository{}
+	for i := 0; i < 5; i++ {
+		repository.records = append(repository.records, HistoryRecord{Id: fmt.Sprintf("r%d", i), DurationMs: 1000 - i})
+	}
+	service := NewHistoryService(repository, nil)
+	user := &users.UserDto{Id: "user-1"}
+
+	var seen []string
+	query := &HistoryQuery{Sort: SortDuration, Descending: true, Limit: 2}
+	for page := 0; ; page++ {
+		response, err := service.GetHistoryRecordsByCursor(user, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, record := range response.Records {
+			seen = append(seen, record.Id)
+		}
+		if !response.HasMore {
+			break
+		}
+
+		cursor, err := DecodeHistoryCursor(response.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cursor.Descending || cursor.Sort != SortDuration {
+			t.Errorf("page %d: cursor lost the ordering: %+v", page, cursor)
+		}
+		query.Cursor = cursor
+	}
+
+	if fmt.Sprint(seen) != "[r0 r1 r2 r3 r4]" {
+		t.Errorf("expected every record once, got %v", seen)
+	}
+}
+
+func TestGetHistoryRecordsByCursorRejectsOtherOrdering(t *testing.T) {
+	cursor := &HistoryCursor{Sort: SortDuration, Descending: true, Value: "900", Id: "r1"}
+
+	cases := []struct {
+		name  string
+		query HistoryQuery
+	}{
+		{name: "other sort", query: HistoryQuery{Sort: SortCreatedAt, Descending: true}},
+		{name: "other direction", query: HistoryQuery{Sort: SortDuration}},
+	}
+
+	for _, c := range cases {
+		repository := &memoryHistoryRepository{}
+		service := NewHistoryService(repository, nil)
+		c.query.Cursor = cursor
+		c.query.Limit = 10
+
+		_, err := service.GetHistoryRecordsByCursor(&users.UserDto{Id: "user-1"}, &c.query)
+
+		var badRequest *service_errors.ErrBadRequest
+		if !errors.As(err, &badRequest) {
+			t.Errorf("%s: expected a bad request, got %v", c.name, err)
+		}
+		if len(repository.queries) != 0 {
+			t.Errorf("%s: mismatched cursor should not reach the repository", c.name)
+		}
+	}
+}
+
+func TestSaveHistoryRecordKeepsSynthesisDetails(t *testing.T) {
+	repository := &memoryHistoryRepository{}
+	blobs := &memoryBlobStorage{blobs: map[string][]byte{}}
+	service := NewHistoryService(repository, blobs)
+
+	dto := &HistoryRecordDto{
+		UserId:               "user-1",
+		Text:                 "Hello there",
+		Language:             "en",
+		ModelId:              "model-1",
+		ModelName:            "anna",
+		ModelVersionId:       "v2",
+		ModelVersion:         2,
+		DurationMs:           1250,
+		SampleRate:           22050,
+		CharacterCount:       11,
+		LatencyMs:            340,
+		GlobalLexiconVersion: 3,
+		UserLexiconVersion:   1,
+		Words:                []subtitles.Timing{{Text: "Hello", StartMs: 0, EndMs: 500}},
+	}
+
+	saved, err := service.SaveHistoryRecord(dto, &HistoryAudio{Data: []byte("wav"), ContentType: "audio/wav", Extension: "wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repository.records) != 1 {
+		t.Fatalf("expected one saved record, got %d", len(repository.records))
+	}
+	record := repository.records[0]
+
+	expected := HistoryRecord{
+		Id:                   "r0",
+		UserId:               "user-1",
+		Text:                 "Hello there",
+		Language:             "en",
+		ModelId:              "model-1",
+		ModelName:            "anna",
+		ModelVersionId:       "v2",
+		ModelVersion:         2,
+		DurationMs:           1250,
+		SampleRate:           22050,
+		CharacterCount:       11,
+		LatencyMs:            340,
+		GlobalLexiconVersion: 3,
+		UserLexiconVersion:   1,
+		Words:                dto.Words,
+		AudioKey:             record.AudioKey,
+		AudioContentType:     "audio/wav",
+	}
+	if !reflect.DeepEqual(record, expected) {
+		t.Errorf("expected %+v, got %+v", expected, record)
+	}
+
+	if _, ok := blobs.blobs[record.AudioKey]; !ok || !strings.HasPrefix(record.AudioKey, "history/user-1/") {
+		t.Errorf("expected the audio under the user's prefix, got %q", record.AudioKey)
+	}
+	if saved.Id != "r0" || saved.LatencyMs != 340 || saved.DurationMs != 1250 || saved.Language != "en" || saved.ModelId != "model-1" {
+		t.Errorf("expected the saved record back, got %+v", saved)
+	}
+}
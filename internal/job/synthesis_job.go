@@ -0,0 +1,40 @@
+package job
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+type SynthesisJob struct {
+	Id              string                `gorm:"type:varchar(256);primaryKey;"`
+	UserId          string                `gorm:"type:varchar(256);not null;index"`
+	ModelId         string                `gorm:"type:varchar(256);not null"`
+	Text            string                `gorm:"type:text;not null"`
+	TextType        string                `gorm:"type:varchar(16);"`
+	Voice           requests.VoiceOptions `gorm:"embedded;embeddedPrefix:voice_"`
+	Format          string                `gorm:"type:varchar(32);not null"`
+	Status          JobStatus             `gorm:"type:varchar(32);not null;index"`
+	Progress        int                   `gorm:"not null;default:0"`
+	Attempts        int                   `gorm:"not null;default:0"`
+	Error           string                `gorm:"type:varchar(1024);"`
+	HistoryRecordId string                `gorm:"type:varchar(256);"`
+	CreatedAt       time.Time             `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt       time.Time             `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	StartedAt       *time.Time            `gorm:"type:timestamp;"`
+	FinishedAt      *time.Time            `gorm:"type:timestamp;"`
+}
+
+func (job *SynthesisJob) BeforeCreate(tx *gorm.DB) (err error) {
+	job.Id = uuid.NewString()
+	return
+}
+
+type SynthesisJobResult struct {
+	JobId       string    `gorm:"type:varchar(256);primaryKey;"`
+	ContentType string    `gorm:"type:varchar(64);not null"`
+	Data        []byte    `gorm:"type:bytea;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
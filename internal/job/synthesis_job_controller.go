@@ -0,0 +1,125 @@
+package job
+
+import (
+	"fmt"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/users"
+	"vitaliiPsl/synthesizer/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobController struct {
+	service           JobService
+	validationService *validation.ValidationService
+}
+
+func NewJobController(jobService JobService, validationService *validation.ValidationService) *JobController {
+	return &JobController{service: jobService, validationService: validationService}
+}
+
+func (controller *JobController) HandleCreateJob(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling create synthesis job request...")
+
+	tempUser := c.Locals("user")
+	if tempUser == nil {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	userDto, ok := tempUser.(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.SynthesisJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse synthesis job request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateSynthesisJobRequest(&req); err != nil {
+		logger.Logger.Error("Synthesis job request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.service.EnqueueJob(&req, userDto.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to handle create synthesis job request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled create synthesis job request.", "id", response.Id)
+	return c.Status(fiber.StatusAccepted).JSON(response)
+}
+
+func (controller *JobController) HandleFetchJob(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling synthesis job request...")
+
+	tempUser := c.Locals("user")
+	if tempUser == nil {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	userDto, ok := tempUser.(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	jobId := c.Params("id")
+	if jobId == "" {
+		logger.Logger.Error("Job Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Job Id is required",
+		})
+	}
+
+	response, err := controller.service.GetJob(jobId, userDto.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to handle synthesis job request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled synthesis job request.", "id", jobId)
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *JobController) HandleFetchJobAudio(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling synthesis job audio request...")
+
+	tempUser := c.Locals("user")
+	if tempUser == nil {
+		logger.Logger.Error("No user found in context.")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	userDto, ok := tempUser.(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	jobId := c.Params("id")
+	if jobId == "" {
+		logger.Logger.Error("Job Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Job Id is required",
+		})
+	}
+
+	encoded, err := controller.service.GetJobAudio(jobId, userDto.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to handle synthesis job audio request", "message", err.Error())
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, encoded.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s.%s\"", jobId, encoded.Format.Extension()))
+
+	logger.Logger.Info("Handled synthesis job audio request.", "id", jobId)
+	return c.Status(fiber.StatusOK).Send(encoded.Data)
+}
@@ -0,0 +1,33 @@
+package job
+
+import "time"
+
+type SynthesisJobDto struct {
+	Id              string     `json:"id"`
+	UserId          string     `json:"user_id"`
+	ModelId         string     `json:"model_id"`
+	Format          string     `json:"format"`
+	Status          JobStatus  `json:"status"`
+	Progress        int        `json:"progress"`
+	Error           string     `json:"error,omitempty"`
+	HistoryRecordId string     `json:"history_record_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+func ToSynthesisJobDto(job *SynthesisJob) *SynthesisJobDto {
+	return &SynthesisJobDto{
+		Id:              job.Id,
+		UserId:          job.UserId,
+		ModelId:         job.ModelId,
+		Format:          job.Format,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		Error:           job.Error,
+		HistoryRecordId: job.HistoryRecordId,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+}
@@ -0,0 +1,141 @@
+package job
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository interface {
+	Save(job *SynthesisJob) error
+	FindByIdAndUserId(id, userId string) (*SynthesisJob, error)
+	ClaimNext() (*SynthesisJob, error)
+	UpdateProgress(id string, progress int) error
+	UpdateHistoryRecord(id, historyRecordId string) error
+	UpdateStatus(job *SynthesisJob) error
+	Complete(job *SynthesisJob, result *SynthesisJobResult) error
+	RequeueStale(staleBefore time.Time, maxAttempts int) (int, int, error)
+	FindResultByJobId(jobId string) (*SynthesisJobResult, error)
+}
+
+type JobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepositoryImpl {
+	return &JobRepositoryImpl{db: db}
+}
+
+func (r *JobRepositoryImpl) Save(job *SynthesisJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *JobRepositoryImpl) FindByIdAndUserId(id, userId string) (*SynthesisJob, error) {
+	var job SynthesisJob
+
+	if err := r.db.Omit("text").First(&job, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *JobRepositoryImpl) ClaimNext() (*SynthesisJob, error) {
+	var job SynthesisJob
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", StatusQueued).
+			Order("created_at").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		job.Status = StatusRunning
+		job.Attempts++
+		job.StartedAt = &now
+
+		return tx.Save(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *JobRepositoryImpl) UpdateProgress(id string, progress int) error {
+	return r.db.Model(&SynthesisJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"progress":   progress,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+func (r *JobRepositoryImpl) UpdateHistoryRecord(id, historyRecordId string) error {
+	return r.db.Model(&SynthesisJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"history_record_id": historyRecordId,
+		"updated_at":        time.Now(),
+	}).Error
+}
+
+func (r *JobRepositoryImpl) UpdateStatus(job *SynthesisJob) error {
+	return r.db.Model(&SynthesisJob{}).Where("id = ?", job.Id).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"progress":    job.Progress,
+		"attempts":    job.Attempts,
+		"finished_at": job.FinishedAt,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+func (r *JobRepositoryImpl) Complete(job *SynthesisJob, result *SynthesisJobResult) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(result).Error; err != nil {
+			return err
+		}
+
+		return tx.Save(job).Error
+	})
+}
+
+// RequeueStale returns stale running jobs to the queue, or fails them when
+// they have used up their attempts. It reports how many of each there were.
+func (r *JobRepositoryImpl) RequeueStale(staleBefore time.Time, maxAttempts int) (int, int, error) {
+	now := time.Now()
+
+	failed := r.db.Model(&SynthesisJob{}).
+		Where("status = ? AND updated_at < ? AND attempts >= ?", StatusRunning, staleBefore, maxAttempts).
+		Updates(map[string]interface{}{
+			"status":      StatusFailed,
+			"progress":    0,
+			"error":       "Synthesis job stalled too many times",
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	if failed.Error != nil {
+		return 0, 0, failed.Error
+	}
+
+	requeued := r.db.Model(&SynthesisJob{}).
+		Where("status = ? AND updated_at < ? AND attempts < ?", StatusRunning, staleBefore, maxAttempts).
+		Updates(map[string]interface{}{
+			"status":     StatusQueued,
+			"progress":   0,
+			"updated_at": now,
+		})
+
+	return int(requeued.RowsAffected), int(failed.RowsAffected), requeued.Error
+}
+
+func (r *JobRepositoryImpl) FindResultByJobId(jobId string) (*SynthesisJobResult, error) {
+	var result SynthesisJobResult
+
+	if err := r.db.First(&result, "job_id = ?", jobId).Error; err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,54 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunRepository builds statements without a database and records them
+func dryRunRepository(t *testing.T) (*JobRepositoryImpl, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{}
+	err = db.Callback().Update().After("gorm:update").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, fmt.Sprintf("%s %v", tx.Statement.SQL.String(), tx.Statement.Vars))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewJobRepository(db), &statements
+}
+
+func TestRequeueStaleRespectsMaxAttempts(t *testing.T) {
+	repository, statements := dryRunRepository(t)
+
+	if _, _, err := repository.RequeueStale(time.Now(), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*statements) != 2 {
+		t.Fatalf("expected two updates, got %v", *statements)
+	}
+
+	failed, requeued := (*statements)[0], (*statements)[1]
+	if !strings.Contains(failed, "attempts >= $") || !strings.Contains(failed, `"status"=$`) || !strings.Contains(failed, string(StatusFailed)) {
+		t.Errorf("expected exhausted jobs to be failed, got %s", failed)
+	}
+	if !strings.Contains(requeued, "attempts < $") || !strings.Contains(requeued, string(StatusQueued)) {
+		t.Errorf("expected jobs with attempts left to be requeued, got %s", requeued)
+	}
+	for _, statement := range *statements {
+		if !strings.HasSuffix(statement, " 3]") {
+			t.Errorf("expected the attempt limit as the last argument, got %s", statement)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/synthesis"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxAttempts = 3
+	maxJobErrorLength  = 1024
+)
+
+type JobService interface {
+	EnqueueJob(req *requests.SynthesisJobRequest, userId string) (*SynthesisJobDto, error)
+	GetJob(id, userId string) (*SynthesisJobDto, error)
+	GetJobAudio(id, userId string) (*audio.EncodedAudio, error)
+	ProcessNextJob(ctx context.Context) (bool, error)
+	RequeueStaleJobs(staleAfter time.Duration) error
+	Notifications() <-chan struct{}
+}
+
+type JobServiceImpl struct {
+	repository       JobRepository
+	synthesisService synthesis.SynthesisService
+	historyService   history.HistoryService
+	audioService     audio.AudioService
+	maxAttempts      int
+	notifications    chan struct{}
+}
+
+func NewJobService(repository JobRepository, synthesisService synthesis.SynthesisService, historyService history.HistoryService, audioService audio.AudioService) *JobServiceImpl {
+	maxAttempts, err := strconv.Atoi(os.Getenv("SYNTHESIS_JOB_MAX_ATTEMPTS"))
+	if err != nil || maxAttempts < 1 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &JobServiceImpl{
+		repository:       repository,
+		synthesisService: synthesisService,
+		historyService:   historyService,
+		audioService:     audioService,
+		maxAttempts:      maxAttempts,
+		notifications:    make(chan struct{}, 1),
+	}
+}
+
+func (s *JobServiceImpl) EnqueueJob(req *requests.SynthesisJobRequest, userId string) (*SynthesisJobDto, error) {
+	logger.Logger.Info("Enqueuing synthesis job...", "userId", userId, "modelId", req.ModelId)
+
+	format := req.Format
+	if format == "" {
+		format = string(audio.FormatWav)
+	}
+
+	if !s.audioService.Supports(audio.AudioFormat(format)) {
+		logger.Logger.Error("Unsupported audio format", "format", format)
+		return nil, service_errors.NewErrBadRequest("Unsupported audio format: " + format)
+	}
+
+	if req.TextType == "ssml" || (req.TextType == "" && synthesis.IsSsml(req.Text)) {
+		if err := synthesis.ValidateSsml(req.Text); err != nil {
+			logger.Logger.Error("Invalid SSML", "userId", userId, "error", err)
+			return nil, service_errors.NewErrBadRequest(err.Error())
+		}
+	}
+
+	job := &SynthesisJob{
+		UserId:   userId,
+		ModelId:  req.ModelId,
+		Text:     req.Text,
+		TextType: req.TextType,
+		Voice:    req.VoiceOptions,
+		Format:   format,
+		Status:   StatusQueued,
+	}
+
+	if err := s.repository.Save(job); err != nil {
+		logger.Logger.Error("Failed to save synthesis job", "userId", userId, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to save synthesis job")
+	}
+
+	s.notify()
+
+	logger.Logger.Info("Enqueued synthesis job.", "id", job.Id, "userId", userId)
+	return ToSynthesisJobDto(job), nil
+}
+
+func (s *JobServiceImpl) GetJob(id, userId string) (*SynthesisJobDto, error) {
+	logger.Logger.Info("Fetching synthesis job...", "id", id, "userId", userId)
+
+	job, err := s.findJob(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Fetched synthesis job.", "id", id, "status", job.Status)
+	return ToSynthesisJobDto(job), nil
+}
+
+func (s *JobServiceImpl) GetJobAudio(id, userId string) (*audio.EncodedAudio, error) {
+	logger.Logger.Info("Fetching synthesis job audio...", "id", id, "userId", userId)
+
+	job, err := s.findJob(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != StatusCompleted {
+		logger.Logger.Error("Synthesis job is not completed", "id", id, "status", job.Status)
+		return nil, service_errors.NewErrBadRequest("Synthesis job is not completed")
+	}
+
+	result, err := s.repository.FindResultByJobId(job.Id)
+	if err != nil {
+		logger.Logger.Error("Failed to fetch synthesis job result", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch synthesis job result")
+	}
+
+	logger.Logger.Info("Fetched synthesis job audio.", "id", id, "size", len(result.Data))
+	return &audio.EncodedAudio{Data: result.Data, Format: audio.AudioFormat(job.Format), ContentType: result.ContentType}, nil
+}
+
+func (s *JobServiceImpl) ProcessNextJob(ctx context.Context) (bool, error) {
+	job, err := s.repository.ClaimNext()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		logger.Logger.Error("Failed to claim synthesis job", "error", err)
+		return false, err
+	}
+
+	logger.Logger.Info("Processing synthesis job...", "id", job.Id, "attempt", job.Attempts)
+
+	if err := s.processJob(ctx, job); err != nil {
+		s.handleJobFailure(ctx, job, err)
+		return true, nil
+	}
+
+	logger.Logger.Info("Processed synthesis job.", "id", job.Id)
+	return true, nil
+}
+
+func (s *JobServiceImpl) RequeueStaleJobs(staleAfter time.Duration) error {
+	count, failed, err := s.repository.RequeueStale(time.Now().Add(-staleAfter), s.maxAttempts)
+	if err != nil {
+		logger.Logger.Error("Failed to requeue stale synthesis jobs", "error", err)
+		return err
+	}
+
+	if failed > 0 {
+		logger.Logger.Error("Failed stale synthesis jobs out of attempts", "count", failed)
+	}
+
+	if count > 0 {
+		logger.Logger.Info("Requeued stale synthesis jobs.", "count", count)
+		s.notify()
+	}
+
+	return nil
+}
+
+func (s *JobServiceImpl) Notifications() <-chan struct{} {
+	return s.notifications
+}
+
+func (s *JobServiceImpl) processJob(ctx context.Context, job *SynthesisJob) error {
+	samples, samplingRate, err := s.synthesizeJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := s.audioService.Encode(samples, samplingRate, audio.AudioFormat(job.Format))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	job.Status = StatusCompleted
+	job.Progress = 100
+	job.Error = ""
+	job.FinishedAt = &now
+
+	result := &SynthesisJobResult{JobId: job.Id, ContentType: encoded.ContentType, Data: encoded.Data}
+	if err := s.repository.Complete(job, result); err != nil {
+		logger.Logger.Error("Failed to save synthesis job result", "id", job.Id, "error", err)
+		return service_errors.NewErrInternalServer("Failed to save synthesis job result")
+	}
+
+	return nil
+}
+
+// synthesizeJob saves the history record only once per job. A retry after the
+// synthesis succeeded reuses the recorded audio instead of synthesizing again.
+func (s *JobServiceImpl) synthesizeJob(ctx context.Context, job *SynthesisJob) ([]float32, int, error) {
+	if job.HistoryRecordId != "" {
+		samples, samplingRate, err := s.recordedAudio(job)
+		if err == nil {
+			return samples, samplingRate, nil
+		}
+
+		var notFound *service_errors.ErrNotFound
+		if !errors.As(err, &notFound) {
+			return nil, 0, err
+		}
+
+		// the user deleted the record in the meantime
+		logger.Logger.Info("Synthesis job history record is gone. Synthesizing again", "id", job.Id, "recordId", job.HistoryRecordId)
+		job.HistoryRecordId = ""
+	}
+
+	req := &requests.SynthesisRequest{Text: job.Text, TextType: job.TextType, ModelId: job.ModelId, VoiceOptions: job.Voice}
+
+	totalChars := utf8.RuneCountInString(job.Text)
+	processedChars := 0
+	samplingRate := 0
+	var samples []float32
+
+	record, err := s.synthesisService.HandleStreamingSynthesisRequest(ctx, req, job.UserId, func(chunk *synthesis.SynthesisChunk) error {
+		if samplingRate == 0 {
+			samplingRate = chunk.SamplingRate
+		} else if samplingRate != chunk.SamplingRate {
+			return fmt.Errorf("model changed sampling rate from %d to %d", samplingRate, chunk.SamplingRate)
+		}
+
+		samples = append(samples, chunk.Samples...)
+		processedChars += utf8.RuneCountInString(chunk.Text)
+
+		return s.repository.UpdateProgress(job.Id, min(99, processedChars*100/max(totalChars, 1)))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	job.HistoryRecordId = record.Id
+	if err := s.repository.UpdateHistoryRecord(job.Id, record.Id); err != nil {
+		logger.Logger.Error("Failed to link synthesis job to history record", "id", job.Id, "recordId", record.Id, "error", err)
+	}
+
+	return samples, samplingRate, nil
+}
+
+func (s *JobServiceImpl) recordedAudio(job *SynthesisJob) ([]float32, int, error) {
+	recorded, err := s.historyService.GetHistoryRecordAudio(job.HistoryRecordId, job.UserId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	decoded, err := audio.DecodeWav(recorded.Data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logger.Logger.Info("Reusing synthesis job history record.", "id", job.Id, "recordId", job.HistoryRecordId)
+	return decoded.Samples, decoded.SamplingRate, nil
+}
+
+func (s *JobServiceImpl) handleJobFailure(ctx context.Context, job *SynthesisJob, err error) {
+	job.Progress = 0
+
+	if ctx.Err() != nil {
+		// interrupted by shutdown, so the attempt doesn't count
+		logger.Logger.Info("Synthesis job interrupted. Returning it to the queue", "id", job.Id)
+		job.Attempts--
+		job.Status = StatusQueued
+		s.saveFailedJob(job)
+		return
+	}
+
+	logger.Logger.Error("Failed to process synthesis job", "id", job.Id, "attempt", job.Attempts, "error", err)
+
+	job.Error = truncateJobError(err.Error())
+
+	if isPermanentFailure(err) || job.Attempts >= s.maxAttempts {
+		now := time.Now()
+		job.Status = StatusFailed
+		job.FinishedAt = &now
+	} else {
+		job.Status = StatusQueued
+	}
+
+	if !s.saveFailedJob(job) {
+		return
+	}
+
+	if job.Status == StatusQueued {
+		s.notify()
+	}
+}
+
+// falls back to a status-only update so a job never gets stuck as running
+// just because its error message couldn't be stored
+func (s *JobServiceImpl) saveFailedJob(job *SynthesisJob) bool {
+	err := s.repository.Save(job)
+	if err == nil {
+		return true
+	}
+
+	logger.Logger.Error("Failed to update failed synthesis job", "id", job.Id, "error", err)
+
+	if err := s.repository.UpdateStatus(job); err != nil {
+		logger.Logger.Error("Failed to update failed synthesis job status", "id", job.Id, "error", err)
+		return false
+	}
+
+	return true
+}
+
+func (s *JobServiceImpl) findJob(id, userId string) (*SynthesisJob, error) {
+	job, err := s.repository.FindByIdAndUserId(id, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Synthesis job not found", "id", id, "userId", userId)
+			return nil, service_errors.NewErrNotFound("Synthesis job not found")
+		}
+
+		logger.Logger.Error("Failed to fetch synthesis job", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch synthesis job")
+	}
+
+	return job, nil
+}
+
+func (s *JobServiceImpl) notify() {
+	select {
+	case s.notifications <- struct{}{}:
+	default:
+	}
+}
+
+func truncateJobError(message string) string {
+	if utf8.RuneCountInString(message) <= maxJobErrorLength {
+		return message
+	}
+
+	runes := []rune(message)
+	return string(runes[:maxJobErrorLength-3]) + "..."
+}
+
+func isPermanentFailure(err error) bool {
+	switch err.(type) {
+	case *service_errors.ErrNotFound, *service_errors.ErrBadRequest, *service_errors.ErrGone:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/synthesis"
+)
+
+type failingSaveRepository struct {
+	JobRepository
+	saved   []SynthesisJob
+	updated []SynthesisJob
+}
+
+func (r *failingSaveRepository) Save(job *SynthesisJob) error {
+	if len(job.Error) > maxJobErrorLength {
+		return errors.New("value too long for type character varying(1024)")
+	}
+
+	r.saved = append(r.saved, *job)
+	return errors.New("connection reset")
+}
+
+func (r *failingSaveRepository) UpdateStatus(job *SynthesisJob) error {
+	r.updated = append(r.updated, *job)
+	return nil
+}
+
+func TestTruncateJobError(t *testing.T) {
+	short := "model not found"
+	if truncateJobError(short) != short {
+		t.Errorf("short message changed")
+	}
+
+	long := strings.Repeat("ї", maxJobErrorLength+10)
+	truncated := truncateJobError(long)
+	if utf8.RuneCountInString(truncated) != maxJobErrorLength || !strings.HasSuffix(truncated, "...") {
+		t.Errorf("unexpected truncation to %d runes", utf8.RuneCountInString(truncated))
+	}
+	if !utf8.ValidString(truncated) {
+		t.Error("truncation produced invalid utf-8")
+	}
+}
+
+func TestHandleJobFailureMarksFailedWhenSaveFails(t *testing.T) {
+	repository := &failingSaveRepository{}
+	service := &JobServiceImpl{repository: repository, maxAttempts: 3, notifications: make(chan struct{}, 1)}
+	job := &SynthesisJob{Id: "job-1", Status: StatusRunning, Attempts: 1}
+
+	service.handleJobFailure(context.Background(), job, service_errors.NewErrBadRequest(strings.Repeat("x", 4000)))
+
+	if len(repository.updated) != 1 {
+		t.Fatalf("expected status fallback, got %d updates", len(repository.updated))
+	}
+	if status := repository.updated[0].Status; status != StatusFailed {
+		t.Errorf("expected failed status, got %s", status)
+	}
+	if repository.updated[0].FinishedAt == nil {
+		t.Error("expected finished time")
+	}
+}
+
+func TestHandleJobFailureRequeuesOnShutdown(t *testing.T) {
+	repository := &failingSaveRepository{}
+	service := &JobServiceImpl{repository: repository, maxAttempts: 1, notifications: make(chan struct{}, 1)}
+	job := &SynthesisJob{Id: "job-1", Status: StatusRunning, Attempts: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	service.handleJobFailure(ctx, job, ctx.Err())
+
+	if job.Status != StatusQueued || job.Attempts != 0 || job.Error != "" {
+		t.Errorf("expected interrupted job to be requeued untouched, got %+v", job)
+	}
+}
+
+type recordingJobRepository struct {
+	JobRepository
+	linked    string
+	completed *SynthesisJobResult
+}
+
+func (r *recordingJobRepository) UpdateProgress(id string, progress int) error {
+	return nil
+}
+
+func (r *recordingJobRepository) UpdateHistoryRecord(id, historyRecordId string) error {
+	r.linked = historyRecordId
+	return nil
+}
+
+func (r *recordingJobRepository) Complete(job *SynthesisJob, result *SynthesisJobResult) error {
+	r.completed = result
+	return nil
+}
+
+// countingSynthesisService streams one chunk and records it as history
+type countingSynthesisService struct {
+	synthesis.SynthesisService
+	calls   int
+	history *recordingHistoryService
+}
+
+func (s *countingSynthesisService) HandleStreamingSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string, onChunk func(*synthesis.SynthesisChunk) error) (*history.HistoryRecordDto, error) {
+	s.calls++
+
+	samples := []float32{0.5, -0.5, 0.25, 0}
+	if err := onChunk(&synthesis.SynthesisChunk{Text: req.Text, Samples: samples, SamplingRate: 8000}); err != nil {
+		return nil, err
+	}
+
+	data, _ := audio.NewWavEncoder().Encode(samples, 8000)
+	return s.history.SaveHistoryRecord(&history.HistoryRecordDto{UserId: userId}, &history.HistoryAudio{Data: data})
+}
+
+type recordingHistoryService struct {
+	history.HistoryService
+	records map[string]*history.HistoryAudio
+}
+
+func (s *recordingHistoryService) SaveHistoryRecord(dto *history.HistoryRecordDto, historyAudio *history.HistoryAudio) (*history.HistoryRecordDto, error) {
+	dto.Id = fmt.Sprintf("record-%d", len(s.records)+1)
+	s.records[dto.Id] = historyAudio
+	return dto, nil
+}
+
+func (s *recordingHistoryService) GetHistoryRecordAudio(id, userId string) (*history.HistoryAudio, error) {
+	recorded, ok := s.records[id]
+	if !ok {
+		return nil, service_errors.NewErrNotFound("History record not found")
+	}
+	return recorded, nil
+}
+
+// flakyAudioService fails the first encoding
+type flakyAudioService struct {
+	audio.AudioService
+	failures int
+	encoded  int
+}
+
+func (s *flakyAudioService) Encode(samples []float32, samplingRate int, format audio.AudioFormat) (*audio.EncodedAudio, error) {
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("encoder crashed")
+	}
+
+	s.encoded = len(samples)
+	return &audio.EncodedAudio{Format: format}, nil
+}
+
+func TestRetrySavesHistoryOnce(t *testing.T) {
+	repository := &recordingJobRepository{}
+	historyService := &recordingHistoryService{records: map[string]*history.HistoryAudio{}}
+	synthesisService := &countingSynthesisService{history: historyService}
+	audioService := &flakyAudioService{failures: 1}
+	service := &JobServiceImpl{repository: repository, synthesisService: synthesisService, historyService: historyService, audioService: audioService}
+	job := &SynthesisJob{Id: "job-1", UserId: "user-1", Text: "hello", Format: "wav"}
+
+	if err := service.processJob(context.Background(), job); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if repository.linked != "record-1" || job.HistoryRecordId != "record-1" {
+		t.Fatalf("expected the job to be linked to its history record, got %q", repository.linked)
+	}
+
+	if err := service.processJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	if synthesisService.calls != 1 || len(historyService.records) != 1 {
+		t.Errorf("expected one synthesis and one history record, got %d and %d", synthesisService.calls, len(historyService.records))
+	}
+	if audioService.encoded != 4 || repository.completed == nil || job.Status != StatusCompleted {
+		t.Errorf("expected the retry to complete with the recorded audio, encoded %d samples", audioService.encoded)
+	}
+
+	// a record deleted in the meantime is synthesized again
+	delete(historyService.records, "record-1")
+	job.Status = StatusRunning
+	if err := service.processJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if synthesisService.calls != 2 || job.HistoryRecordId != "record-1" {
+		t.Errorf("expected a fresh synthesis, got %d calls and record %q", synthesisService.calls, job.HistoryRecordId)
+	}
+}
@@ -0,0 +1,17 @@
+package job
+
+type JobStatus string
+
+const (
+	// job is waiting in the queue for a free worker
+	StatusQueued JobStatus = "Queued"
+
+	// job was claimed by a worker and is being synthesized
+	StatusRunning JobStatus = "Running"
+
+	// job finished and its audio is available
+	StatusCompleted JobStatus = "Completed"
+
+	// job failed permanently or ran out of attempts
+	StatusFailed JobStatus = "Failed"
+)
@@ -0,0 +1,104 @@
+package job
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"vitaliiPsl/synthesizer/internal/logger"
+)
+
+const (
+	defaultWorkerCount  = 2
+	defaultPollInterval = 5 * time.Second
+	defaultStaleAfter   = 5 * time.Minute
+)
+
+type JobWorkerPool struct {
+	service      JobService
+	workers      int
+	pollInterval time.Duration
+	staleAfter   time.Duration
+	running      sync.WaitGroup
+}
+
+func NewJobWorkerPool(service JobService) *JobWorkerPool {
+	workers, err := strconv.Atoi(os.Getenv("SYNTHESIS_JOB_WORKERS"))
+	if err != nil || workers < 1 {
+		workers = defaultWorkerCount
+	}
+
+	staleAfter := defaultStaleAfter
+	if seconds, err := strconv.Atoi(os.Getenv("SYNTHESIS_JOB_STALE_SECONDS")); err == nil && seconds > 0 {
+		staleAfter = time.Duration(seconds) * time.Second
+	}
+
+	return &JobWorkerPool{
+		service:      service,
+		workers:      workers,
+		pollInterval: defaultPollInterval,
+		staleAfter:   staleAfter,
+	}
+}
+
+func (p *JobWorkerPool) Start(ctx context.Context) {
+	logger.Logger.Info("Starting synthesis job workers...", "workers", p.workers)
+
+	p.service.RequeueStaleJobs(p.staleAfter)
+
+	for i := 0; i < p.workers; i++ {
+		p.running.Add(1)
+		go func(worker int) {
+			defer p.running.Done()
+			p.work(ctx, worker)
+		}(i)
+	}
+
+	go p.reapStaleJobs(ctx)
+}
+
+// blocks until every worker has returned after the start context was cancelled
+func (p *JobWorkerPool) Wait() {
+	p.running.Wait()
+	logger.Logger.Info("Stopped synthesis job workers.")
+}
+
+func (p *JobWorkerPool) work(ctx context.Context, worker int) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			logger.Logger.Info("Stopped synthesis job worker.", "worker", worker)
+			return
+		}
+
+		processed, err := p.service.ProcessNextJob(ctx)
+		if err == nil && processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Logger.Info("Stopped synthesis job worker.", "worker", worker)
+			return
+		case <-p.service.Notifications():
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *JobWorkerPool) reapStaleJobs(ctx context.Context) {
+	ticker := time.NewTicker(p.staleAfter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.service.RequeueStaleJobs(p.staleAfter)
+		}
+	}
+}
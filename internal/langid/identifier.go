@@ -0,0 +1,183 @@
+package langid
+
+import (
+	"embed"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// sample texts the n-gram profiles are built from, one file per language
+//
+//go:embed profiles/*.txt
+var samples embed.FS
+
+const maxGramLength = 3
+
+type Detection struct {
+	Language   string
+	Confidence float64
+}
+
+type profile struct {
+	language string
+	// log probability of every n-gram seen in the sample, per n-gram length
+	logProbabilities map[string]float64
+	// log probability assigned to unseen n-grams, per n-gram length
+	unseen [maxGramLength + 1]float64
+}
+
+// Identifier detects the language of a text with character n-gram profiles
+// scored as a naive Bayes classifier. It works offline and is meant for
+// routing, not for linguistics: a sentence or two is enough to tell the
+// supported languages apart.
+type Identifier struct {
+	profiles []*profile
+}
+
+func NewIdentifier() *Identifier {
+	entries, err := samples.ReadDir("profiles")
+	if err != nil {
+		panic(err)
+	}
+
+	identifier := &Identifier{}
+	for _, entry := range entries {
+		data, err := samples.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		identifier.profiles = append(identifier.profiles, buildProfile(language, string(data)))
+	}
+
+	return identifier
+}
+
+func (i *Identifier) Languages() []string {
+	languages := make([]string, len(i.profiles))
+	for j, profile := range i.profiles {
+		languages[j] = profile.language
+	}
+
+	return languages
+}
+
+// Detect returns the most likely language of the text. When candidates are
+// given only those languages are considered. An empty language means the
+// text has no letters to judge by or none of the candidates is known.
+func (i *Identifier) Detect(text string, candidates ...string) Detection {
+	grams := extractGrams(text)
+	if len(grams) == 0 {
+		return Detection{}
+	}
+
+	var languages []string
+	var scores []float64
+	for _, profile := range i.profiles {
+		if len(candidates) > 0 && !slices.Contains(candidates, profile.language) {
+			continue
+		}
+
+		score := 0.0
+		for gram, count := range grams {
+			score += float64(count) * profile.logProbability(gram)
+		}
+
+		languages = append(languages, profile.language)
+		scores = append(scores, score)
+	}
+
+	if len(scores) == 0 {
+		return Detection{}
+	}
+
+	best := 0
+	for j := range scores {
+		if scores[j] > scores[best] {
+			best = j
+		}
+	}
+
+	// softmax over the scores gives the posterior of the best language
+	total := 0.0
+	for _, score := range scores {
+		total += math.Exp(score - scores[best])
+	}
+
+	return Detection{Language: languages[best], Confidence: 1 / total}
+}
+
+// Primary reduces a language tag such as "en-US" to its primary subtag.
+func Primary(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if index := strings.IndexAny(language, "-_"); index >= 0 {
+		return language[:index]
+	}
+
+	return language
+}
+
+func buildProfile(language, sample string) *profile {
+	counts := extractGrams(sample)
+
+	var totals, distinct [maxGramLength + 1]int
+	for gram, count := range counts {
+		n := len([]rune(gram))
+		totals[n] += count
+		distinct[n]++
+	}
+
+	profile := &profile{language: language, logProbabilities: make(map[string]float64, len(counts))}
+	for gram, count := range counts {
+		n := len([]rune(gram))
+		profile.logProbabilities[gram] = math.Log(float64(count+1) / float64(totals[n]+distinct[n]+1))
+	}
+
+	for n := 1; n <= maxGramLength; n++ {
+		profile.unseen[n] = math.Log(1 / float64(totals[n]+distinct[n]+1))
+	}
+
+	return profile
+}
+
+func (p *profile) logProbability(gram string) float64 {
+	if probability, ok := p.logProbabilities[gram]; ok {
+		return probability
+	}
+
+	return p.unseen[len([]rune(gram))]
+}
+
+// extractGrams counts the 1 to 3 letter n-grams of every word, with words
+// padded by spaces so their beginnings and endings count as well
+func extractGrams(text string) map[string]int {
+	grams := make(map[string]int)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	for _, word := range words {
+		word = strings.Trim(word, "'")
+		if word == "" {
+			continue
+		}
+
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= maxGramLength; n++ {
+			for start := 0; start+n <= len(runes); start++ {
+				gram := string(runes[start : start+n])
+				if gram == " " {
+					continue
+				}
+				grams[gram]++
+			}
+		}
+	}
+
+	return grams
+}
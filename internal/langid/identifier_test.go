@@ -0,0 +1,51 @@
+package langid
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	identifier := NewIdentifier()
+
+	cases := map[string]string{
+		"Good morning, how are you doing today?":                     "en",
+		"Доброго ранку, як у тебе справи сьогодні?":                  "uk",
+		"Доброе утро, как у тебя дела сегодня?":                      "ru",
+		"Guten Morgen, wie geht es dir heute?":                       "de",
+		"Bonjour, comment vas-tu aujourd'hui ?":                      "fr",
+		"Buenos días, ¿cómo estás hoy?":                              "es",
+		"Dzień dobry, jak się dzisiaj czujesz?":                      "pl",
+		"Buongiorno, come stai oggi?":                                "it",
+		"The train leaves from the second platform in ten minutes.":  "en",
+		"Потяг відправляється з другої платформи за десять хвилин.":  "uk",
+		"Поезд отправляется со второй платформы через десять минут.": "ru",
+	}
+
+	for text, expected := range cases {
+		if detection := identifier.Detect(text); detection.Language != expected {
+			t.Errorf("%q: expected %s, got %s (%.2f)", text, expected, detection.Language, detection.Confidence)
+		}
+	}
+}
+
+func TestDetectWithCandidates(t *testing.T) {
+	identifier := NewIdentifier()
+
+	if detection := identifier.Detect("Guten Morgen, wie geht es dir heute?", "en", "uk"); detection.Language != "en" {
+		t.Errorf("expected the closest candidate, got %s", detection.Language)
+	}
+
+	if detection := identifier.Detect("Hello there", "xx"); detection.Language != "" {
+		t.Errorf("expected no detection for unknown candidates, got %s", detection.Language)
+	}
+
+	if detection := identifier.Detect("12:30 - 14:00"); detection.Language != "" {
+		t.Errorf("expected no detection without letters, got %s", detection.Language)
+	}
+}
+
+func TestPrimary(t *testing.T) {
+	for tag, expected := range map[string]string{"en-US": "en", "uk": "uk", " PT_br ": "pt"} {
+		if primary := Primary(tag); primary != expected {
+			t.Errorf("%q: expected %s, got %s", tag, expected, primary)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package lexicon
+
+type EntryType string
+
+const (
+	// the grapheme is replaced with another spelling before synthesis
+	EntryAlias EntryType = "alias"
+
+	// the grapheme is sent to the model together with its pronunciation
+	EntryPhoneme EntryType = "phoneme"
+)
+
+type LexiconScope string
+
+const (
+	ScopeUser   LexiconScope = "user"
+	ScopeGlobal LexiconScope = "global"
+)
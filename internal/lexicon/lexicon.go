@@ -0,0 +1,41 @@
+package lexicon
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// global lexicons have an empty user id and apply to every user
+type Lexicon struct {
+	Id        string         `gorm:"type:varchar(256);primaryKey;"`
+	UserId    string         `gorm:"type:varchar(256);not null;default:'';index:idx_unique_lexicon_user_language,unique;"`
+	Language  string         `gorm:"type:varchar(255);not null;index:idx_unique_lexicon_user_language,unique;"`
+	Version   int            `gorm:"not null;default:0"`
+	Entries   []LexiconEntry `gorm:"foreignKey:LexiconId;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (lexicon *Lexicon) BeforeCreate(tx *gorm.DB) (err error) {
+	lexicon.Id = uuid.NewString()
+	return
+}
+
+type LexiconEntry struct {
+	Id            string    `gorm:"type:varchar(256);primaryKey;"`
+	LexiconId     string    `gorm:"type:varchar(256);not null;index:idx_unique_lexicon_entry_grapheme,unique;"`
+	Grapheme      string    `gorm:"type:varchar(256);not null;index:idx_unique_lexicon_entry_grapheme,unique;"`
+	Type          EntryType `gorm:"type:varchar(32);not null"`
+	Replacement   string    `gorm:"type:varchar(1024);not null"`
+	Alphabet      string    `gorm:"type:varchar(32);"`
+	CaseSensitive bool      `gorm:"not null;default:false"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (entry *LexiconEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	entry.Id = uuid.NewString()
+	return
+}
@@ -0,0 +1,116 @@
+package lexicon
+
+import (
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/users"
+	"vitaliiPsl/synthesizer/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LexiconController struct {
+	service           LexiconService
+	validationService *validation.ValidationService
+}
+
+func NewLexiconController(lexiconService LexiconService, validationService *validation.ValidationService) *LexiconController {
+	return &LexiconController{service: lexiconService, validationService: validationService}
+}
+
+func (controller *LexiconController) HandleFetchLexicons(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling fetch lexicons request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	lexicons, err := controller.service.GetLexicons(userDto, c.Query("language"))
+	if err != nil {
+		logger.Logger.Error("Failed to handle fetch lexicons request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled fetch lexicons request.")
+	return c.Status(fiber.StatusOK).JSON(lexicons)
+}
+
+func (controller *LexiconController) HandleCreateEntry(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling create lexicon entry request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.LexiconEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse lexicon entry request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateLexiconEntryRequest(&req); err != nil {
+		logger.Logger.Error("Lexicon entry request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	entry, err := controller.service.CreateEntry(userDto, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle create lexicon entry request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled create lexicon entry request.")
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+func (controller *LexiconController) HandleUpdateEntry(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling update lexicon entry request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.LexiconEntryUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse lexicon entry request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateLexiconEntryUpdateRequest(&req); err != nil {
+		logger.Logger.Error("Lexicon entry request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	entry, err := controller.service.UpdateEntry(userDto, c.Params("id"), &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle update lexicon entry request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled update lexicon entry request.")
+	return c.Status(fiber.StatusOK).JSON(entry)
+}
+
+func (controller *LexiconController) HandleDeleteEntry(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling delete lexicon entry request...")
+
+	userDto, ok := c.Locals("user").(*users.UserDto)
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	if err := controller.service.DeleteEntry(userDto, c.Params("id")); err != nil {
+		logger.Logger.Error("Failed to handle delete lexicon entry request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled delete lexicon entry request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
@@ -0,0 +1,66 @@
+package lexicon
+
+import "time"
+
+type LexiconDto struct {
+	Id        string            `json:"id"`
+	Scope     LexiconScope      `json:"scope"`
+	Language  string            `json:"language"`
+	Version   int               `json:"version"`
+	Entries   []LexiconEntryDto `json:"entries"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type LexiconEntryDto struct {
+	Id            string    `json:"id"`
+	LexiconId     string    `json:"lexicon_id"`
+	Grapheme      string    `json:"grapheme"`
+	Type          EntryType `json:"type"`
+	Replacement   string    `json:"replacement"`
+	Alphabet      string    `json:"alphabet,omitempty"`
+	CaseSensitive bool      `json:"case_sensitive"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// entries in effect for a synthesis request; user entries shadow global ones
+type ResolvedLexicon struct {
+	Entries       []LexiconEntryDto
+	GlobalVersion int
+	UserVersion   int
+}
+
+func ToLexiconDto(lexicon *Lexicon) *LexiconDto {
+	scope := ScopeUser
+	if lexicon.UserId == "" {
+		scope = ScopeGlobal
+	}
+
+	entries := make([]LexiconEntryDto, len(lexicon.Entries))
+	for i, entry := range lexicon.Entries {
+		entries[i] = *ToLexiconEntryDto(&entry)
+	}
+
+	return &LexiconDto{
+		Id:        lexicon.Id,
+		Scope:     scope,
+		Language:  lexicon.Language,
+		Version:   lexicon.Version,
+		Entries:   entries,
+		UpdatedAt: lexicon.UpdatedAt,
+	}
+}
+
+func ToLexiconEntryDto(entry *LexiconEntry) *LexiconEntryDto {
+	return &LexiconEntryDto{
+		Id:            entry.Id,
+		LexiconId:     entry.LexiconId,
+		Grapheme:      entry.Grapheme,
+		Type:          entry.Type,
+		Replacement:   entry.Replacement,
+		Alphabet:      entry.Alphabet,
+		CaseSensitive: entry.CaseSensitive,
+		CreatedAt:     entry.CreatedAt,
+		UpdatedAt:     entry.UpdatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package lexicon
+
+import (
+	"gorm.io/gorm"
+)
+
+type LexiconRepository interface {
+	FindVisible(userId, language string) ([]Lexicon, error)
+	FindById(id string) (*Lexicon, error)
+	FindEntryById(id string) (*LexiconEntry, error)
+	FindEntryByGrapheme(lexiconId, grapheme string) (*LexiconEntry, error)
+	FindOrCreate(userId, language string) (*Lexicon, error)
+	SaveEntry(entry *LexiconEntry) error
+	DeleteEntry(entry *LexiconEntry) error
+}
+
+type LexiconRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLexiconRepository(db *gorm.DB) *LexiconRepositoryImpl {
+	return &LexiconRepositoryImpl{db: db}
+}
+
+func (r *LexiconRepositoryImpl) FindVisible(userId, language string) ([]Lexicon, error) {
+	var lexicons []Lexicon
+
+	query := r.db.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("grapheme ASC")
+	}).Where("user_id = ? OR user_id = ''", userId)
+
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+
+	if err := query.Order("language ASC, user_id ASC").Find(&lexicons).Error; err != nil {
+		return nil, err
+	}
+
+	return lexicons, nil
+}
+
+func (r *LexiconRepositoryImpl) FindById(id string) (*Lexicon, error) {
+	var lexicon Lexicon
+
+	if err := r.db.First(&lexicon, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &lexicon, nil
+}
+
+func (r *LexiconRepositoryImpl) FindEntryById(id string) (*LexiconEntry, error) {
+	var entry LexiconEntry
+
+	if err := r.db.First(&entry, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *LexiconRepositoryImpl) FindEntryByGrapheme(lexiconId, grapheme string) (*LexiconEntry, error) {
+	var entry LexiconEntry
+
+	if err := r.db.Where("lexicon_id = ? AND lower(grapheme) = lower(?)", lexiconId, grapheme).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *LexiconRepositoryImpl) FindOrCreate(userId, language string) (*Lexicon, error) {
+	lexicon := Lexicon{UserId: userId, Language: language}
+
+	if err := r.db.Where("user_id = ? AND language = ?", userId, language).FirstOrCreate(&lexicon).Error; err != nil {
+		return nil, err
+	}
+
+	return &lexicon, nil
+}
+
+// every change to an entry bumps the version of its lexicon so that history
+// records can tell which revision of the dictionary was applied
+func (r *LexiconRepositoryImpl) SaveEntry(entry *LexiconEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(entry).Error; err != nil {
+			return err
+		}
+
+		return bumpVersion(tx, entry.LexiconId)
+	})
+}
+
+func (r *LexiconRepositoryImpl) DeleteEntry(entry *LexiconEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&LexiconEntry{}, "id = ?", entry.Id).Error; err != nil {
+			return err
+		}
+
+		return bumpVersion(tx, entry.LexiconId)
+	})
+}
+
+func bumpVersion(tx *gorm.DB, lexiconId string) error {
+	return tx.Model(&Lexicon{}).Where("id = ?", lexiconId).Updates(map[string]interface{}{
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+	}).Error
+}
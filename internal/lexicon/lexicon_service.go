@@ -0,0 +1,255 @@
+package lexicon
+
+import (
+	"errors"
+	"strings"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/users"
+
+	"gorm.io/gorm"
+)
+
+type LexiconService interface {
+	GetLexicons(user *users.UserDto, language string) ([]LexiconDto, error)
+	CreateEntry(user *users.UserDto, req *requests.LexiconEntryRequest) (*LexiconEntryDto, error)
+	UpdateEntry(user *users.UserDto, id string, req *requests.LexiconEntryUpdateRequest) (*LexiconEntryDto, error)
+	DeleteEntry(user *users.UserDto, id string) error
+	ResolveLexicon(userId, language string) (*ResolvedLexicon, error)
+}
+
+type LexiconServiceImpl struct {
+	repository LexiconRepository
+}
+
+func NewLexiconService(repository LexiconRepository) *LexiconServiceImpl {
+	return &LexiconServiceImpl{repository: repository}
+}
+
+func (s *LexiconServiceImpl) GetLexicons(user *users.UserDto, language string) ([]LexiconDto, error) {
+	logger.Logger.Info("Fetching lexicons...", "userId", user.Id, "language", language)
+
+	lexicons, err := s.repository.FindVisible(user.Id, normalizeLanguage(language))
+	if err != nil {
+		logger.Logger.Error("Failed to fetch lexicons", "userId", user.Id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch lexicons")
+	}
+
+	dtos := make([]LexiconDto, len(lexicons))
+	for i, lexicon := range lexicons {
+		dtos[i] = *ToLexiconDto(&lexicon)
+	}
+
+	logger.Logger.Info("Fetched lexicons.", "userId", user.Id, "count", len(dtos))
+	return dtos, nil
+}
+
+func (s *LexiconServiceImpl) CreateEntry(user *users.UserDto, req *requests.LexiconEntryRequest) (*LexiconEntryDto, error) {
+	logger.Logger.Info("Creating lexicon entry...", "userId", user.Id, "language", req.Language, "scope", req.Scope)
+
+	ownerId := user.Id
+	if LexiconScope(req.Scope) == ScopeGlobal {
+		if user.Role != users.RoleAdmin {
+			logger.Logger.Error("Only admins can manage the global lexicon", "userId", user.Id)
+			return nil, service_errors.NewErrForbidden("Only admins can manage the global lexicon")
+		}
+		ownerId = ""
+	}
+
+	if err := validateEntry(EntryType(req.Type), req.Alphabet); err != nil {
+		return nil, err
+	}
+
+	lexicon, err := s.repository.FindOrCreate(ownerId, normalizeLanguage(req.Language))
+	if err != nil {
+		logger.Logger.Error("Failed to fetch lexicon", "userId", user.Id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch lexicon")
+	}
+
+	if err := s.checkDuplicate(lexicon.Id, "", req.Grapheme); err != nil {
+		return nil, err
+	}
+
+	entry := &LexiconEntry{
+		LexiconId:     lexicon.Id,
+		Grapheme:      strings.TrimSpace(req.Grapheme),
+		Type:          EntryType(req.Type),
+		Replacement:   strings.TrimSpace(req.Replacement),
+		Alphabet:      req.Alphabet,
+		CaseSensitive: req.CaseSensitive,
+	}
+
+	if entry.Type == EntryAlias {
+		entry.Alphabet = ""
+	}
+
+	if err := s.repository.SaveEntry(entry); err != nil {
+		logger.Logger.Error("Failed to save lexicon entry", "userId", user.Id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to save lexicon entry")
+	}
+
+	logger.Logger.Info("Created lexicon entry.", "id", entry.Id, "lexiconId", lexicon.Id)
+	return ToLexiconEntryDto(entry), nil
+}
+
+func (s *LexiconServiceImpl) UpdateEntry(user *users.UserDto, id string, req *requests.LexiconEntryUpdateRequest) (*LexiconEntryDto, error) {
+	logger.Logger.Info("Updating lexicon entry...", "id", id, "userId", user.Id)
+
+	entry, err := s.findEditableEntry(user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Grapheme != "" {
+		if err := s.checkDuplicate(entry.LexiconId, entry.Id, req.Grapheme); err != nil {
+			return nil, err
+		}
+		entry.Grapheme = strings.TrimSpace(req.Grapheme)
+	}
+
+	if req.Type != "" {
+		entry.Type = EntryType(req.Type)
+	}
+
+	if req.Replacement != "" {
+		entry.Replacement = strings.TrimSpace(req.Replacement)
+	}
+
+	if req.Alphabet != "" {
+		entry.Alphabet = req.Alphabet
+	}
+
+	if entry.Type == EntryAlias {
+		entry.Alphabet = ""
+	}
+
+	if req.CaseSensitive != nil {
+		entry.CaseSensitive = *req.CaseSensitive
+	}
+
+	if err := validateEntry(entry.Type, entry.Alphabet); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.SaveEntry(entry); err != nil {
+		logger.Logger.Error("Failed to update lexicon entry", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to update lexicon entry")
+	}
+
+	logger.Logger.Info("Updated lexicon entry.", "id", id)
+	return ToLexiconEntryDto(entry), nil
+}
+
+func (s *LexiconServiceImpl) DeleteEntry(user *users.UserDto, id string) error {
+	logger.Logger.Info("Deleting lexicon entry...", "id", id, "userId", user.Id)
+
+	entry, err := s.findEditableEntry(user, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteEntry(entry); err != nil {
+		logger.Logger.Error("Failed to delete lexicon entry", "id", id, "error", err)
+		return service_errors.NewErrInternalServer("Failed to delete lexicon entry")
+	}
+
+	logger.Logger.Info("Deleted lexicon entry.", "id", id)
+	return nil
+}
+
+func (s *LexiconServiceImpl) ResolveLexicon(userId, language string) (*ResolvedLexicon, error) {
+	lexicons, err := s.repository.FindVisible(userId, normalizeLanguage(language))
+	if err != nil {
+		logger.Logger.Error("Failed to resolve lexicon", "userId", userId, "language", language, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to resolve lexicon")
+	}
+
+	resolved := &ResolvedLexicon{}
+	var global, personal []LexiconEntry
+
+	for _, lexicon := range lexicons {
+		if lexicon.UserId == "" {
+			resolved.GlobalVersion = lexicon.Version
+			global = lexicon.Entries
+		} else {
+			resolved.UserVersion = lexicon.Version
+			personal = lexicon.Entries
+		}
+	}
+
+	shadowed := make(map[string]bool, len(personal))
+	for _, entry := range personal {
+		shadowed[strings.ToLower(entry.Grapheme)] = true
+		resolved.Entries = append(resolved.Entries, *ToLexiconEntryDto(&entry))
+	}
+
+	for _, entry := range global {
+		if !shadowed[strings.ToLower(entry.Grapheme)] {
+			resolved.Entries = append(resolved.Entries, *ToLexiconEntryDto(&entry))
+		}
+	}
+
+	return resolved, nil
+}
+
+func (s *LexiconServiceImpl) findEditableEntry(user *users.UserDto, id string) (*LexiconEntry, error) {
+	entry, err := s.repository.FindEntryById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Lexicon entry not found", "id", id)
+			return nil, service_errors.NewErrNotFound("Lexicon entry not found")
+		}
+
+		logger.Logger.Error("Failed to fetch lexicon entry", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch lexicon entry")
+	}
+
+	lexicon, err := s.repository.FindById(entry.LexiconId)
+	if err != nil {
+		logger.Logger.Error("Failed to fetch lexicon", "id", entry.LexiconId, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch lexicon")
+	}
+
+	if lexicon.UserId == "" {
+		if user.Role != users.RoleAdmin {
+			logger.Logger.Error("Only admins can manage the global lexicon", "userId", user.Id)
+			return nil, service_errors.NewErrForbidden("Only admins can manage the global lexicon")
+		}
+		return entry, nil
+	}
+
+	if lexicon.UserId != user.Id {
+		logger.Logger.Error("Lexicon entry belongs to another user", "id", id, "userId", user.Id)
+		return nil, service_errors.NewErrNotFound("Lexicon entry not found")
+	}
+
+	return entry, nil
+}
+
+func (s *LexiconServiceImpl) checkDuplicate(lexiconId, entryId, grapheme string) error {
+	existing, err := s.repository.FindEntryByGrapheme(lexiconId, strings.TrimSpace(grapheme))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Logger.Error("Failed to fetch lexicon entry", "lexiconId", lexiconId, "error", err)
+		return service_errors.NewErrInternalServer("Failed to fetch lexicon entry")
+	}
+
+	if existing != nil && existing.Id != entryId {
+		logger.Logger.Error("Lexicon entry already exists", "lexiconId", lexiconId, "grapheme", grapheme)
+		return service_errors.NewErrBadRequest("Lexicon already has an entry for this grapheme")
+	}
+
+	return nil
+}
+
+func validateEntry(entryType EntryType, alphabet string) error {
+	if entryType == EntryPhoneme && alphabet == "" {
+		return service_errors.NewErrBadRequest("Phoneme entries require an alphabet")
+	}
+
+	return nil
+}
+
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.TrimSpace(language))
+}
@@ -0,0 +1,86 @@
+package lexicon
+
+import (
+	"errors"
+	"testing"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+)
+
+type visibleLexiconRepository struct {
+	LexiconRepository
+	lexicons []Lexicon
+	err      error
+
+	userId   string
+	language string
+}
+
+func (r *visibleLexiconRepository) FindVisible(userId, language string) ([]Lexicon, error) {
+	r.userId, r.language = userId, language
+	return r.lexicons, r.err
+}
+
+func TestResolveLexiconUserEntriesShadowGlobal(t *testing.T) {
+	repository := &visibleLexiconRepository{lexicons: []Lexicon{
+		{Version: 3, Entries: []LexiconEntry{
+			{Grapheme: "SQL", Type: EntryAlias, Replacement: "sequel"},
+			{Grapheme: "GIF", Type: EntryAlias, Replacement: "jif"},
+		}},
+		{UserId: "user", Version: 7, Entries: []LexiconEntry{
+			{Grapheme: "gif", Type: EntryAlias, Replacement: "gif with a hard g"},
+		}},
+	}}
+	service := NewLexiconService(repository)
+
+	resolved, err := service.ResolveLexicon("user", " EN ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repository.userId != "user" || repository.language != "en" {
+		t.Errorf("expected lexicons of user for en, got %q for %q", repository.userId, repository.language)
+	}
+	if resolved.GlobalVersion != 3 || resolved.UserVersion != 7 {
+		t.Errorf("expected versions 3 and 7, got %d and %d", resolved.GlobalVersion, resolved.UserVersion)
+	}
+
+	replacements := map[string]string{}
+	for _, entry := range resolved.Entries {
+		replacements[entry.Grapheme] = entry.Replacement
+	}
+
+	expected := map[string]string{"SQL": "sequel", "gif": "gif with a hard g"}
+	if len(replacements) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, replacements)
+	}
+	for grapheme, replacement := range expected {
+		if replacements[grapheme] != replacement {
+			t.Errorf("expected %q for %q, got %q", replacement, grapheme, replacements[grapheme])
+		}
+	}
+}
+
+func TestResolveLexiconWithoutUserLexicon(t *testing.T) {
+	repository := &visibleLexiconRepository{lexicons: []Lexicon{
+		{Version: 2, Entries: []LexiconEntry{{Grapheme: "SQL", Type: EntryAlias, Replacement: "sequel"}}},
+	}}
+	service := NewLexiconService(repository)
+
+	resolved, err := service.ResolveLexicon("", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resolved.Entries) != 1 || resolved.UserVersion != 0 || resolved.GlobalVersion != 2 {
+		t.Errorf("expected the global lexicon only, got %+v", resolved)
+	}
+}
+
+func TestResolveLexiconRepositoryFailure(t *testing.T) {
+	service := NewLexiconService(&visibleLexiconRepository{err: errors.New("connection refused")})
+
+	_, err := service.ResolveLexicon("user", "en")
+	if _, ok := err.(*service_errors.ErrInternalServer); !ok {
+		t.Errorf("expected ErrInternalServer, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type Endpoint struct {
+	ModelId   string
+	ReplicaId string
+	Url       string
+}
+
+func (e *Endpoint) key() string {
+	return e.ModelId + "|" + e.Url
+}
+
+type ReplicaStatusDto struct {
+	Id                  string     `json:"id,omitempty"`
+	Url                 string     `json:"url"`
+	Primary             bool       `json:"primary"`
+	Healthy             bool       `json:"healthy"`
+	Outstanding         int        `json:"outstanding"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LatencyP95Ms        int64      `json:"latency_p95_ms"`
+}
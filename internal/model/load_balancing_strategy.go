@@ -0,0 +1,11 @@
+package model
+
+type LoadBalancingStrategy string
+
+const (
+	// requests rotate over healthy endpoints in order
+	RoundRobin LoadBalancingStrategy = "round_robin"
+
+	// requests go to the healthy endpoint with the fewest in-flight calls
+	LeastOutstanding LoadBalancingStrategy = "least_outstanding"
+)
@@ -8,11 +8,32 @@ import (
 )
 
 type Model struct {
-	Id        string    `gorm:"type:varchar(256);primaryKey;"`
-	Url       string    `gorm:"type:varchar(256);"`
-	Name      string    `gorm:"type:varchar(255);index:idx_unique_name_language,unique;"`
-	Language  string    `gorm:"type:varchar(255);index:idx_unique_name_language,unique;"`
-	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	Id                 string                `gorm:"type:varchar(256);primaryKey;"`
+	Url                string                `gorm:"type:varchar(256);"`
+	Name               string                `gorm:"type:varchar(255);index:idx_unique_name_language,unique;"`
+	Language           string                `gorm:"type:varchar(255);index:idx_unique_name_language,unique;"`
+	Description        string                `gorm:"type:text;"`
+	Gender             VoiceGender           `gorm:"type:varchar(16);"`
+	VoiceType          VoiceType             `gorm:"type:varchar(32);"`
+	Tags               []string              `gorm:"type:jsonb;serializer:json"`
+	NativeSampleRate   int                   `gorm:"not null;default:0"`
+	PreviewKey         string                `gorm:"type:varchar(512);"`
+	PreviewContentType string                `gorm:"type:varchar(64);"`
+	TimeoutMs          int                   `gorm:"not null;default:0"`
+	LoadBalancing      LoadBalancingStrategy `gorm:"type:varchar(32);default:'round_robin'"`
+	Protocol           ModelProtocol         `gorm:"type:varchar(32);not null;default:'json'"`
+	UpstreamModel      string                `gorm:"type:varchar(255);"`
+	MaxInputLength     int                   `gorm:"not null;default:0"`
+	Capabilities       ModelCapabilities     `gorm:"type:jsonb;serializer:json"`
+	ActiveVersionId    string                `gorm:"type:varchar(256);"`
+	PreviousVersionId  string                `gorm:"type:varchar(256);"`
+	CanaryVersionId    string                `gorm:"type:varchar(256);"`
+	CanaryPercent      int                   `gorm:"not null;default:0"`
+	Versions           []ModelVersion        `gorm:"foreignKey:ModelId;constraint:OnDelete:CASCADE"`
+	LanguageRules      []ModelLanguageRule   `gorm:"foreignKey:ModelId;constraint:OnDelete:CASCADE"`
+	Replicas           []ModelReplica        `gorm:"foreignKey:ModelId;constraint:OnDelete:CASCADE"`
+	ArchivedAt         *time.Time            `gorm:"type:timestamp;index"`
+	CreatedAt          time.Time             `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
 }
 
 func (model *Model) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+type ParameterRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ranges are declared only for parameters the model handles natively;
+// anything else is either emulated after synthesis or rejected
+type ModelCapabilities struct {
+	Rate     *ParameterRange `json:"rate,omitempty"`
+	Pitch    *ParameterRange `json:"pitch,omitempty"`
+	Volume   *ParameterRange `json:"volume,omitempty"`
+	Speakers []string        `json:"speakers,omitempty"`
+	Styles   []string        `json:"styles,omitempty"`
+}
+
+func (r *ParameterRange) Contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+func (c *ModelCapabilities) SupportsSpeaker(speaker string) bool {
+	return slices.Contains(c.Speakers, speaker)
+}
+
+func (c *ModelCapabilities) SupportsStyle(style string) bool {
+	return slices.Contains(c.Styles, style)
+}
+
+func ToModelCapabilities(req *requests.ModelCapabilitiesRequest) (ModelCapabilities, error) {
+	if req == nil {
+		return ModelCapabilities{}, nil
+	}
+
+	capabilities := ModelCapabilities{Speakers: req.Speakers, Styles: req.Styles}
+
+	ranges := []struct {
+		name   string
+		source *requests.ParameterRangeRequest
+		target **ParameterRange
+	}{
+		{"rate", req.Rate, &capabilities.Rate},
+		{"pitch", req.Pitch, &capabilities.Pitch},
+		{"volume", req.Volume, &capabilities.Volume},
+	}
+
+	for _, r := range ranges {
+		if r.source == nil {
+			continue
+		}
+
+		if r.source.Min > r.source.Max {
+			return ModelCapabilities{}, fmt.Errorf("%s range minimum must not exceed maximum", r.name)
+		}
+
+		*r.target = &ParameterRange{Min: r.source.Min, Max: r.source.Max}
+	}
+
+	return capabilities, nil
+}
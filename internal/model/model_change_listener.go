@@ -0,0 +1,5 @@
+package model
+
+type ModelChangeListener interface {
+	OnModelChanged(modelId string)
+}
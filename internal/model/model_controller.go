@@ -1,6 +1,9 @@
 package model
 
 import (
+	"strings"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/requests"
 	"vitaliiPsl/synthesizer/internal/validation"
@@ -10,11 +13,14 @@ import (
 
 type ModelController struct {
 	service           ModelService
+	replicaService    ReplicaService
+	versionService    VersionService
+	registryService   RegistryService
 	validationService *validation.ValidationService
 }
 
-func NewModelController(modelService ModelService, validationService *validation.ValidationService) *ModelController {
-	return &ModelController{service: modelService, validationService: validationService}
+func NewModelController(modelService ModelService, replicaService ReplicaService, versionService VersionService, registryService RegistryService, validationService *validation.ValidationService) *ModelController {
+	return &ModelController{service: modelService, replicaService: replicaService, versionService: versionService, registryService: registryService, validationService: validationService}
 }
 
 func (controller *ModelController) HandleSaveModel(c *fiber.Ctx) error {
@@ -22,12 +28,12 @@ func (controller *ModelController) HandleSaveModel(c *fiber.Ctx) error {
 
 	var req requests.ModelRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse model request", err)
+		logger.Logger.Error("Failed to parse model request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
 	if err := controller.validationService.ValidateModelRequest(&req); err != nil {
-		logger.Logger.Error("Model request didn't pass validation", "message", err.Error(), err)
+		logger.Logger.Error("Model request didn't pass validation", "message", err.Error())
 		return err
 	}
 
@@ -53,15 +59,24 @@ func (controller *ModelController) HandleUpdateModel(c *fiber.Ctx) error {
 
 	var req requests.ModelRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse model request", err)
+		logger.Logger.Error("Failed to parse model request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if _, err := controller.service.UpdateModel(modelId, &req); err != nil {
+	updated, err := controller.service.UpdateModel(modelId, &req)
+	if err != nil {
 		logger.Logger.Error("Failed to handle update model request", "message", err.Error())
 		return err
 	}
 
+	if updated.StagedVersionId != "" {
+		logger.Logger.Info("Handled update model request. Connection change was staged.", "versionId", updated.StagedVersionId)
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"staged_version_id": updated.StagedVersionId,
+			"message":           "Connection changes were saved as an inactive version, activate it or start a canary to roll it out",
+		})
+	}
+
 	logger.Logger.Info("Handled update model request.")
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
@@ -77,8 +92,8 @@ func (controller *ModelController) HandleDeleteModel(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := controller.service.DeleteModel(modelId); err != nil {
-		logger.Logger.Error("Failed to delete model", "message", err.Error())
+	if err := controller.service.ArchiveModel(modelId); err != nil {
+		logger.Logger.Error("Failed to archive model", "message", err.Error())
 		return err
 	}
 
@@ -86,10 +101,70 @@ func (controller *ModelController) HandleDeleteModel(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
 }
 
+func (controller *ModelController) HandleFetchArchivedModels(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling archived models request...")
+
+	response, err := controller.service.GetArchivedModels()
+	if err != nil {
+		logger.Logger.Error("Failed to handle archived models request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled archived models request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleRestoreModel(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling restore model request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.service.RestoreModel(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to restore model", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled restore model request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandlePurgeModel(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling purge model request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	if err := controller.service.PurgeModel(modelId); err != nil {
+		logger.Logger.Error("Failed to purge model", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled purge model request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
 func (controller *ModelController) HandleFetchModels(c *fiber.Ctx) error {
 	logger.Logger.Info("Handling models request...")
 
-	response, err := controller.service.GetModels()
+	query, err := parseModelQuery(c)
+	if err != nil {
+		logger.Logger.Error("Failed to parse models query", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.service.GetModels(query)
 	if err != nil {
 		logger.Logger.Error("Failed to handle models request", "message", err.Error())
 		return err
@@ -98,3 +173,455 @@ func (controller *ModelController) HandleFetchModels(c *fiber.Ctx) error {
 	logger.Logger.Info("Handled models request.")
 	return c.Status(fiber.StatusOK).JSON(response)
 }
+
+func (controller *ModelController) HandleFetchModel(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling model request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.service.GetModelById(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle model request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled model request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleSaveModelPreview(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling save model preview request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	format, ok := audio.FormatFromContentType(strings.Split(c.Get(fiber.HeaderContentType), ";")[0])
+	if !ok || format == audio.FormatJson {
+		logger.Logger.Error("Unsupported preview content type", "contentType", c.Get(fiber.HeaderContentType))
+		return service_errors.NewErrBadRequest("Preview must be a wav, mp3, ogg or flac file")
+	}
+
+	if len(c.Body()) == 0 {
+		logger.Logger.Error("Preview body is empty.")
+		return service_errors.NewErrBadRequest("Preview audio is required")
+	}
+
+	preview := &ModelPreview{Data: c.Body(), ContentType: format.ContentType(), Extension: format.Extension()}
+	response, err := controller.service.SaveModelPreview(modelId, preview)
+	if err != nil {
+		logger.Logger.Error("Failed to handle save model preview request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled save model preview request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleFetchModelPreview(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling model preview request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	preview, err := controller.service.GetModelPreview(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle model preview request", "message", err.Error())
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, preview.ContentType)
+
+	logger.Logger.Info("Handled model preview request.", "id", modelId)
+	return c.Status(fiber.StatusOK).Send(preview.Data)
+}
+
+func (controller *ModelController) HandleFetchReplicas(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling model replicas request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.replicaService.GetReplicaStatuses(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle model replicas request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled model replicas request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleAddReplica(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling add model replica request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	var req requests.ReplicaRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse replica request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateReplicaRequest(&req); err != nil {
+		logger.Logger.Error("Replica request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.replicaService.AddReplica(modelId, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle add model replica request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled add model replica request.")
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (controller *ModelController) HandleDeleteReplica(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling delete model replica request...")
+
+	modelId := c.Params("id")
+	replicaId := c.Params("replicaId")
+	if modelId == "" || replicaId == "" {
+		logger.Logger.Error("Model Id or replica Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id and replica Id are required",
+		})
+	}
+
+	if err := controller.replicaService.DeleteReplica(modelId, replicaId); err != nil {
+		logger.Logger.Error("Failed to delete model replica", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled delete model replica request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+func (controller *ModelController) HandleFetchVersions(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling model versions request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.versionService.GetVersions(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle model versions request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled model versions request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleAddVersion(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling add model version request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	var req requests.ModelVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse model version request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateModelVersionRequest(&req); err != nil {
+		logger.Logger.Error("Model version request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.versionService.AddVersion(modelId, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle add model version request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled add model version request.")
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+func (controller *ModelController) HandleActivateVersion(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling activate model version request...")
+
+	modelId := c.Params("id")
+	versionId := c.Params("versionId")
+	if modelId == "" || versionId == "" {
+		logger.Logger.Error("Model Id or version Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id and version Id are required",
+		})
+	}
+
+	response, err := controller.versionService.ActivateVersion(modelId, versionId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle activate model version request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled activate model version request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleStartCanary(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling start model canary request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	var req requests.CanaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse canary request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateCanaryRequest(&req); err != nil {
+		logger.Logger.Error("Canary request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.versionService.StartCanary(modelId, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle start model canary request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled start model canary request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleStopCanary(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling stop model canary request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.versionService.StopCanary(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle stop model canary request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled stop model canary request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleRollback(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling model rollback request...")
+
+	modelId := c.Params("id")
+	if modelId == "" {
+		logger.Logger.Error("Model Id is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Model Id is required",
+		})
+	}
+
+	response, err := controller.versionService.Rollback(modelId)
+	if err != nil {
+		logger.Logger.Error("Failed to handle model rollback request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled model rollback request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleFetchLanguageRules(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling language rules request...")
+
+	response, err := controller.service.GetLanguageRules()
+	if err != nil {
+		logger.Logger.Error("Failed to handle language rules request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled language rules request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleSetLanguageRule(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling set language rule request...")
+
+	language := c.Params("language")
+	if language == "" {
+		logger.Logger.Error("Language is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Language is required",
+		})
+	}
+
+	var req requests.LanguageRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse language rule request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateLanguageRuleRequest(&req); err != nil {
+		logger.Logger.Error("Language rule request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	response, err := controller.service.SetLanguageRule(language, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to handle set language rule request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled set language rule request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func (controller *ModelController) HandleDeleteLanguageRule(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling delete language rule request...")
+
+	language := c.Params("language")
+	if language == "" {
+		logger.Logger.Error("Language is missing.")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Language is required",
+		})
+	}
+
+	if err := controller.service.DeleteLanguageRule(language); err != nil {
+		logger.Logger.Error("Failed to handle delete language rule request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled delete language rule request.")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{})
+}
+
+func (controller *ModelController) HandleExportRegistry(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling export model registry request...")
+
+	format, ok := ParseRegistryFormat(c.Query("format"))
+	if !ok {
+		logger.Logger.Error("Unsupported registry format", "format", c.Query("format"))
+		return service_errors.NewErrBadRequest("Unsupported registry format: " + c.Query("format"))
+	}
+
+	document, err := controller.registryService.Export()
+	if err != nil {
+		logger.Logger.Error("Failed to handle export model registry request", "message", err.Error())
+		return err
+	}
+
+	data, err := EncodeRegistry(document, format)
+	if err != nil {
+		logger.Logger.Error("Failed to encode model registry", "error", err)
+		return service_errors.NewErrInternalServer("Failed to encode model registry")
+	}
+
+	logger.Logger.Info("Handled export model registry request.")
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+func (controller *ModelController) HandleImportRegistry(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling import model registry request...")
+
+	format := RegistryFormatFromContentType(c.Get(fiber.HeaderContentType))
+	document, err := DecodeRegistry(c.Body(), format)
+	if err != nil {
+		logger.Logger.Error("Failed to parse model registry", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid registry: " + err.Error()})
+	}
+
+	options := RegistryImportOptions{
+		DryRun:          c.QueryBool("dryRun"),
+		Prune:           c.QueryBool("prune"),
+		RequireRevision: !c.QueryBool("force"),
+	}
+
+	response, err := controller.registryService.Import(document, options)
+	if err != nil {
+		logger.Logger.Error("Failed to handle import model registry request", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled import model registry request.")
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func parseModelQuery(c *fiber.Ctx) (*ModelQuery, error) {
+	query := &ModelQuery{
+		Language:  c.Query("language"),
+		Tag:       strings.ToLower(strings.TrimSpace(c.Query("tag"))),
+		Gender:    VoiceGender(c.Query("gender")),
+		VoiceType: VoiceType(c.Query("voiceType")),
+	}
+
+	if query.Gender != "" && !query.Gender.Valid() {
+		return nil, service_errors.NewErrBadRequest("Unsupported gender: " + string(query.Gender))
+	}
+
+	if query.VoiceType != "" && !query.VoiceType.Valid() {
+		return nil, service_errors.NewErrBadRequest("Unsupported voice type: " + string(query.VoiceType))
+	}
+
+	// capability can be repeated or comma separated
+	for _, value := range c.Context().QueryArgs().PeekMulti("capability") {
+		for _, name := range strings.Split(string(value), ",") {
+			capability := ModelCapability(strings.TrimSpace(name))
+			if capability == "" {
+				continue
+			}
+
+			if _, ok := capability.Condition(); !ok {
+				return nil, service_errors.NewErrBadRequest("Unsupported capability: " + string(capability))
+			}
+
+			query.Capabilities = append(query.Capabilities, capability)
+		}
+	}
+
+	return query, nil
+}
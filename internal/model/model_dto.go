@@ -3,29 +3,167 @@ package model
 import "time"
 
 type ModelDto struct {
+	Id                 string                `json:"id"`
+	Url                string                `json:"url"`
+	Name               string                `json:"name"`
+	Language           string                `json:"language"`
+	Description        string                `json:"description,omitempty"`
+	Gender             VoiceGender           `json:"gender,omitempty"`
+	VoiceType          VoiceType             `json:"voice_type,omitempty"`
+	Tags               []string              `json:"tags,omitempty"`
+	NativeSampleRate   int                   `json:"native_sample_rate,omitempty"`
+	MultiSpeaker       bool                  `json:"multi_speaker"`
+	HasPreview         bool                  `json:"has_preview"`
+	PreviewKey         string                `json:"-"`
+	PreviewContentType string                `json:"-"`
+	TimeoutMs          int                   `json:"timeout_ms"`
+	LoadBalancing      LoadBalancingStrategy `json:"load_balancing"`
+	Protocol           ModelProtocol         `json:"protocol"`
+	UpstreamModel      string                `json:"upstream_model,omitempty"`
+	MaxInputLength     int                   `json:"max_input_length"`
+	Capabilities       ModelCapabilities     `json:"capabilities"`
+	ActiveVersionId    string                `json:"active_version_id,omitempty"`
+	PreviousVersionId  string                `json:"previous_version_id,omitempty"`
+	CanaryVersionId    string                `json:"canary_version_id,omitempty"`
+	CanaryPercent      int                   `json:"canary_percent"`
+	Versions           []ModelVersionDto     `json:"versions"`
+	StagedVersionId    string                `json:"staged_version_id,omitempty"`
+	ServingVersionId   string                `json:"-"`
+	ServingVersion     int                   `json:"-"`
+	Health             *ModelHealthDto       `json:"health,omitempty"`
+	Replicas           []ModelReplicaDto     `json:"replicas"`
+	ArchivedAt         *time.Time            `json:"archived_at,omitempty"`
+	CreatedAt          time.Time             `json:"created_at"`
+}
+
+type ModelReplicaDto struct {
 	Id        string    `json:"id"`
+	VersionId string    `json:"version_id,omitempty"`
 	Url       string    `json:"url"`
-	Name      string    `json:"name"`
-	Language  string    `json:"language"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 func ToModelModel(dto *ModelDto) *Model {
+	versions := make([]ModelVersion, len(dto.Versions))
+	for i, version := range dto.Versions {
+		versions[i] = *ToModelVersionModel(dto.Id, &version)
+	}
+
+	replicas := make([]ModelReplica, len(dto.Replicas))
+	for i, replica := range dto.Replicas {
+		replicas[i] = *ToModelReplicaModel(dto.Id, &replica)
+	}
+
 	return &Model{
+		Id:                 dto.Id,
+		Url:                dto.Url,
+		Name:               dto.Name,
+		Language:           dto.Language,
+		Description:        dto.Description,
+		Gender:             dto.Gender,
+		VoiceType:          dto.VoiceType,
+		Tags:               dto.Tags,
+		NativeSampleRate:   dto.NativeSampleRate,
+		PreviewKey:         dto.PreviewKey,
+		PreviewContentType: dto.PreviewContentType,
+		TimeoutMs:          dto.TimeoutMs,
+		LoadBalancing:      dto.LoadBalancing,
+		Protocol:           dto.Protocol,
+		UpstreamModel:      dto.UpstreamModel,
+		MaxInputLength:     dto.MaxInputLength,
+		Capabilities:       dto.Capabilities,
+		ActiveVersionId:    dto.ActiveVersionId,
+		PreviousVersionId:  dto.PreviousVersionId,
+		CanaryVersionId:    dto.CanaryVersionId,
+		CanaryPercent:      dto.CanaryPercent,
+		Versions:           versions,
+		Replicas:           replicas,
+		ArchivedAt:         dto.ArchivedAt,
+		CreatedAt:          dto.CreatedAt,
+	}
+}
+
+func ToModelDto(model *Model) *ModelDto {
+	versions := make([]ModelVersionDto, len(model.Versions))
+	for i, version := range model.Versions {
+		versions[i] = *ToModelVersionDto(&version)
+	}
+
+	replicas := make([]ModelReplicaDto, len(model.Replicas))
+	for i, replica := range model.Replicas {
+		replicas[i] = *ToModelReplicaDto(&replica)
+	}
+
+	return &ModelDto{
+		Id:                 model.Id,
+		Url:                model.Url,
+		Name:               model.Name,
+		Language:           model.Language,
+		Description:        model.Description,
+		Gender:             model.Gender,
+		VoiceType:          model.VoiceType,
+		Tags:               model.Tags,
+		NativeSampleRate:   model.NativeSampleRate,
+		MultiSpeaker:       len(model.Capabilities.Speakers) > 1,
+		HasPreview:         model.PreviewKey != "",
+		PreviewKey:         model.PreviewKey,
+		PreviewContentType: model.PreviewContentType,
+		TimeoutMs:          model.TimeoutMs,
+		LoadBalancing:      model.LoadBalancing,
+		Protocol:           model.Protocol,
+		UpstreamModel:      model.UpstreamModel,
+		MaxInputLength:     model.MaxInputLength,
+		Capabilities:       model.Capabilities,
+		ActiveVersionId:    model.ActiveVersionId,
+		PreviousVersionId:  model.PreviousVersionId,
+		CanaryVersionId:    model.CanaryVersionId,
+		CanaryPercent:      model.CanaryPercent,
+		Versions:           versions,
+		ServingVersionId:   model.ActiveVersionId,
+		ServingVersion:     activeVersionNumber(model),
+		Replicas:           replicas,
+		ArchivedAt:         model.ArchivedAt,
+		CreatedAt:          model.CreatedAt,
+	}
+}
+
+func ToModelReplicaModel(modelId string, dto *ModelReplicaDto) *ModelReplica {
+	return &ModelReplica{
 		Id:        dto.Id,
+		ModelId:   modelId,
+		VersionId: dto.VersionId,
 		Url:       dto.Url,
-		Name:      dto.Name,
-		Language:  dto.Language,
 		CreatedAt: dto.CreatedAt,
 	}
 }
 
-func ToModelDto(model *Model) *ModelDto {
-	return &ModelDto{
-		Id:        model.Id,
-		Url:       model.Url,
-		Name:      model.Name,
-		Language:  model.Language,
-		CreatedAt: model.CreatedAt,
+func ToModelReplicaDto(replica *ModelReplica) *ModelReplicaDto {
+	return &ModelReplicaDto{
+		Id:        replica.Id,
+		VersionId: replica.VersionId,
+		Url:       replica.Url,
+		CreatedAt: replica.CreatedAt,
+	}
+}
+
+// Endpoints lists the urls serving the model's current version. Replicas of
+// other versions are left out so a canary never leaks into active traffic.
+func (dto *ModelDto) Endpoints() []Endpoint {
+	endpoints := []Endpoint{{ModelId: dto.Id, Url: dto.Url}}
+	for _, replica := range dto.Replicas {
+		if dto.ServingVersionId != "" && replica.VersionId != "" && replica.VersionId != dto.ServingVersionId {
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{ModelId: dto.Id, ReplicaId: replica.Id, Url: replica.Url})
 	}
+
+	return endpoints
+}
+
+func activeVersionNumber(model *Model) int {
+	if version := model.findVersion(model.ActiveVersionId); version != nil {
+		return version.Number
+	}
+
+	return 0
 }
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+type ModelStatus string
+
+const (
+	ModelAvailable ModelStatus = "available"
+	ModelDegraded  ModelStatus = "degraded"
+	ModelDown      ModelStatus = "down"
+)
+
+// health of the active version, aggregated over its endpoints and the most
+// recent probes
+type ModelHealthDto struct {
+	Status        ModelStatus `json:"status"`
+	Availability  float64     `json:"availability"`
+	LatencyP50Ms  int64       `json:"latency_p50_ms"`
+	LatencyP95Ms  int64       `json:"latency_p95_ms"`
+	LatencyP99Ms  int64       `json:"latency_p99_ms"`
+	LastCheckedAt *time.Time  `json:"last_checked_at,omitempty"`
+}
+
+type probeResult struct {
+	healthy bool
+	latency time.Duration
+}
+
+func (dto *ModelDto) Down() bool {
+	return dto.Health != nil && dto.Health.Status == ModelDown
+}
+
+// nearest-rank percentile of sorted latencies, in milliseconds
+func percentileMs(sorted []time.Duration, percentile int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (percentile*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1].Milliseconds()
+}
+
+func sortedLatencies(probes []probeResult) []time.Duration {
+	var latencies []time.Duration
+	for _, probe := range probes {
+		if probe.healthy {
+			latencies = append(latencies, probe.latency)
+		}
+	}
+
+	slices.Sort(latencies)
+	return latencies
+}
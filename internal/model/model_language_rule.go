@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// the model used for a language when a synthesis request names a language
+// instead of a model
+type ModelLanguageRule struct {
+	Language  string    `gorm:"type:varchar(35);primaryKey;"`
+	ModelId   string    `gorm:"type:varchar(256);not null;index"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+type ModelLanguageRuleDto struct {
+	Language  string    `json:"language"`
+	ModelId   string    `json:"model_id"`
+	ModelName string    `json:"model_name,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToModelLanguageRuleDto(rule *ModelLanguageRule) *ModelLanguageRuleDto {
+	return &ModelLanguageRuleDto{
+		Language:  rule.Language,
+		ModelId:   rule.ModelId,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,182 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/langid"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+
+	"gorm.io/gorm"
+)
+
+var languageTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+func (s *ModelServiceImpl) GetLanguageRules() ([]ModelLanguageRuleDto, error) {
+	logger.Logger.Info("Fetching language rules...")
+
+	rules, err := s.repository.FindLanguageRules()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch language rules", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch language rules")
+	}
+
+	dtos := make([]ModelLanguageRuleDto, len(rules))
+	for i, rule := range rules {
+		dtos[i] = *ToModelLanguageRuleDto(&rule)
+		if model, err := s.repository.FindById(rule.ModelId); err == nil {
+			dtos[i].ModelName = model.Name
+		}
+	}
+
+	logger.Logger.Info("Fetched language rules.", "size", len(dtos))
+	return dtos, nil
+}
+
+func (s *ModelServiceImpl) SetLanguageRule(language string, req *requests.LanguageRuleRequest) (*ModelLanguageRuleDto, error) {
+	logger.Logger.Info("Setting language rule...", "language", language, "modelId", req.ModelId)
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !languageTagPattern.MatchString(language) {
+		logger.Logger.Error("Invalid language tag", "language", language)
+		return nil, service_errors.NewErrBadRequest("Invalid language tag: " + language)
+	}
+
+	model, err := s.findModel(req.ModelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.ArchivedAt != nil {
+		logger.Logger.Error("Model is archived", "modelId", model.Id)
+		return nil, service_errors.NewErrBadRequest("Archived models can't be used as a language default")
+	}
+
+	if langid.Primary(model.Language) != langid.Primary(language) {
+		logger.Logger.Error("Model language doesn't match the rule", "language", language, "modelLanguage", model.Language)
+		return nil, service_errors.NewErrBadRequest("Model speaks " + model.Language + ", not " + language)
+	}
+
+	rule := &ModelLanguageRule{Language: language, ModelId: model.Id}
+	if existing, err := s.repository.FindLanguageRule(language); err == nil {
+		rule.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.repository.SaveLanguageRule(rule); err != nil {
+		logger.Logger.Error("Failed to save language rule", "language", language, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to save language rule")
+	}
+
+	dto := ToModelLanguageRuleDto(rule)
+	dto.ModelName = model.Name
+
+	logger.Logger.Info("Set language rule.", "language", language, "modelId", model.Id)
+	return dto, nil
+}
+
+func (s *ModelServiceImpl) DeleteLanguageRule(language string) error {
+	logger.Logger.Info("Deleting language rule...", "language", language)
+
+	deleted, err := s.repository.DeleteLanguageRule(strings.ToLower(strings.TrimSpace(language)))
+	if err != nil {
+		logger.Logger.Error("Failed to delete language rule", "language", language, "error", err)
+		return service_errors.NewErrInternalServer("Failed to delete language rule")
+	}
+
+	if !deleted {
+		logger.Logger.Error("Language rule not found", "language", language)
+		return service_errors.NewErrNotFound("Language rule not found")
+	}
+
+	logger.Logger.Info("Deleted language rule.", "language", language)
+	return nil
+}
+
+// GetModelForLanguage resolves the model serving a language: the admin rule
+// for the exact tag, then for its primary subtag, and otherwise the first
+// model speaking the language, preferring exact tags and models that are up.
+func (s *ModelServiceImpl) GetModelForLanguage(language string) (*ModelDto, error) {
+	logger.Logger.Info("Resolving model for language...", "language", language)
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	primary := langid.Primary(language)
+
+	for _, tag := range slices.Compact([]string{language, primary}) {
+		rule, err := s.repository.FindLanguageRule(tag)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Logger.Error("Failed to fetch language rule", "language", tag, "error", err)
+				return nil, service_errors.NewErrInternalServer("Failed to fetch language rule")
+			}
+			continue
+		}
+
+		model, err := s.repository.FindById(rule.ModelId)
+		if err != nil || model.ArchivedAt != nil {
+			logger.Logger.Warn("Language rule points to an unavailable model", "language", tag, "modelId", rule.ModelId)
+			continue
+		}
+
+		logger.Logger.Info("Resolved model for language by rule.", "language", language, "modelId", model.Id)
+		return s.withHealth(ToModelDto(model)), nil
+	}
+
+	models, err := s.repository.FindByQuery(&ModelQuery{})
+	if err != nil {
+		logger.Logger.Error("Failed to fetch models", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch models")
+	}
+
+	var selected *ModelDto
+	rank := func(dto *ModelDto) int {
+		score := 0
+		if strings.EqualFold(dto.Language, language) {
+			score += 2
+		}
+		if !dto.Down() {
+			score += 1
+		}
+		return score
+	}
+
+	for i := range models {
+		if langid.Primary(models[i].Language) != primary {
+			continue
+		}
+
+		dto := s.withHealth(ToModelDto(&models[i]))
+		if selected == nil || rank(dto) > rank(selected) {
+			selected = dto
+		}
+	}
+
+	if selected == nil {
+		logger.Logger.Error("No model for language", "language", language)
+		return nil, service_errors.NewErrNotFound("No model is available for language: " + language)
+	}
+
+	logger.Logger.Info("Resolved model for language.", "language", language, "modelId", selected.Id)
+	return selected, nil
+}
+
+// GetLanguages lists the primary language subtags served by active models.
+func (s *ModelServiceImpl) GetLanguages() ([]string, error) {
+	models, err := s.repository.FindByQuery(&ModelQuery{})
+	if err != nil {
+		logger.Logger.Error("Failed to fetch models", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch models")
+	}
+
+	var languages []string
+	for _, model := range models {
+		if language := langid.Primary(model.Language); !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+
+	slices.Sort(languages)
+	return languages, nil
+}
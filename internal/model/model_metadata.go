@@ -0,0 +1,43 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+type VoiceGender string
+
+const (
+	GenderMale    VoiceGender = "male"
+	GenderFemale  VoiceGender = "female"
+	GenderNeutral VoiceGender = "neutral"
+)
+
+type VoiceType string
+
+const (
+	VoiceNeural        VoiceType = "neural"
+	VoiceConcatenative VoiceType = "concatenative"
+	VoiceParametric    VoiceType = "parametric"
+)
+
+func (g VoiceGender) Valid() bool {
+	return g == GenderMale || g == GenderFemale || g == GenderNeutral
+}
+
+func (t VoiceType) Valid() bool {
+	return t == VoiceNeural || t == VoiceConcatenative || t == VoiceParametric
+}
+
+// tags are matched case-insensitively, so they're stored lowercased and unique
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
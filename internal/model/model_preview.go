@@ -0,0 +1,7 @@
+package model
+
+type ModelPreview struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
@@ -0,0 +1,17 @@
+package model
+
+type ModelProtocol string
+
+const (
+	// POST {text, ...} answered with {samples, sampling_rate}
+	ProtocolJson ModelProtocol = "json"
+
+	// the same request answered with a wav file
+	ProtocolWav ModelProtocol = "wav"
+
+	// OpenAI compatible POST /v1/audio/speech
+	ProtocolOpenAi ModelProtocol = "openai"
+
+	// synthesizer.v1.Synthesizer/Synthesize over gRPC, urls look like grpc://host:port
+	ProtocolGrpc ModelProtocol = "grpc"
+)
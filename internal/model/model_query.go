@@ -0,0 +1,33 @@
+package model
+
+type ModelCapability string
+
+const (
+	CapabilityRate         ModelCapability = "rate"
+	CapabilityPitch        ModelCapability = "pitch"
+	CapabilityVolume       ModelCapability = "volume"
+	CapabilityStyles       ModelCapability = "styles"
+	CapabilityMultiSpeaker ModelCapability = "multi_speaker"
+)
+
+var capabilityConditions = map[ModelCapability]string{
+	CapabilityRate:         "jsonb_typeof(capabilities -> 'rate') = 'object'",
+	CapabilityPitch:        "jsonb_typeof(capabilities -> 'pitch') = 'object'",
+	CapabilityVolume:       "jsonb_typeof(capabilities -> 'volume') = 'object'",
+	CapabilityStyles:       "jsonb_array_length(coalesce(capabilities -> 'styles', '[]'::jsonb)) > 0",
+	CapabilityMultiSpeaker: "jsonb_array_length(coalesce(capabilities -> 'speakers', '[]'::jsonb)) > 1",
+}
+
+// empty fields don't filter; all given capabilities must be present
+type ModelQuery struct {
+	Language     string
+	Tag          string
+	Gender       VoiceGender
+	VoiceType    VoiceType
+	Capabilities []ModelCapability
+}
+
+func (capability ModelCapability) Condition() (string, bool) {
+	condition, ok := capabilityConditions[capability]
+	return condition, ok
+}
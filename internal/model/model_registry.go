@@ -0,0 +1,237 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+// RegistryDocument is the declarative form of the model registry. Models are
+// keyed by name and language; the revision identifies the registry state the
+// document was exported from.
+//
+// Only the model settings and their live connection are covered. Replicas,
+// versions and language rules are managed through their own endpoints: they
+// are neither exported nor accepted on import, and an import leaves them as
+// they are. Pruning a model that a language rule points to is rejected.
+type RegistryDocument struct {
+	Revision string                  `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Models   []requests.ModelRequest `json:"models" yaml:"models"`
+}
+
+type RegistryAction string
+
+const (
+	RegistryCreate  RegistryAction = "create"
+	RegistryUpdate  RegistryAction = "update"
+	RegistryRestore RegistryAction = "restore"
+	RegistryDelete  RegistryAction = "delete"
+)
+
+type RegistryChange struct {
+	Action   RegistryAction `json:"action"`
+	Id       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Language string         `json:"language"`
+	Fields   []string       `json:"fields,omitempty"`
+	// connection changes are staged as an inactive version, not applied
+	Staged bool `json:"staged,omitempty"`
+}
+
+type RegistryDiff struct {
+	DryRun   bool             `json:"dry_run"`
+	Revision string           `json:"revision"`
+	Changes  []RegistryChange `json:"changes"`
+}
+
+type RegistryImportOptions struct {
+	DryRun bool
+	// models missing from the document are archived only when pruning
+	Prune bool
+	// imports without a revision are rejected unless forced
+	RequireRevision bool
+}
+
+func registryKey(name, language string) string {
+	return name + "|" + language
+}
+
+func toModelRequest(model *Model) requests.ModelRequest {
+	return requests.ModelRequest{
+		Url:              model.Url,
+		Name:             model.Name,
+		Language:         model.Language,
+		Description:      model.Description,
+		Gender:           string(model.Gender),
+		VoiceType:        string(model.VoiceType),
+		Tags:             model.Tags,
+		NativeSampleRate: model.NativeSampleRate,
+		TimeoutMs:        model.TimeoutMs,
+		LoadBalancing:    string(model.LoadBalancing),
+		Protocol:         string(model.Protocol),
+		UpstreamModel:    model.UpstreamModel,
+		MaxInputLength:   model.MaxInputLength,
+		Capabilities:     toCapabilitiesRequest(&model.Capabilities),
+	}
+}
+
+func toCapabilitiesRequest(capabilities *ModelCapabilities) *requests.ModelCapabilitiesRequest {
+	toRange := func(r *ParameterRange) *requests.ParameterRangeRequest {
+		if r == nil {
+			return nil
+		}
+		return &requests.ParameterRangeRequest{Min: r.Min, Max: r.Max}
+	}
+
+	req := &requests.ModelCapabilitiesRequest{
+		Rate:     toRange(capabilities.Rate),
+		Pitch:    toRange(capabilities.Pitch),
+		Volume:   toRange(capabilities.Volume),
+		Speakers: capabilities.Speakers,
+		Styles:   capabilities.Styles,
+	}
+
+	if req.Rate == nil && req.Pitch == nil && req.Volume == nil && len(req.Speakers) == 0 && len(req.Styles) == 0 {
+		return nil
+	}
+
+	return req
+}
+
+// registryModel builds the model a registry entry describes. The entry is
+// declarative, so fields it leaves empty are empty on the model too.
+func registryModel(req *requests.ModelRequest) (*Model, error) {
+	capabilities, err := ToModelCapabilities(req.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &Model{
+		Url:              req.Url,
+		Name:             req.Name,
+		Language:         req.Language,
+		Description:      req.Description,
+		Gender:           VoiceGender(req.Gender),
+		VoiceType:        VoiceType(req.VoiceType),
+		Tags:             normalizeTags(req.Tags),
+		NativeSampleRate: req.NativeSampleRate,
+		TimeoutMs:        req.TimeoutMs,
+		LoadBalancing:    LoadBalancingStrategy(req.LoadBalancing),
+		Protocol:         ModelProtocol(req.Protocol),
+		UpstreamModel:    req.UpstreamModel,
+		MaxInputLength:   req.MaxInputLength,
+		Capabilities:     capabilities,
+	}
+
+	if model.LoadBalancing == "" {
+		model.LoadBalancing = RoundRobin
+	}
+
+	if model.Protocol == "" {
+		model.Protocol = ProtocolJson
+	}
+
+	if model.Gender != "" && !model.Gender.Valid() {
+		return nil, fmt.Errorf("unsupported voice gender: %s", req.Gender)
+	}
+
+	if model.VoiceType != "" && !model.VoiceType.Valid() {
+		return nil, fmt.Errorf("unsupported voice type: %s", req.VoiceType)
+	}
+
+	if model.LoadBalancing != RoundRobin && model.LoadBalancing != LeastOutstanding {
+		return nil, fmt.Errorf("unsupported load balancing strategy: %s", req.LoadBalancing)
+	}
+
+	return model, nil
+}
+
+// diffModel lists the fields that differ between the model and the registry
+// entry, including fields the entry clears.
+func diffModel(model *Model, req *requests.ModelRequest) ([]string, error) {
+	desired, err := registryModel(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	changed := func(field string, differs bool) {
+		if differs {
+			fields = append(fields, field)
+		}
+	}
+
+	// rows created before these settings existed have them empty
+	protocol, loadBalancing := model.Protocol, model.LoadBalancing
+	if protocol == "" {
+		protocol = ProtocolJson
+	}
+	if loadBalancing == "" {
+		loadBalancing = RoundRobin
+	}
+
+	// a connection already staged as a version waits for its rollout, importing
+	// it again is not a change
+	if model.stagedVersion(desired.Url, desired.Protocol, desired.UpstreamModel) == nil {
+		changed("url", desired.Url != model.Url)
+		changed("protocol", desired.Protocol != protocol)
+		changed("upstreamModel", desired.UpstreamModel != model.UpstreamModel)
+	}
+	changed("description", desired.Description != model.Description)
+	changed("gender", desired.Gender != model.Gender)
+	changed("voiceType", desired.VoiceType != model.VoiceType)
+	changed("tags", !slices.Equal(desired.Tags, model.Tags))
+	changed("nativeSampleRate", desired.NativeSampleRate != model.NativeSampleRate)
+	changed("timeoutMs", desired.TimeoutMs != model.TimeoutMs)
+	changed("loadBalancing", desired.LoadBalancing != loadBalancing)
+	changed("maxInputLength", desired.MaxInputLength != model.MaxInputLength)
+
+	current, _ := json.Marshal(model.Capabilities)
+	target, _ := json.Marshal(desired.Capabilities)
+	changed("capabilities", string(current) != string(target))
+
+	return fields, nil
+}
+
+// stagesConnection tells whether the changed fields touch the connection, which
+// the import stages as a version instead of applying
+func stagesConnection(fields []string) bool {
+	return slices.Contains(fields, "url") || slices.Contains(fields, "protocol") || slices.Contains(fields, "upstreamModel")
+}
+
+// applyRegistryModel copies the desired state onto the stored model. Connection
+// changes aren't applied directly, they come back as a version to stage.
+func applyRegistryModel(model *Model, desired *Model) *ModelVersion {
+	model.Description = desired.Description
+	model.Gender = desired.Gender
+	model.VoiceType = desired.VoiceType
+	model.Tags = desired.Tags
+	model.NativeSampleRate = desired.NativeSampleRate
+	model.TimeoutMs = desired.TimeoutMs
+	model.LoadBalancing = desired.LoadBalancing
+	model.MaxInputLength = desired.MaxInputLength
+	model.Capabilities = desired.Capabilities
+
+	if desired.Url == model.Url && desired.Protocol == model.Protocol && desired.UpstreamModel == model.UpstreamModel {
+		return nil
+	}
+
+	return &ModelVersion{Url: desired.Url, Protocol: desired.Protocol, UpstreamModel: desired.UpstreamModel, Notes: "registry sync"}
+}
+
+// the revision is a digest of the exported models, so any change that shows
+// up in an export also changes the revision
+func registryRevision(models []requests.ModelRequest) string {
+	sorted := slices.Clone(models)
+	slices.SortFunc(sorted, func(a, b requests.ModelRequest) int {
+		return strings.Compare(registryKey(a.Name, a.Language), registryKey(b.Name, b.Language))
+	})
+
+	data, _ := json.Marshal(sorted)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/validation"
+)
+
+func TestPlanRegistryChanges(t *testing.T) {
+	active := []Model{
+		{Id: "1", Name: "anna", Language: "en", Url: "http://anna", Protocol: ProtocolJson, TimeoutMs: 1000},
+		{Id: "2", Name: "boris", Language: "uk", Url: "http://boris", Protocol: ProtocolJson},
+		{Id: "3", Name: "clara", Language: "de", Url: "http://clara", Protocol: ProtocolJson},
+	}
+	archived := []Model{{Id: "4", Name: "dmytro", Language: "uk", Url: "http://dmytro", Protocol: ProtocolJson}}
+
+	document := &RegistryDocument{Models: []requests.ModelRequest{
+		{Name: "anna", Language: "en", Url: "http://anna-v2", TimeoutMs: 1000},
+		{Name: "boris", Language: "uk", Url: "http://boris"},
+		{Name: "dmytro", Language: "uk", Url: "http://dmytro"},
+		{Name: "eva", Language: "en", Url: "http://eva"},
+	}}
+
+	changes, err := planRegistryChanges(document, active, archived, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []RegistryChange{
+		{Action: RegistryUpdate, Id: "1", Name: "anna", Language: "en", Fields: []string{"url"}},
+		{Action: RegistryRestore, Id: "4", Name: "dmytro", Language: "uk"},
+		{Action: RegistryCreate, Name: "eva", Language: "en"},
+		{Action: RegistryDelete, Id: "3", Name: "clara", Language: "de"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.Action != expected[i].Action || change.Id != expected[i].Id || change.Name != expected[i].Name || len(change.Fields) != len(expected[i].Fields) {
+			t.Errorf("change %d: expected %+v, got %+v", i, expected[i], change)
+		}
+	}
+
+	changes, err = planRegistryChanges(document, active, archived, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range changes {
+		if change.Action == RegistryDelete {
+			t.Errorf("unexpected delete without pruning: %+v", change)
+		}
+	}
+}
+
+func TestRegistryRoundTrip(t *testing.T) {
+	models := []Model{{
+		Name:         "anna",
+		Language:     "en",
+		Url:          "http://anna",
+		Protocol:     ProtocolOpenAi,
+		Tags:         []string{"calm"},
+		Capabilities: ModelCapabilities{Rate: &ParameterRange{Min: 0.5, Max: 2}, Speakers: []string{"a", "b"}},
+	}}
+	document := exportDocument(models)
+
+	for _, format := range []RegistryFormat{RegistryJson, RegistryYaml} {
+		data, err := EncodeRegistry(document, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := DecodeRegistry(data, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if decoded.Revision != document.Revision || registryRevision(decoded.Models) != document.Revision {
+			t.Errorf("%s: revision changed after round trip", format)
+		}
+
+		fields, err := diffModel(&models[0], &decoded.Models[0])
+		if err != nil || len(fields) != 0 {
+			t.Errorf("%s: expected no changes after round trip, got %v (%v)", format, fields, err)
+		}
+	}
+
+	if _, err := DecodeRegistry([]byte("models:\n  - name: anna\n    langauge: en\n"), RegistryYaml); err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+}
+
+func TestRegistryClearsOmittedFields(t *testing.T) {
+	model := &Model{
+		Name:          "anna",
+		Language:      "en",
+		Url:           "http://anna",
+		Protocol:      ProtocolJson,
+		LoadBalancing: RoundRobin,
+		Description:   "Warm narrator",
+		Tags:          []string{"calm"},
+		TimeoutMs:     1500,
+		Capabilities:  ModelCapabilities{Speakers: []string{"a"}},
+	}
+	req := &requests.ModelRequest{Name: "anna", Language: "en", Url: "http://anna"}
+
+	fields, err := diffModel(model, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fields, []string{"description", "tags", "timeoutMs", "capabilities"}) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	desired, _ := registryModel(req)
+	if version := applyRegistryModel(model, desired); version != nil {
+		t.Errorf("unexpected version %+v", version)
+	}
+	if model.Description != "" || len(model.Tags) != 0 || model.TimeoutMs != 0 || len(model.Capabilities.Speakers) != 0 {
+		t.Errorf("expected fields to be cleared, got %+v", model)
+	}
+
+	req.Url = "http://anna-v2"
+	if version := applyRegistryModel(model, desired); version != nil {
+		t.Errorf("desired state was built before the url change, got %+v", version)
+	}
+	desired, _ = registryModel(req)
+	if version := applyRegistryModel(model, desired); version == nil || version.Url != "http://anna-v2" || model.Url != "http://anna" {
+		t.Errorf("expected url change as a new version, got %+v", version)
+	}
+}
+
+func TestRegistryModelValidation(t *testing.T) {
+	for _, req := range []requests.ModelRequest{
+		{Name: "anna", Language: "en", Url: "http://anna", Gender: "robot"},
+		{Name: "anna", Language: "en", Url: "http://anna", VoiceType: "vinyl"},
+		{Name: "anna", Language: "en", Url: "http://anna", LoadBalancing: "random"},
+	} {
+		if _, err := registryModel(&req); err == nil {
+			t.Errorf("expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestRegistryRejectsUnmanagedSettings(t *testing.T) {
+	cases := []struct {
+		name   string
+		format RegistryFormat
+		data   string
+	}{
+		{name: "replicas", format: RegistryJson, data: `{"models":[{"name":"anna","language":"en","url":"http://anna","replicas":[{"url":"http://replica"}]}]}`},
+		{name: "versions", format: RegistryYaml, data: "models:\n  - name: anna\n    language: en\n    url: http://anna\n    versions: []\n"},
+		{name: "language rules", format: RegistryJson, data: `{"models":[],"language_rules":[{"language":"en","model_id":"1"}]}`},
+	}
+
+	for _, c := range cases {
+		if _, err := DecodeRegistry([]byte(c.data), c.format); err == nil {
+			t.Errorf("%s: expected the document to be rejected", c.name)
+		}
+	}
+}
+
+func TestRegistryImportKeepsUnmanagedSettings(t *testing.T) {
+	anna := &Model{Id: "1", Name: "anna", Language: "en", Url: "http://anna", Protocol: ProtocolJson, LoadBalancing: RoundRobin, Replicas: []ModelReplica{{Id: "r1", Url: "http://replica"}}}
+	boris := &Model{Id: "2", Name: "boris", Language: "uk", Url: "http://boris", Protocol: ProtocolJson, LoadBalancing: RoundRobin}
+	repository := newMemoryModelRepository(anna, boris)
+	repository.rules = []ModelLanguageRule{{Language: "uk", ModelId: "2"}}
+	service := NewRegistryService(repository, validation.NewValidationService())
+
+	document := &RegistryDocument{Models: []requests.ModelRequest{{Name: "anna", Language: "en", Url: "http://anna", TimeoutMs: 500}}}
+
+	// boris is the default for Ukrainian, pruning it would leave the rule dangling
+	_, err := service.Import(document, RegistryImportOptions{Prune: true})
+	var badRequest *service_errors.ErrBadRequest
+	if !errors.As(err, &badRequest) {
+		t.Fatalf("expected a bad request, got %v", err)
+	}
+	if boris.ArchivedAt != nil || anna.TimeoutMs != 0 {
+		t.Fatal("rejected import should not change the registry")
+	}
+
+	if _, err := service.Import(document, RegistryImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if anna.TimeoutMs != 500 || len(anna.Replicas) != 1 {
+		t.Errorf("expected the update to keep replicas, got timeout %d and %+v", anna.TimeoutMs, anna.Replicas)
+	}
+}
+
+func TestRegistryCreateReportsAssignedId(t *testing.T) {
+	repository := newMemoryModelRepository()
+	req := &requests.ModelRequest{Name: "anna", Language: "en", Url: "http://anna"}
+
+	id, err := applyRegistryChange(repository, RegistryChange{Action: RegistryCreate, Name: "anna", Language: "en"}, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model, ok := repository.models[id]
+	if id == "" || !ok {
+		t.Fatalf("expected the id assigned on create, got %q", id)
+	}
+	if model.ActiveVersionId != "v1" || model.Url != "http://anna" {
+		t.Errorf("expected the first version to be active, got %+v", model)
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ModelReplica struct {
+	Id        string    `gorm:"type:varchar(256);primaryKey;"`
+	ModelId   string    `gorm:"type:varchar(256);not null;index"`
+	VersionId string    `gorm:"type:varchar(256);index"`
+	Url       string    `gorm:"type:varchar(256);not null"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (replica *ModelReplica) BeforeCreate(tx *gorm.DB) (err error) {
+	replica.Id = uuid.NewString()
+	return
+}
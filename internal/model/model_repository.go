@@ -1,15 +1,29 @@
 package model
 
 import (
+	"encoding/json"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ModelRepository interface {
+	Transaction(fn func(repository ModelRepository) error) error
 	Save(model *Model) error
+	CreateWithVersion(model *Model, version *ModelVersion) error
+	SaveVersion(model *Model, version *ModelVersion, activate bool) error
 	FindById(id string) (*Model, error)
 	FindByNameAndLanguage(name, language string) (*Model, error)
 	FindAll() ([]Model, error)
+	FindArchived() ([]Model, error)
+	FindByQuery(query *ModelQuery) ([]Model, error)
 	DeleteById(id string) error
+	SaveReplica(replica *ModelReplica) error
+	DeleteReplica(modelId, replicaId string) (bool, error)
+	FindLanguageRules() ([]ModelLanguageRule, error)
+	FindLanguageRule(language string) (*ModelLanguageRule, error)
+	SaveLanguageRule(rule *ModelLanguageRule) error
+	DeleteLanguageRule(language string) (bool, error)
 }
 
 type ModelRepositoryImpl struct {
@@ -20,14 +34,71 @@ func NewModelRepository(db *gorm.DB) *ModelRepositoryImpl {
 	return &ModelRepositoryImpl{db: db}
 }
 
+func (r *ModelRepositoryImpl) Transaction(fn func(repository ModelRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&ModelRepositoryImpl{db: tx})
+	})
+}
+
+// associations are written through their own methods; saving them along with
+// the model would re-run their create hooks and duplicate rows
 func (r *ModelRepositoryImpl) Save(model *Model) error {
-	return r.db.Save(model).Error
+	return r.db.Omit(clause.Associations).Save(model).Error
+}
+
+func (r *ModelRepositoryImpl) CreateWithVersion(model *Model, version *ModelVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(model).Error; err != nil {
+			return err
+		}
+
+		version.ModelId = model.Id
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		model.Versions = append(model.Versions, *version)
+		model.activate(version)
+		return tx.Omit(clause.Associations).Save(model).Error
+	})
+}
+
+func (r *ModelRepositoryImpl) SaveVersion(model *Model, version *ModelVersion, activate bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		version.ModelId = model.Id
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		// replicas added before versioning belong to the first version
+		if version.Number == 1 {
+			err := tx.Model(&ModelReplica{}).
+				Where("model_id = ? AND (version_id IS NULL OR version_id = '')", model.Id).
+				Update("version_id", version.Id).Error
+			if err != nil {
+				return err
+			}
+
+			for i := range model.Replicas {
+				if model.Replicas[i].VersionId == "" {
+					model.Replicas[i].VersionId = version.Id
+				}
+			}
+		}
+
+		model.Versions = append(model.Versions, *version)
+		if activate {
+			model.activate(version)
+		}
+
+		return tx.Omit(clause.Associations).Save(model).Error
+	})
 }
 
 func (r *ModelRepositoryImpl) FindById(id string) (*Model, error) {
 	var model Model
 
-	if err := r.db.First(&model, "id = ?", id).Error; err != nil {
+	if err := r.preload().First(&model, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
@@ -47,7 +118,7 @@ func (r *ModelRepositoryImpl) FindByNameAndLanguage(name, language string) (*Mod
 func (r *ModelRepositoryImpl) FindAll() ([]Model, error) {
 	var models []Model
 
-	result := r.db.Find(&models)
+	result := r.preload().Where("archived_at IS NULL").Find(&models)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -55,6 +126,98 @@ func (r *ModelRepositoryImpl) FindAll() ([]Model, error) {
 	return models, nil
 }
 
+func (r *ModelRepositoryImpl) FindArchived() ([]Model, error) {
+	var models []Model
+
+	if err := r.preload().Where("archived_at IS NOT NULL").Order("archived_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (r *ModelRepositoryImpl) FindByQuery(query *ModelQuery) ([]Model, error) {
+	var models []Model
+
+	db := r.preload().Where("archived_at IS NULL")
+	if query.Language != "" {
+		db = db.Where("language = ?", query.Language)
+	}
+
+	if query.Gender != "" {
+		db = db.Where("gender = ?", query.Gender)
+	}
+
+	if query.VoiceType != "" {
+		db = db.Where("voice_type = ?", query.VoiceType)
+	}
+
+	if query.Tag != "" {
+		tags, err := json.Marshal([]string{query.Tag})
+		if err != nil {
+			return nil, err
+		}
+
+		db = db.Where("tags @> ?::jsonb", string(tags))
+	}
+
+	for _, capability := range query.Capabilities {
+		if condition, ok := capability.Condition(); ok {
+			db = db.Where(condition)
+		}
+	}
+
+	if err := db.Order("name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
 func (r *ModelRepositoryImpl) DeleteById(id string) error {
 	return r.db.Delete(&Model{}, "id = ?", id).Error
 }
+
+func (r *ModelRepositoryImpl) SaveReplica(replica *ModelReplica) error {
+	return r.db.Save(replica).Error
+}
+
+func (r *ModelRepositoryImpl) DeleteReplica(modelId, replicaId string) (bool, error) {
+	result := r.db.Delete(&ModelReplica{}, "id = ? AND model_id = ?", replicaId, modelId)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ModelRepositoryImpl) FindLanguageRules() ([]ModelLanguageRule, error) {
+	var rules []ModelLanguageRule
+
+	if err := r.db.Order("language").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *ModelRepositoryImpl) FindLanguageRule(language string) (*ModelLanguageRule, error) {
+	var rule ModelLanguageRule
+
+	if err := r.db.First(&rule, "language = ?", language).Error; err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *ModelRepositoryImpl) SaveLanguageRule(rule *ModelLanguageRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *ModelRepositoryImpl) DeleteLanguageRule(language string) (bool, error) {
+	result := r.db.Delete(&ModelLanguageRule{}, "language = ?", language)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ModelRepositoryImpl) preload() *gorm.DB {
+	return r.db.Preload("Replicas").Preload("Versions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number")
+	})
+}
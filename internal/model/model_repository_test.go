@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunRepository builds queries without a database and records those that
+// select models
+func dryRunRepository(t *testing.T) (*ModelRepositoryImpl, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{}
+	err = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		if tx.Statement.Table == "models" {
+			statements = append(statements, fmt.Sprintf("%s %v", tx.Statement.SQL.String(), tx.Statement.Vars))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewModelRepository(db), &statements
+}
+
+func TestFindByQueryBuildsFilters(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    ModelQuery
+		expected []string
+		absent   []string
+	}{
+		{
+			name:     "no filters",
+			query:    ModelQuery{},
+			expected: []string{"WHERE archived_at IS NULL ORDER BY name []"},
+			absent:   []string{"language", "gender", "tags", "capabilities"},
+		},
+		{
+			name:     "language and gender",
+			query:    ModelQuery{Language: "uk", Gender: GenderFemale},
+			expected: []string{"language = $1", "gender = $2", "[uk female]"},
+			absent:   []string{"voice_type", "tags"},
+		},
+		{
+			name:     "voice type",
+			query:    ModelQuery{VoiceType: VoiceNeural},
+			expected: []string{"voice_type = $1", "[neural]"},
+		},
+		{
+			name:     "tag",
+			query:    ModelQuery{Tag: `calm "news"`},
+			expected: []string{"tags @> $1::jsonb", `[["calm \"news\""]]`},
+		},
+		{
+			name:     "capabilities",
+			query:    ModelQuery{Capabilities: []ModelCapability{CapabilityRate, CapabilityMultiSpeaker}},
+			expected: []string{capabilityConditions[CapabilityRate], capabilityConditions[CapabilityMultiSpeaker]},
+			absent:   []string{capabilityConditions[CapabilityPitch], capabilityConditions[CapabilityStyles]},
+		},
+		{
+			name:     "unknown capability",
+			query:    ModelQuery{Capabilities: []ModelCapability{"singing"}},
+			expected: []string{"WHERE archived_at IS NULL ORDER BY name"},
+			absent:   []string{"singing", "capabilities"},
+		},
+		{
+			name:     "combined",
+			query:    ModelQuery{Language: "en", Gender: GenderMale, Tag: "calm", Capabilities: []ModelCapability{CapabilityStyles}},
+			expected: []string{"language = $1 AND gender = $2 AND tags @> $3::jsonb AND " + capabilityConditions[CapabilityStyles], `[en male ["calm"]]`},
+		},
+	}
+
+	for _, c := range cases {
+		repository, statements := dryRunRepository(t)
+
+		if _, err := repository.FindByQuery(&c.query); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+
+		if len(*statements) != 1 {
+			t.Errorf("%s: expected one query, got %v", c.name, *statements)
+			continue
+		}
+
+		statement := (*statements)[0]
+		for _, fragment := range c.expected {
+			if !strings.Contains(statement, fragment) {
+				t.Errorf("%s: expected %q in %s", c.name, fragment, statement)
+			}
+		}
+		for _, fragment := range c.absent {
+			if strings.Contains(statement, fragment) {
+				t.Errorf("%s: unexpected %q in %s", c.name, fragment, statement)
+			}
+		}
+	}
+}
@@ -2,28 +2,45 @@ package model
 
 import (
 	"errors"
+	"fmt"
+	"time"
 	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/storage"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type ModelService interface {
 	SaveModel(req *requests.ModelRequest) (*ModelDto, error)
 	UpdateModel(id string, req *requests.ModelRequest) (*ModelDto, error)
-	DeleteModel(id string) error
+	ArchiveModel(id string) error
+	RestoreModel(id string) (*ModelDto, error)
+	PurgeModel(id string) error
+	GetArchivedModels() ([]ModelDto, error)
 	GetModelById(modelId string) (*ModelDto, error)
-	GetModels() ([]ModelDto, error)
+	GetModels(query *ModelQuery) ([]ModelDto, error)
+	SaveModelPreview(id string, preview *ModelPreview) (*ModelDto, error)
+	GetModelPreview(id string) (*ModelPreview, error)
+	GetLanguageRules() ([]ModelLanguageRuleDto, error)
+	SetLanguageRule(language string, req *requests.LanguageRuleRequest) (*ModelLanguageRuleDto, error)
+	DeleteLanguageRule(language string) error
+	GetModelForLanguage(language string) (*ModelDto, error)
+	GetLanguages() ([]string, error)
 }
 
 type ModelServiceImpl struct {
-	repository ModelRepository
+	repository  ModelRepository
+	blobStorage storage.BlobStorage
+	balancer    ReplicaBalancer
+	listeners   []ModelChangeListener
 }
 
-func NewModelService(repo ModelRepository) *ModelServiceImpl {
+func NewModelService(repo ModelRepository, blobStorage storage.BlobStorage, balancer ReplicaBalancer, listeners ...ModelChangeListener) *ModelServiceImpl {
 
-	return &ModelServiceImpl{repository: repo}
+	return &ModelServiceImpl{repository: repo, blobStorage: blobStorage, balancer: balancer, listeners: listeners}
 }
 
 func (s *ModelServiceImpl) SaveModel(req *requests.ModelRequest) (*ModelDto, error) {
@@ -35,18 +52,50 @@ func (s *ModelServiceImpl) SaveModel(req *requests.ModelRequest) (*ModelDto, err
 		return nil, service_errors.NewErrInternalServer("Failed to model by name and language")
 	}
 
+	if existing != nil && existing.ArchivedAt != nil {
+		logger.Logger.Error("Archived model with given name and language exists", "name", req.Name, "language", req.Language)
+		return nil, service_errors.NewErrBadRequest("An archived model with this name and language exists, restore or purge it first")
+	}
+
 	if existing != nil {
 		logger.Logger.Error("Model with given name and language already exists", "name", req.Name, "language", req.Language)
 		return nil, service_errors.NewErrBadRequest("Model with this name and language already exists")
 	}
 
+	capabilities, err := ToModelCapabilities(req.Capabilities)
+	if err != nil {
+		logger.Logger.Error("Invalid model capabilities", "name", req.Name, "error", err)
+		return nil, service_errors.NewErrBadRequest(err.Error())
+	}
+
 	model := &Model{
-		Url:      req.Url,
-		Name:     req.Name,
-		Language: req.Language,
+		Url:              req.Url,
+		Name:             req.Name,
+		Language:         req.Language,
+		Description:      req.Description,
+		Gender:           VoiceGender(req.Gender),
+		VoiceType:        VoiceType(req.VoiceType),
+		Tags:             normalizeTags(req.Tags),
+		NativeSampleRate: req.NativeSampleRate,
+		TimeoutMs:        req.TimeoutMs,
+		LoadBalancing:    LoadBalancingStrategy(req.LoadBalancing),
+		Protocol:         ModelProtocol(req.Protocol),
+		UpstreamModel:    req.UpstreamModel,
+		MaxInputLength:   req.MaxInputLength,
+		Capabilities:     capabilities,
 	}
 
-	err = s.repository.Save(model)
+	if model.LoadBalancing == "" {
+		model.LoadBalancing = RoundRobin
+	}
+
+	if model.Protocol == "" {
+		model.Protocol = ProtocolJson
+	}
+
+	version := &ModelVersion{Number: 1, Url: model.Url, Protocol: model.Protocol, UpstreamModel: model.UpstreamModel}
+
+	err = s.repository.CreateWithVersion(model, version)
 	if err != nil {
 		logger.Logger.Error("Failed to save model", "name", model.Name, "language", "model.Language")
 		return nil, service_errors.NewErrInternalServer("Failed to save model")
@@ -81,10 +130,6 @@ func (s *ModelServiceImpl) UpdateModel(id string, req *requests.ModelRequest) (*
 		return nil, service_errors.NewErrBadRequest("Model with this name and language already exists")
 	}
 
-	if req.Url != "" {
-		model.Url = req.Url
-	}
-
 	if req.Name != "" {
 		model.Name = req.Name
 	}
@@ -93,28 +138,155 @@ func (s *ModelServiceImpl) UpdateModel(id string, req *requests.ModelRequest) (*
 		model.Language = req.Language
 	}
 
-	err = s.repository.Save(model)
+	if req.TimeoutMs != 0 {
+		model.TimeoutMs = req.TimeoutMs
+	}
+
+	if req.MaxInputLength != 0 {
+		model.MaxInputLength = req.MaxInputLength
+	}
+
+	if req.Description != "" {
+		model.Description = req.Description
+	}
+
+	if req.Gender != "" {
+		gender := VoiceGender(req.Gender)
+		if !gender.Valid() {
+			logger.Logger.Error("Unsupported voice gender", "gender", req.Gender)
+			return nil, service_errors.NewErrBadRequest("Unsupported voice gender")
+		}
+
+		model.Gender = gender
+	}
+
+	if req.VoiceType != "" {
+		voiceType := VoiceType(req.VoiceType)
+		if !voiceType.Valid() {
+			logger.Logger.Error("Unsupported voice type", "voiceType", req.VoiceType)
+			return nil, service_errors.NewErrBadRequest("Unsupported voice type")
+		}
+
+		model.VoiceType = voiceType
+	}
+
+	if req.Tags != nil {
+		model.Tags = normalizeTags(req.Tags)
+	}
+
+	if req.NativeSampleRate != 0 {
+		model.NativeSampleRate = req.NativeSampleRate
+	}
+
+	if req.LoadBalancing != "" {
+		strategy := LoadBalancingStrategy(req.LoadBalancing)
+		if strategy != RoundRobin && strategy != LeastOutstanding {
+			logger.Logger.Error("Unsupported load balancing strategy", "strategy", req.LoadBalancing)
+			return nil, service_errors.NewErrBadRequest("Unsupported load balancing strategy")
+		}
+
+		model.LoadBalancing = strategy
+	}
+
+	if req.Capabilities != nil {
+		capabilities, err := ToModelCapabilities(req.Capabilities)
+		if err != nil {
+			logger.Logger.Error("Invalid model capabilities", "id", id, "error", err)
+			return nil, service_errors.NewErrBadRequest(err.Error())
+		}
+
+		model.Capabilities = capabilities
+	}
+
+	// connection changes don't go live here, they are staged as an inactive
+	// version to be rolled out through activation or a canary
+	var stagedVersionId string
+	if version := nextVersion(model, req); version != nil {
+		version.Notes = "staged by model update"
+		stagedVersionId, err = stageVersion(s.repository, model, version)
+	} else {
+		err = s.repository.Save(model)
+	}
+
 	if err != nil {
-		logger.Logger.Error("Failed to update model", "id", model.Id)
+		logger.Logger.Error("Failed to update model", "id", model.Id, "error", err)
 		return nil, service_errors.NewErrInternalServer("Failed to update model")
 	}
 
-	logger.Logger.Info("Updated model.", "id", model.Id, "url", model.Url, "name", model.Name, "language", model.Language)
+	s.notifyModelChanged(model.Id)
+
+	logger.Logger.Info("Updated model.", "id", model.Id, "url", model.Url, "name", model.Name, "language", model.Language, "stagedVersionId", stagedVersionId)
+	dto := ToModelDto(model)
+	dto.StagedVersionId = stagedVersionId
+	return dto, nil
+}
+
+// ArchiveModel hides the model from listings and stops synthesis against it.
+// The row is kept so history records and clients can still resolve it.
+func (s *ModelServiceImpl) ArchiveModel(id string) error {
+	logger.Logger.Info("Archiving model...", "id", id)
+
+	model, err := s.findModel(id)
+	if err != nil {
+		return err
+	}
+
+	if model.ArchivedAt != nil {
+		logger.Logger.Error("Model is already archived", "id", id)
+		return service_errors.NewErrBadRequest("Model is already archived")
+	}
+
+	now := time.Now()
+	model.ArchivedAt = &now
+	if err := s.repository.Save(model); err != nil {
+		logger.Logger.Error("Failed to archive model", "id", id)
+		return service_errors.NewErrInternalServer("Failed to archive model")
+	}
+
+	s.notifyModelChanged(model.Id)
+
+	logger.Logger.Info("Archived model.", "id", id)
+	return nil
+}
+
+func (s *ModelServiceImpl) RestoreModel(id string) (*ModelDto, error) {
+	logger.Logger.Info("Restoring model...", "id", id)
+
+	model, err := s.findModel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.ArchivedAt == nil {
+		logger.Logger.Error("Model is not archived", "id", id)
+		return nil, service_errors.NewErrBadRequest("Model is not archived")
+	}
+
+	model.ArchivedAt = nil
+	if err := s.repository.Save(model); err != nil {
+		logger.Logger.Error("Failed to restore model", "id", id)
+		return nil, service_errors.NewErrInternalServer("Failed to restore model")
+	}
+
+	s.notifyModelChanged(model.Id)
+
+	logger.Logger.Info("Restored model.", "id", id)
 	return ToModelDto(model), nil
 }
 
-func (s *ModelServiceImpl) DeleteModel(id string) error {
-	logger.Logger.Info("Deleting model...", "id", id)
+// PurgeModel permanently deletes an archived model with its versions,
+// replicas and preview.
+func (s *ModelServiceImpl) PurgeModel(id string) error {
+	logger.Logger.Info("Purging model...", "id", id)
 
-	model, err := s.repository.FindById(id)
+	model, err := s.findModel(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Logger.Error("Model not found", "id", id)
-			return service_errors.NewErrNotFound("Model not found")
-		}
+		return err
+	}
 
-		logger.Logger.Error("Failed to fetch model", "id", id)
-		return service_errors.NewErrInternalServer("Failed to fetch model by Id")
+	if model.ArchivedAt == nil {
+		logger.Logger.Error("Model must be archived before purging", "id", id)
+		return service_errors.NewErrBadRequest("Model must be archived before it can be purged")
 	}
 
 	err = s.repository.DeleteById(model.Id)
@@ -123,10 +295,32 @@ func (s *ModelServiceImpl) DeleteModel(id string) error {
 		return service_errors.NewErrInternalServer("Failed to delete model")
 	}
 
-	logger.Logger.Info("Deleted model.", "id", id)
+	s.deletePreview(model.PreviewKey)
+
+	s.notifyModelChanged(model.Id)
+
+	logger.Logger.Info("Purged model.", "id", id)
 	return nil
 }
 
+func (s *ModelServiceImpl) GetArchivedModels() ([]ModelDto, error) {
+	logger.Logger.Info("Fetching archived models...")
+
+	records, err := s.repository.FindArchived()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch archived models")
+		return nil, service_errors.NewErrInternalServer("Failed to fetch archived models")
+	}
+
+	dtos := make([]ModelDto, len(records))
+	for i, record := range records {
+		dtos[i] = *ToModelDto(&record)
+	}
+
+	logger.Logger.Info("Fetched archived models", "size", len(dtos))
+	return dtos, nil
+}
+
 func (s *ModelServiceImpl) GetModelById(modelId string) (*ModelDto, error) {
 	logger.Logger.Info("Fetching model...", "modelId", modelId)
 
@@ -142,13 +336,13 @@ func (s *ModelServiceImpl) GetModelById(modelId string) (*ModelDto, error) {
 	}
 
 	logger.Logger.Info("Fetched model", "modelId", modelId)
-	return ToModelDto(model), nil
+	return s.withHealth(ToModelDto(model)), nil
 }
 
-func (s *ModelServiceImpl) GetModels() ([]ModelDto, error) {
-	logger.Logger.Info("Fetching models...")
+func (s *ModelServiceImpl) GetModels(query *ModelQuery) ([]ModelDto, error) {
+	logger.Logger.Info("Fetching models...", "language", query.Language, "tag", query.Tag, "capabilities", query.Capabilities)
 
-	records, err := s.repository.FindAll()
+	records, err := s.repository.FindByQuery(query)
 	if err != nil {
 		logger.Logger.Error("Failed to fetch models")
 		return nil, service_errors.NewErrInternalServer("Failed to fetch models")
@@ -156,9 +350,130 @@ func (s *ModelServiceImpl) GetModels() ([]ModelDto, error) {
 
 	dtos := make([]ModelDto, len(records))
 	for i, record := range records {
-		dtos[i] = *ToModelDto(&record)
+		dtos[i] = *s.withHealth(ToModelDto(&record))
 	}
 
 	logger.Logger.Info("Fetched models", "size", len(dtos))
 	return dtos, nil
 }
+
+func (s *ModelServiceImpl) SaveModelPreview(id string, preview *ModelPreview) (*ModelDto, error) {
+	logger.Logger.Info("Saving model preview...", "id", id, "contentType", preview.ContentType, "size", len(preview.Data))
+
+	model, err := s.repository.FindById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Model not found", "id", id)
+			return nil, service_errors.NewErrNotFound("Model not found")
+		}
+
+		logger.Logger.Error("Failed to fetch model", "id", id)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch model")
+	}
+
+	previousKey := model.PreviewKey
+	model.PreviewKey = fmt.Sprintf("models/%s/preview-%s.%s", model.Id, uuid.NewString(), preview.Extension)
+	model.PreviewContentType = preview.ContentType
+
+	if err := s.blobStorage.Put(model.PreviewKey, preview.Data, preview.ContentType); err != nil {
+		logger.Logger.Error("Failed to store model preview", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to store model preview")
+	}
+
+	if err := s.repository.Save(model); err != nil {
+		logger.Logger.Error("Failed to update model", "id", id)
+		s.deletePreview(model.PreviewKey)
+		return nil, service_errors.NewErrInternalServer("Failed to update model")
+	}
+
+	s.deletePreview(previousKey)
+	s.notifyModelChanged(model.Id)
+
+	logger.Logger.Info("Saved model preview.", "id", id, "key", model.PreviewKey)
+	return ToModelDto(model), nil
+}
+
+func (s *ModelServiceImpl) GetModelPreview(id string) (*ModelPreview, error) {
+	logger.Logger.Info("Fetching model preview...", "id", id)
+
+	model, err := s.GetModelById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.PreviewKey == "" {
+		logger.Logger.Error("Model has no preview", "id", id)
+		return nil, service_errors.NewErrNotFound("Model has no preview")
+	}
+
+	data, err := s.blobStorage.Get(model.PreviewKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			logger.Logger.Error("Model preview not found", "id", id, "key", model.PreviewKey)
+			return nil, service_errors.NewErrNotFound("Model preview not found")
+		}
+
+		logger.Logger.Error("Failed to fetch model preview", "id", id, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch model preview")
+	}
+
+	logger.Logger.Info("Fetched model preview.", "id", id, "size", len(data))
+	return &ModelPreview{Data: data, ContentType: model.PreviewContentType}, nil
+}
+
+func (s *ModelServiceImpl) deletePreview(key string) {
+	if key == "" {
+		return
+	}
+
+	if err := s.blobStorage.Delete(key); err != nil {
+		logger.Logger.Error("Failed to delete model preview", "key", key, "error", err)
+	}
+}
+
+func nextVersion(model *Model, req *requests.ModelRequest) *ModelVersion {
+	version := &ModelVersion{Url: model.Url, Protocol: model.Protocol, UpstreamModel: model.UpstreamModel}
+	if req.Url != "" {
+		version.Url = req.Url
+	}
+
+	if req.Protocol != "" {
+		version.Protocol = ModelProtocol(req.Protocol)
+	}
+
+	if req.UpstreamModel != "" {
+		version.UpstreamModel = req.UpstreamModel
+	}
+
+	if version.Url == model.Url && version.Protocol == model.Protocol && version.UpstreamModel == model.UpstreamModel {
+		return nil
+	}
+
+	return version
+}
+
+func (s *ModelServiceImpl) findModel(id string) (*Model, error) {
+	model, err := s.repository.FindById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Model not found", "id", id)
+			return nil, service_errors.NewErrNotFound("Model not found")
+		}
+
+		logger.Logger.Error("Failed to fetch model", "id", id)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch model")
+	}
+
+	return model, nil
+}
+
+func (s *ModelServiceImpl) withHealth(dto *ModelDto) *ModelDto {
+	dto.Health = s.balancer.Health(dto)
+	return dto
+}
+
+func (s *ModelServiceImpl) notifyModelChanged(modelId string) {
+	for _, listener := range s.listeners {
+		listener.OnModelChanged(modelId)
+	}
+}
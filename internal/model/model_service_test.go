@@ -0,0 +1,281 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// memoryModelRepository keeps models in a map, versions are numbered as they
+// are saved
+type memoryModelRepository struct {
+	ModelRepository
+	models map[string]*Model
+	rules  []ModelLanguageRule
+}
+
+func newMemoryModelRepository(models ...*Model) *memoryModelRepository {
+	repo := &memoryModelRepository{models: map[string]*Model{}}
+	for _, model := range models {
+		repo.models[model.Id] = model
+	}
+	return repo
+}
+
+func (r *memoryModelRepository) Save(model *Model) error {
+	r.models[model.Id] = model
+	return nil
+}
+
+func (r *memoryModelRepository) CreateWithVersion(model *Model, version *ModelVersion) error {
+	model.Id = fmt.Sprintf("model-%d", len(r.models)+1)
+	return r.SaveVersion(model, version, true)
+}
+
+func (r *memoryModelRepository) SaveVersion(model *Model, version *ModelVersion, activate bool) error {
+	version.Id = fmt.Sprintf("v%d", version.Number)
+	version.ModelId = model.Id
+	model.Versions = append(model.Versions, *version)
+	if activate {
+		model.activate(version)
+	}
+	return r.Save(model)
+}
+
+func (r *memoryModelRepository) FindById(id string) (*Model, error) {
+	model, ok := r.models[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return model, nil
+}
+
+func (r *memoryModelRepository) Transaction(fn func(repository ModelRepository) error) error {
+	return fn(r)
+}
+
+func (r *memoryModelRepository) FindAll() ([]Model, error) {
+	return r.find(func(model *Model) bool { return model.ArchivedAt == nil }), nil
+}
+
+func (r *memoryModelRepository) FindArchived() ([]Model, error) {
+	return r.find(func(model *Model) bool { return model.ArchivedAt != nil }), nil
+}
+
+func (r *memoryModelRepository) find(matches func(model *Model) bool) []Model {
+	models := []Model{}
+	for _, model := range r.models {
+		if matches(model) {
+			models = append(models, *model)
+		}
+	}
+
+	slices.SortFunc(models, func(a, b Model) int { return strings.Compare(a.Id, b.Id) })
+	return models
+}
+
+func (r *memoryModelRepository) FindByQuery(query *ModelQuery) ([]Model, error) {
+	return r.FindAll()
+}
+
+func (r *memoryModelRepository) DeleteById(id string) error {
+	delete(r.models, id)
+	return nil
+}
+
+func (r *memoryModelRepository) FindLanguageRules() ([]ModelLanguageRule, error) {
+	return r.rules, nil
+}
+
+func (r *memoryModelRepository) FindByNameAndLanguage(name, language string) (*Model, error) {
+	for _, model := range r.models {
+		if model.Name == name && model.Language == language {
+			return model, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type memoryBlobStorage struct {
+	storage.BlobStorage
+	deleted []string
+}
+
+func (s *memoryBlobStorage) Delete(key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+type recordingModelListener struct {
+	changed []string
+}
+
+func (l *recordingModelListener) OnModelChanged(modelId string) {
+	l.changed = append(l.changed, modelId)
+}
+
+func listedIds(t *testing.T, service *ModelServiceImpl) []string {
+	t.Helper()
+
+	models, err := service.GetModels(&ModelQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = model.Id
+	}
+	return ids
+}
+
+func assertBadRequest(t *testing.T, name string, err error) {
+	t.Helper()
+
+	var badRequest *service_errors.ErrBadRequest
+	if !errors.As(err, &badRequest) {
+		t.Errorf("%s: expected a bad request, got %v", name, err)
+	}
+}
+
+func TestArchiveAndRestoreModel(t *testing.T) {
+	anna := &Model{Id: "1", Name: "anna", Language: "en", Url: "http://anna"}
+	boris := &Model{Id: "2", Name: "boris", Language: "uk", Url: "http://boris"}
+	listener := &recordingModelListener{}
+	service := NewModelService(newMemoryModelRepository(anna, boris), &memoryBlobStorage{}, NewReplicaBalancer(), listener)
+
+	assertBadRequest(t, "restore active", func() error { _, err := service.RestoreModel("1"); return err }())
+
+	if err := service.ArchiveModel("1"); err != nil {
+		t.Fatal(err)
+	}
+	assertBadRequest(t, "archive twice", service.ArchiveModel("1"))
+
+	if ids := listedIds(t, service); !slices.Equal(ids, []string{"2"}) {
+		t.Errorf("archived model should be hidden from the list, got %v", ids)
+	}
+
+	archived, err := service.GetModelById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived.ArchivedAt == nil {
+		t.Error("archived model should stay resolvable by id and report when it was archived")
+	}
+
+	restored, err := service.RestoreModel("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ArchivedAt != nil {
+		t.Errorf("restored model is still archived: %+v", restored)
+	}
+	if ids := listedIds(t, service); !slices.Equal(ids, []string{"1", "2"}) {
+		t.Errorf("restored model should be listed again, got %v", ids)
+	}
+
+	if !slices.Equal(listener.changed, []string{"1", "1"}) {
+		t.Errorf("expected listeners to hear about archive and restore, got %v", listener.changed)
+	}
+}
+
+func TestPurgeModel(t *testing.T) {
+	anna := &Model{Id: "1", Name: "anna", Language: "en", Url: "http://anna", PreviewKey: "models/1/preview.wav"}
+	repository := newMemoryModelRepository(anna)
+	blobs := &memoryBlobStorage{}
+	listener := &recordingModelListener{}
+	service := NewModelService(repository, blobs, NewReplicaBalancer(), listener)
+
+	assertBadRequest(t, "purge active", service.PurgeModel("1"))
+	if _, ok := repository.models["1"]; !ok {
+		t.Fatal("active model must not be purged")
+	}
+
+	if err := service.ArchiveModel("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.PurgeModel("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := repository.models["1"]; ok {
+		t.Error("purged model should be deleted")
+	}
+	if !slices.Equal(blobs.deleted, []string{"models/1/preview.wav"}) {
+		t.Errorf("expected the preview to be deleted, got %v", blobs.deleted)
+	}
+
+	var notFound *service_errors.ErrNotFound
+	if _, err := service.GetModelById("1"); !errors.As(err, &notFound) {
+		t.Errorf("purged model should not be found, got %v", err)
+	}
+	if !slices.Equal(listener.changed, []string{"1", "1"}) {
+		t.Errorf("expected listeners to hear about archive and purge, got %v", listener.changed)
+	}
+}
+
+func TestUpdateModelStagesConnectionChange(t *testing.T) {
+	model := &Model{Id: "model-1", Name: "anna", Language: "en", Url: "http://v1", Protocol: ProtocolJson}
+	repo := newMemoryModelRepository(model)
+	service := NewModelService(repo, nil, nil)
+
+	dto, err := service.UpdateModel(model.Id, &requests.ModelRequest{Name: "anna", Language: "en", Url: "http://v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dto.StagedVersionId != "v2" {
+		t.Errorf("expected version 2 to be staged, got %q", dto.StagedVersionId)
+	}
+	if model.Url != "http://v1" || model.ActiveVersionId != "v1" {
+		t.Errorf("connection change should not go live, serving %s from %s", model.ActiveVersionId, model.Url)
+	}
+
+	// the same change again reuses the staged version
+	dto, err = service.UpdateModel(model.Id, &requests.ModelRequest{Name: "anna", Language: "en", Url: "http://v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dto.StagedVersionId != "v2" || len(model.Versions) != 2 {
+		t.Errorf("expected the staged version to be reused, got %q with %d versions", dto.StagedVersionId, len(model.Versions))
+	}
+
+	dto, err = service.UpdateModel(model.Id, &requests.ModelRequest{Name: "anna", Language: "en", TimeoutMs: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dto.StagedVersionId != "" || model.TimeoutMs != 500 {
+		t.Errorf("expected a plain update, got staged %q and timeout %d", dto.StagedVersionId, model.TimeoutMs)
+	}
+}
+
+func TestRegistryStagesConnectionChange(t *testing.T) {
+	model := &Model{Id: "model-1", Name: "anna", Language: "en", Url: "http://v1", Protocol: ProtocolJson, LoadBalancing: RoundRobin}
+	repo := newMemoryModelRepository(model)
+	req := &requests.ModelRequest{Name: "anna", Language: "en", Url: "http://v2"}
+
+	change := RegistryChange{Action: RegistryUpdate, Id: model.Id, Fields: []string{"url"}, Staged: true}
+	if _, err := applyRegistryChange(repo, change, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Url != "http://v1" || len(model.Versions) != 2 || model.Versions[1].Url != "http://v2" {
+		t.Errorf("expected http://v2 staged next to the live connection, got %s with %+v", model.Url, model.Versions)
+	}
+
+	// importing the same document again is a no-op
+	fields, err := diffModel(model, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 0 {
+		t.Errorf("staged connection should not show up as a change, got %v", fields)
+	}
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// a version pins where and how a model is served; the model mirrors the
+// connection settings of its active version
+type ModelVersion struct {
+	Id            string        `gorm:"type:varchar(256);primaryKey;"`
+	ModelId       string        `gorm:"type:varchar(256);not null;index:idx_model_version_number,unique"`
+	Number        int           `gorm:"not null;index:idx_model_version_number,unique"`
+	Url           string        `gorm:"type:varchar(256);not null"`
+	Protocol      ModelProtocol `gorm:"type:varchar(32);not null;default:'json'"`
+	UpstreamModel string        `gorm:"type:varchar(255);"`
+	Notes         string        `gorm:"type:varchar(1024);"`
+	CreatedAt     time.Time     `gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (version *ModelVersion) BeforeCreate(tx *gorm.DB) (err error) {
+	version.Id = uuid.NewString()
+	return
+}
+
+type ModelVersionDto struct {
+	Id            string        `json:"id"`
+	Number        int           `json:"number"`
+	Url           string        `json:"url"`
+	Protocol      ModelProtocol `json:"protocol"`
+	UpstreamModel string        `json:"upstream_model,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+func ToModelVersionModel(modelId string, dto *ModelVersionDto) *ModelVersion {
+	return &ModelVersion{
+		Id:            dto.Id,
+		ModelId:       modelId,
+		Number:        dto.Number,
+		Url:           dto.Url,
+		Protocol:      dto.Protocol,
+		UpstreamModel: dto.UpstreamModel,
+		Notes:         dto.Notes,
+		CreatedAt:     dto.CreatedAt,
+	}
+}
+
+func ToModelVersionDto(version *ModelVersion) *ModelVersionDto {
+	return &ModelVersionDto{
+		Id:            version.Id,
+		Number:        version.Number,
+		Url:           version.Url,
+		Protocol:      version.Protocol,
+		UpstreamModel: version.UpstreamModel,
+		Notes:         version.Notes,
+		CreatedAt:     version.CreatedAt,
+	}
+}
+
+func (model *Model) activate(version *ModelVersion) {
+	if model.ActiveVersionId != "" && model.ActiveVersionId != version.Id {
+		model.PreviousVersionId = model.ActiveVersionId
+	}
+
+	model.ActiveVersionId = version.Id
+	model.Url = version.Url
+	model.Protocol = version.Protocol
+	model.UpstreamModel = version.UpstreamModel
+
+	if model.CanaryVersionId == version.Id {
+		model.CanaryVersionId = ""
+		model.CanaryPercent = 0
+	}
+}
+
+func (model *Model) findVersion(id string) *ModelVersion {
+	for i := range model.Versions {
+		if model.Versions[i].Id == id {
+			return &model.Versions[i]
+		}
+	}
+
+	return nil
+}
+
+// stagedVersion finds an inactive version that already serves from the given
+// connection, so repeated updates don't pile up identical versions
+func (model *Model) stagedVersion(url string, protocol ModelProtocol, upstreamModel string) *ModelVersion {
+	for i := range model.Versions {
+		version := &model.Versions[i]
+		if version.Id != model.ActiveVersionId && version.Url == url && version.Protocol == protocol && version.UpstreamModel == upstreamModel {
+			return version
+		}
+	}
+
+	return nil
+}
+
+// stageVersion saves a connection change as an inactive version; it reaches
+// traffic only once activated or started as a canary
+func stageVersion(repository ModelRepository, model *Model, version *ModelVersion) (string, error) {
+	if err := ensureInitialVersion(repository, model); err != nil {
+		return "", err
+	}
+
+	if staged := model.stagedVersion(version.Url, version.Protocol, version.UpstreamModel); staged != nil {
+		return staged.Id, repository.Save(model)
+	}
+
+	version.Number = model.nextVersionNumber()
+	if err := repository.SaveVersion(model, version, false); err != nil {
+		return "", err
+	}
+
+	return version.Id, nil
+}
+
+func (model *Model) nextVersionNumber() int {
+	number := 0
+	for _, version := range model.Versions {
+		number = max(number, version.Number)
+	}
+
+	return number + 1
+}
+
+// ForRequest picks the version serving a single request. Callers with the
+// same key stay on the same side of a canary split; an empty key is placed
+// at random.
+func (dto *ModelDto) ForRequest(key string) *ModelDto {
+	versionId := dto.ActiveVersionId
+	if dto.CanaryVersionId != "" && canaryBucket(dto.Id, key) < dto.CanaryPercent {
+		versionId = dto.CanaryVersionId
+	}
+
+	return dto.WithVersion(versionId)
+}
+
+// WithVersion returns a copy of the model that connects to the given version.
+// Models created before versioning have none and are returned as they are.
+func (dto *ModelDto) WithVersion(versionId string) *ModelDto {
+	serving := *dto
+
+	for _, version := range dto.Versions {
+		if version.Id == versionId {
+			serving.Url = version.Url
+			serving.Protocol = version.Protocol
+			serving.UpstreamModel = version.UpstreamModel
+			serving.ServingVersionId = version.Id
+			serving.ServingVersion = version.Number
+			break
+		}
+	}
+
+	return &serving
+}
+
+func canaryBucket(modelId, key string) int {
+	if key == "" {
+		return rand.Intn(100)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(modelId + "|" + key))
+	return int(hash.Sum32() % 100)
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func versionedModel() *Model {
+	model := &Model{Id: "model-1"}
+	for number := 1; number <= 2; number++ {
+		version := ModelVersion{Id: fmt.Sprintf("v%d", number), Number: number, Url: fmt.Sprintf("http://v%d", number), Protocol: ProtocolJson}
+		model.Versions = append(model.Versions, version)
+	}
+
+	model.activate(&model.Versions[0])
+	return model
+}
+
+func TestActivateTracksPreviousVersion(t *testing.T) {
+	model := versionedModel()
+	model.CanaryVersionId = "v2"
+	model.CanaryPercent = 10
+
+	model.activate(&model.Versions[1])
+
+	if model.Url != "http://v2" || model.ActiveVersionId != "v2" || model.PreviousVersionId != "v1" {
+		t.Errorf("unexpected model after activation: %+v", model)
+	}
+	if model.CanaryVersionId != "" || model.CanaryPercent != 0 {
+		t.Errorf("promoted canary should be cleared, got %q at %d%%", model.CanaryVersionId, model.CanaryPercent)
+	}
+}
+
+func TestForRequestSplitsCanaryTraffic(t *testing.T) {
+	model := versionedModel()
+	model.CanaryVersionId = "v2"
+	model.CanaryPercent = 25
+	dto := ToModelDto(model)
+
+	canary := 0
+	for i := 0; i < 4000; i++ {
+		serving := dto.ForRequest(fmt.Sprintf("user-%d", i))
+		if serving.ServingVersionId == "v2" {
+			canary++
+			if serving.Url != "http://v2" || serving.ServingVersion != 2 {
+				t.Fatalf("canary request not routed to canary: %+v", serving)
+			}
+		}
+	}
+
+	if canary < 800 || canary > 1200 {
+		t.Errorf("expected about 25%% canary traffic, got %d of 4000", canary)
+	}
+
+	first := dto.ForRequest("user-7").ServingVersionId
+	for i := 0; i < 10; i++ {
+		if dto.ForRequest("user-7").ServingVersionId != first {
+			t.Fatal("the same user should stay on the same version")
+		}
+	}
+}
+
+func TestEndpointsFollowServingVersion(t *testing.T) {
+	model := versionedModel()
+	model.Replicas = []ModelReplica{
+		{Id: "r1", VersionId: "v1", Url: "http://v1-replica"},
+		{Id: "r2", VersionId: "v2", Url: "http://v2-replica"},
+	}
+	dto := ToModelDto(model)
+
+	endpoints := dto.WithVersion("v2").Endpoints()
+	if len(endpoints) != 2 || endpoints[0].Url != "http://v2" || endpoints[1].ReplicaId != "r2" {
+		t.Errorf("unexpected canary endpoints: %+v", endpoints)
+	}
+
+	endpoints = dto.Endpoints()
+	if len(endpoints) != 2 || endpoints[0].Url != "http://v1" || endpoints[1].ReplicaId != "r1" {
+		t.Errorf("unexpected active endpoints: %+v", endpoints)
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type RegistryFormat string
+
+const (
+	RegistryJson RegistryFormat = "json"
+	RegistryYaml RegistryFormat = "yaml"
+)
+
+func ParseRegistryFormat(value string) (RegistryFormat, bool) {
+	switch strings.ToLower(value) {
+	case "", "json":
+		return RegistryJson, true
+	case "yaml", "yml":
+		return RegistryYaml, true
+	default:
+		return "", false
+	}
+}
+
+func RegistryFormatFromContentType(contentType string) RegistryFormat {
+	if strings.Contains(contentType, "yaml") {
+		return RegistryYaml
+	}
+
+	return RegistryJson
+}
+
+func RegistryFormatFromPath(path string) RegistryFormat {
+	format, ok := ParseRegistryFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+	if !ok {
+		return RegistryJson
+	}
+
+	return format
+}
+
+func (f RegistryFormat) ContentType() string {
+	if f == RegistryYaml {
+		return "application/yaml"
+	}
+
+	return "application/json"
+}
+
+func EncodeRegistry(document *RegistryDocument, format RegistryFormat) ([]byte, error) {
+	if format == RegistryYaml {
+		return yaml.Marshal(document)
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// unknown fields are rejected so typos in a checked-in file don't get
+// silently dropped
+func DecodeRegistry(data []byte, format RegistryFormat) (*RegistryDocument, error) {
+	var document RegistryDocument
+
+	if format == RegistryYaml {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&document); err != nil {
+			return nil, err
+		}
+
+		return &document, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
@@ -0,0 +1,293 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/validation"
+)
+
+type RegistryService interface {
+	Export() (*RegistryDocument, error)
+	Import(document *RegistryDocument, options RegistryImportOptions) (*RegistryDiff, error)
+}
+
+type RegistryServiceImpl struct {
+	repository        ModelRepository
+	validationService *validation.ValidationService
+	listeners         []ModelChangeListener
+	mu                sync.Mutex
+}
+
+func NewRegistryService(repository ModelRepository, validationService *validation.ValidationService, listeners ...ModelChangeListener) *RegistryServiceImpl {
+	return &RegistryServiceImpl{repository: repository, validationService: validationService, listeners: listeners}
+}
+
+func (s *RegistryServiceImpl) Export() (*RegistryDocument, error) {
+	logger.Logger.Info("Exporting model registry...")
+
+	models, err := s.repository.FindAll()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch models", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch models")
+	}
+
+	document := exportDocument(models)
+
+	logger.Logger.Info("Exported model registry.", "size", len(document.Models), "revision", document.Revision)
+	return document, nil
+}
+
+// Import brings the registry in line with the document. The document's
+// revision has to match the current registry, so an import based on a stale
+// export fails instead of overwriting changes made in the meantime.
+func (s *RegistryServiceImpl) Import(document *RegistryDocument, options RegistryImportOptions) (*RegistryDiff, error) {
+	logger.Logger.Info("Importing model registry...", "size", len(document.Models), "dryRun", options.DryRun, "prune", options.Prune)
+
+	if err := s.validate(document); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active, err := s.repository.FindAll()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch models", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch models")
+	}
+
+	archived, err := s.repository.FindArchived()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch archived models", "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch archived models")
+	}
+
+	revision := exportDocument(active).Revision
+	if document.Revision == "" && options.RequireRevision {
+		logger.Logger.Error("Registry revision is missing")
+		return nil, service_errors.NewErrBadRequest("Registry revision is required, export the registry first or force the import")
+	}
+
+	if document.Revision != "" && document.Revision != revision {
+		logger.Logger.Error("Registry revision is stale", "revision", document.Revision, "current", revision)
+		return nil, service_errors.NewErrConflict("Registry has changed since it was exported, export it again and reapply your changes")
+	}
+
+	changes, err := planRegistryChanges(document, active, archived, options.Prune)
+	if err != nil {
+		logger.Logger.Error("Invalid model registry", "error", err)
+		return nil, service_errors.NewErrBadRequest(err.Error())
+	}
+
+	if err := s.checkLanguageRules(changes); err != nil {
+		return nil, err
+	}
+
+	diff := &RegistryDiff{DryRun: options.DryRun, Revision: revision, Changes: changes}
+	if options.DryRun {
+		logger.Logger.Info("Planned model registry import.", "changes", len(changes))
+		return diff, nil
+	}
+
+	listed := make(map[string]*requests.ModelRequest, len(document.Models))
+	for i := range document.Models {
+		listed[registryKey(document.Models[i].Name, document.Models[i].Language)] = &document.Models[i]
+	}
+
+	// the whole diff is applied in one transaction, a failing change leaves
+	// the registry as it was
+	err = s.repository.Transaction(func(repository ModelRepository) error {
+		for i, change := range changes {
+			id, err := applyRegistryChange(repository, change, listed[registryKey(change.Name, change.Language)])
+			if err != nil {
+				logger.Logger.Error("Failed to apply model registry change", "action", change.Action, "name", change.Name, "language", change.Language, "error", err)
+				return err
+			}
+
+			changes[i].Id = id
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, service_errors.NewErrInternalServer("Failed to apply model registry, no changes were made")
+	}
+
+	for _, change := range changes {
+		for _, listener := range s.listeners {
+			listener.OnModelChanged(change.Id)
+		}
+	}
+
+	if active, err = s.repository.FindAll(); err == nil {
+		diff.Revision = exportDocument(active).Revision
+	}
+
+	logger.Logger.Info("Imported model registry.", "changes", len(changes), "revision", diff.Revision)
+	return diff, nil
+}
+
+func (s *RegistryServiceImpl) validate(document *RegistryDocument) error {
+	seen := make(map[string]bool, len(document.Models))
+	for i := range document.Models {
+		req := &document.Models[i]
+		if err := s.validationService.ValidateModelRequest(req); err != nil {
+			logger.Logger.Error("Registry model didn't pass validation", "index", i, "name", req.Name, "language", req.Language)
+			return service_errors.NewErrBadRequest(fmt.Sprintf("Model %d (%s, %s) didn't pass validation", i+1, req.Name, req.Language))
+		}
+
+		key := registryKey(req.Name, req.Language)
+		if seen[key] {
+			logger.Logger.Error("Duplicate registry model", "name", req.Name, "language", req.Language)
+			return service_errors.NewErrBadRequest(fmt.Sprintf("Model %s (%s) is listed more than once", req.Name, req.Language))
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// the registry doesn't carry language rules, so it can't prune a model one
+// of them routes to without leaving the rule dangling
+func (s *RegistryServiceImpl) checkLanguageRules(changes []RegistryChange) error {
+	rules, err := s.repository.FindLanguageRules()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch language rules", "error", err)
+		return service_errors.NewErrInternalServer("Failed to fetch language rules")
+	}
+
+	for _, change := range changes {
+		if change.Action != RegistryDelete {
+			continue
+		}
+
+		for _, rule := range rules {
+			if rule.ModelId == change.Id {
+				logger.Logger.Error("Registry prune would orphan a language rule", "modelId", change.Id, "language", rule.Language)
+				return service_errors.NewErrBadRequest(fmt.Sprintf("Model %s (%s) is the default for language %s, change the language rule before pruning it", change.Name, change.Language, rule.Language))
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportDocument(models []Model) *RegistryDocument {
+	document := &RegistryDocument{Models: make([]requests.ModelRequest, len(models))}
+	for i := range models {
+		document.Models[i] = toModelRequest(&models[i])
+	}
+
+	document.Revision = registryRevision(document.Models)
+	return document
+}
+
+func planRegistryChanges(document *RegistryDocument, active, archived []Model, prune bool) ([]RegistryChange, error) {
+	activeByKey := make(map[string]*Model, len(active))
+	for i := range active {
+		activeByKey[registryKey(active[i].Name, active[i].Language)] = &active[i]
+	}
+
+	archivedByKey := make(map[string]*Model, len(archived))
+	for i := range archived {
+		archivedByKey[registryKey(archived[i].Name, archived[i].Language)] = &archived[i]
+	}
+
+	changes := []RegistryChange{}
+	listed := make(map[string]bool, len(document.Models))
+	for i := range document.Models {
+		req := &document.Models[i]
+		key := registryKey(req.Name, req.Language)
+		listed[key] = true
+
+		if _, err := registryModel(req); err != nil {
+			return nil, fmt.Errorf("model %s (%s): %w", req.Name, req.Language, err)
+		}
+
+		if model, ok := activeByKey[key]; ok {
+			fields, err := diffModel(model, req)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(fields) > 0 {
+				changes = append(changes, RegistryChange{Action: RegistryUpdate, Id: model.Id, Name: model.Name, Language: model.Language, Fields: fields, Staged: stagesConnection(fields)})
+			}
+			continue
+		}
+
+		if model, ok := archivedByKey[key]; ok {
+			fields, err := diffModel(model, req)
+			if err != nil {
+				return nil, err
+			}
+
+			changes = append(changes, RegistryChange{Action: RegistryRestore, Id: model.Id, Name: model.Name, Language: model.Language, Fields: fields, Staged: stagesConnection(fields)})
+			continue
+		}
+
+		changes = append(changes, RegistryChange{Action: RegistryCreate, Name: req.Name, Language: req.Language})
+	}
+
+	if prune {
+		for i := range active {
+			if !listed[registryKey(active[i].Name, active[i].Language)] {
+				changes = append(changes, RegistryChange{Action: RegistryDelete, Id: active[i].Id, Name: active[i].Name, Language: active[i].Language})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func applyRegistryChange(repository ModelRepository, change RegistryChange, req *requests.ModelRequest) (string, error) {
+	if change.Action == RegistryCreate {
+		model, err := registryModel(req)
+		if err != nil {
+			return "", err
+		}
+
+		// the id is assigned on create
+		version := &ModelVersion{Number: 1, Url: model.Url, Protocol: model.Protocol, UpstreamModel: model.UpstreamModel}
+		if err := repository.CreateWithVersion(model, version); err != nil {
+			return "", err
+		}
+
+		return model.Id, nil
+	}
+
+	model, err := repository.FindById(change.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if change.Action == RegistryDelete {
+		now := time.Now()
+		model.ArchivedAt = &now
+		return model.Id, repository.Save(model)
+	}
+
+	if change.Action == RegistryRestore {
+		model.ArchivedAt = nil
+	}
+
+	desired, err := registryModel(req)
+	if err != nil {
+		return "", err
+	}
+
+	version := applyRegistryModel(model, desired)
+	if version == nil {
+		return model.Id, repository.Save(model)
+	}
+
+	if _, err := stageVersion(repository, model, version); err != nil {
+		return "", err
+	}
+
+	return model.Id, nil
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultUnhealthyThreshold = 2
+	defaultDegradedLatencyMs  = 2000
+	degradedAvailability      = 0.9
+	healthProbeWindow         = 60
+)
+
+type ReplicaBalancer interface {
+	Acquire(model *ModelDto) (*Endpoint, func(healthy bool))
+	ReportHealth(endpoint *Endpoint, healthy bool, latency time.Duration, err error)
+	Status(model *ModelDto) []ReplicaStatusDto
+	Health(model *ModelDto) *ModelHealthDto
+}
+
+type replicaState struct {
+	healthy             bool
+	outstanding         int
+	consecutiveFailures int
+	lastCheckedAt       *time.Time
+	lastError           string
+	probes              []probeResult
+}
+
+type ReplicaBalancerImpl struct {
+	mu                 sync.Mutex
+	states             map[string]*replicaState
+	counters           map[string]int
+	unhealthyThreshold int
+	degradedLatency    time.Duration
+}
+
+func NewReplicaBalancer() *ReplicaBalancerImpl {
+	threshold, err := strconv.Atoi(os.Getenv("MODEL_REPLICA_UNHEALTHY_THRESHOLD"))
+	if err != nil || threshold < 1 {
+		threshold = defaultUnhealthyThreshold
+	}
+
+	degradedLatencyMs, err := strconv.Atoi(os.Getenv("MODEL_DEGRADED_LATENCY_MS"))
+	if err != nil || degradedLatencyMs < 1 {
+		degradedLatencyMs = defaultDegradedLatencyMs
+	}
+
+	return &ReplicaBalancerImpl{
+		states:             make(map[string]*replicaState),
+		counters:           make(map[string]int),
+		unhealthyThreshold: threshold,
+		degradedLatency:    time.Duration(degradedLatencyMs) * time.Millisecond,
+	}
+}
+
+func (b *ReplicaBalancerImpl) Acquire(model *ModelDto) (*Endpoint, func(healthy bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	endpoints := model.Endpoints()
+
+	var candidates []Endpoint
+	for _, endpoint := range endpoints {
+		if b.state(&endpoint).healthy {
+			candidates = append(candidates, endpoint)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = endpoints
+	}
+
+	var selected Endpoint
+	switch model.LoadBalancing {
+	case LeastOutstanding:
+		offset := b.counters[model.Id]
+		b.counters[model.Id] = offset + 1
+
+		selected = candidates[offset%len(candidates)]
+		for i := range candidates {
+			candidate := candidates[(offset+i)%len(candidates)]
+			if b.state(&candidate).outstanding < b.state(&selected).outstanding {
+				selected = candidate
+			}
+		}
+	default:
+		counter := b.counters[model.Id]
+		b.counters[model.Id] = counter + 1
+		selected = candidates[counter%len(candidates)]
+	}
+
+	state := b.state(&selected)
+	state.outstanding++
+
+	var once sync.Once
+	release := func(healthy bool) {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			state.outstanding--
+			if healthy {
+				// only consecutive failures count towards ejecting the replica
+				state.consecutiveFailures = 0
+				return
+			}
+			b.recordFailure(state, "request failed")
+		})
+	}
+
+	return &selected, release
+}
+
+func (b *ReplicaBalancerImpl) ReportHealth(endpoint *Endpoint, healthy bool, latency time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state(endpoint)
+	now := time.Now()
+	state.lastCheckedAt = &now
+
+	state.probes = append(state.probes, probeResult{healthy: healthy, latency: latency})
+	if len(state.probes) > healthProbeWindow {
+		state.probes = state.probes[len(state.probes)-healthProbeWindow:]
+	}
+
+	if healthy {
+		state.healthy = true
+		state.consecutiveFailures = 0
+		state.lastError = ""
+		return
+	}
+
+	message := "health check failed"
+	if err != nil {
+		message = err.Error()
+	}
+	b.recordFailure(state, message)
+}
+
+func (b *ReplicaBalancerImpl) Status(model *ModelDto) []ReplicaStatusDto {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	endpoints := model.Endpoints()
+	statuses := make([]ReplicaStatusDto, len(endpoints))
+	for i, endpoint := range endpoints {
+		state := b.state(&endpoint)
+		statuses[i] = ReplicaStatusDto{
+			Id:                  endpoint.ReplicaId,
+			Url:                 endpoint.Url,
+			Primary:             endpoint.ReplicaId == "",
+			Healthy:             state.healthy,
+			Outstanding:         state.outstanding,
+			ConsecutiveFailures: state.consecutiveFailures,
+			LastCheckedAt:       state.lastCheckedAt,
+			LastError:           state.lastError,
+			LatencyP95Ms:        percentileMs(sortedLatencies(state.probes), 95),
+		}
+	}
+
+	return statuses
+}
+
+// Health classifies the model as down when none of its endpoints is healthy,
+// and as degraded when some are not, recent probes fail too often or the
+// probe latency p95 exceeds the configured limit.
+func (b *ReplicaBalancerImpl) Health(model *ModelDto) *ModelHealthDto {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	endpoints := model.Endpoints()
+	health := &ModelHealthDto{Status: ModelAvailable, Availability: 1}
+
+	var probes []probeResult
+	healthy := 0
+	for _, endpoint := range endpoints {
+		state := b.state(&endpoint)
+		if state.healthy {
+			healthy++
+		}
+
+		probes = append(probes, state.probes...)
+		if state.lastCheckedAt != nil && (health.LastCheckedAt == nil || state.lastCheckedAt.After(*health.LastCheckedAt)) {
+			health.LastCheckedAt = state.lastCheckedAt
+		}
+	}
+
+	latencies := sortedLatencies(probes)
+	if len(probes) > 0 {
+		health.Availability = float64(len(latencies)) / float64(len(probes))
+	}
+
+	health.LatencyP50Ms = percentileMs(latencies, 50)
+	health.LatencyP95Ms = percentileMs(latencies, 95)
+	health.LatencyP99Ms = percentileMs(latencies, 99)
+
+	switch {
+	case healthy == 0:
+		health.Status = ModelDown
+	case healthy < len(endpoints), health.Availability < degradedAvailability, health.LatencyP95Ms > b.degradedLatency.Milliseconds():
+		health.Status = ModelDegraded
+	}
+
+	return health
+}
+
+func (b *ReplicaBalancerImpl) recordFailure(state *replicaState, message string) {
+	state.consecutiveFailures++
+	state.lastError = message
+
+	if state.consecutiveFailures >= b.unhealthyThreshold {
+		state.healthy = false
+	}
+}
+
+func (b *ReplicaBalancerImpl) state(endpoint *Endpoint) *replicaState {
+	state, ok := b.states[endpoint.key()]
+	if !ok {
+		state = &replicaState{healthy: true}
+		b.states[endpoint.key()] = state
+	}
+
+	return state
+}
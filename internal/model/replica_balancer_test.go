@@ -0,0 +1,179 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func healthTestModel() *ModelDto {
+	return &ModelDto{
+		Id:       "model-1",
+		Url:      "http://primary",
+		Replicas: []ModelReplicaDto{{Id: "r1", Url: "http://replica"}},
+	}
+}
+
+func TestHealthReportsLatencyPercentiles(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+
+	for i := 1; i <= 20; i++ {
+		for _, endpoint := range model.Endpoints() {
+			balancer.ReportHealth(&endpoint, true, time.Duration(i*10)*time.Millisecond, nil)
+		}
+	}
+
+	health := balancer.Health(model)
+	if health.Status != ModelAvailable || health.Availability != 1 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if health.LatencyP50Ms != 100 || health.LatencyP95Ms != 190 || health.LatencyP99Ms != 200 {
+		t.Errorf("unexpected percentiles: %+v", health)
+	}
+	if health.LastCheckedAt == nil {
+		t.Error("expected last checked time")
+	}
+}
+
+func TestHealthStatus(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+	endpoints := model.Endpoints()
+
+	for i := 0; i < defaultUnhealthyThreshold; i++ {
+		balancer.ReportHealth(&endpoints[1], false, time.Millisecond, errors.New("connection refused"))
+	}
+	if status := balancer.Health(model).Status; status != ModelDegraded {
+		t.Errorf("expected degraded with one endpoint down, got %s", status)
+	}
+
+	for i := 0; i < defaultUnhealthyThreshold; i++ {
+		balancer.ReportHealth(&endpoints[0], false, time.Millisecond, errors.New("connection refused"))
+	}
+	if status := balancer.Health(model).Status; status != ModelDown {
+		t.Errorf("expected down with all endpoints down, got %s", status)
+	}
+
+	for _, endpoint := range endpoints {
+		balancer.ReportHealth(&endpoint, true, 5*time.Second, nil)
+	}
+	if status := balancer.Health(model).Status; status != ModelDegraded {
+		t.Errorf("expected degraded after recovery with slow probes, got %s", status)
+	}
+}
+
+func TestAcquireRoundRobin(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+
+	var urls []string
+	for i := 0; i < 4; i++ {
+		endpoint, release := balancer.Acquire(model)
+		urls = append(urls, endpoint.Url)
+		release(true)
+	}
+
+	expected := []string{"http://primary", "http://replica", "http://primary", "http://replica"}
+	for i := range expected {
+		if urls[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, urls)
+		}
+	}
+}
+
+func TestAcquireLeastOutstanding(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+	model.LoadBalancing = LeastOutstanding
+
+	busy, release := balancer.Acquire(model)
+	defer release(true)
+
+	for i := 0; i < 3; i++ {
+		endpoint, done := balancer.Acquire(model)
+		if endpoint.Url == busy.Url {
+			t.Errorf("expected the idle endpoint, got the busy %s", endpoint.Url)
+		}
+		done(true)
+	}
+}
+
+func TestAcquireEjectsFailingReplica(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+
+	for i := 0; i < defaultUnhealthyThreshold; i++ {
+		for {
+			endpoint, release := balancer.Acquire(model)
+			if endpoint.ReplicaId == "r1" {
+				release(false)
+				break
+			}
+			release(true)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		endpoint, release := balancer.Acquire(model)
+		if endpoint.ReplicaId == "r1" {
+			t.Fatal("expected the failing replica to be ejected")
+		}
+		release(true)
+	}
+
+	statuses := balancer.Status(model)
+	if statuses[1].Healthy || statuses[1].ConsecutiveFailures != defaultUnhealthyThreshold || statuses[1].LastError == "" {
+		t.Errorf("unexpected replica status: %+v", statuses[1])
+	}
+}
+
+func TestAcquireSuccessResetsFailures(t *testing.T) {
+	t.Setenv("MODEL_REPLICA_UNHEALTHY_THRESHOLD", "2")
+	balancer := NewReplicaBalancer()
+	model := &ModelDto{Id: "model-1", Url: "http://primary"}
+
+	// scattered failures between successful requests never eject the endpoint
+	for i := 0; i < 10; i++ {
+		_, release := balancer.Acquire(model)
+		release(false)
+
+		_, release = balancer.Acquire(model)
+		release(true)
+	}
+
+	status := balancer.Status(model)[0]
+	if !status.Healthy || status.ConsecutiveFailures != 0 || status.Outstanding != 0 {
+		t.Errorf("expected a healthy endpoint, got %+v", status)
+	}
+}
+
+func TestAcquireFallsBackWhenAllUnhealthy(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := healthTestModel()
+
+	for _, endpoint := range model.Endpoints() {
+		for i := 0; i < defaultUnhealthyThreshold; i++ {
+			balancer.ReportHealth(&endpoint, false, time.Millisecond, errors.New("connection refused"))
+		}
+	}
+
+	endpoint, release := balancer.Acquire(model)
+	defer release(true)
+	if endpoint == nil || endpoint.Url == "" {
+		t.Error("expected an endpoint even when every replica is unhealthy")
+	}
+}
+
+func TestReleaseIsIdempotent(t *testing.T) {
+	balancer := NewReplicaBalancer()
+	model := &ModelDto{Id: "model-1", Url: "http://primary"}
+
+	_, release := balancer.Acquire(model)
+	release(false)
+	release(false)
+
+	if status := balancer.Status(model)[0]; status.Outstanding != 0 || status.ConsecutiveFailures != 1 {
+		t.Errorf("expected a single release to count, got %+v", status)
+	}
+}
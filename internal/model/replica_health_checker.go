@@ -0,0 +1,125 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"vitaliiPsl/synthesizer/internal/logger"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+type ReplicaHealthChecker struct {
+	repository ModelRepository
+	balancer   ReplicaBalancer
+	client     *http.Client
+	interval   time.Duration
+}
+
+func NewReplicaHealthChecker(repository ModelRepository, balancer ReplicaBalancer) *ReplicaHealthChecker {
+	interval := defaultHealthCheckInterval
+	if ms, err := strconv.Atoi(os.Getenv("MODEL_HEALTH_CHECK_INTERVAL_MS")); err == nil && ms > 0 {
+		interval = time.Duration(ms) * time.Millisecond
+	}
+
+	return &ReplicaHealthChecker{
+		repository: repository,
+		balancer:   balancer,
+		client:     &http.Client{Timeout: defaultHealthCheckTimeout},
+		interval:   interval,
+	}
+}
+
+func (c *ReplicaHealthChecker) Start(ctx context.Context) {
+	logger.Logger.Info("Starting model replica health checker...", "interval", c.interval)
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.checkAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *ReplicaHealthChecker) checkAll(ctx context.Context) {
+	models, err := c.repository.FindAll()
+	if err != nil {
+		logger.Logger.Error("Failed to fetch models for health check", "error", err)
+		return
+	}
+
+	for _, model := range models {
+		dto := ToModelDto(&model)
+		c.checkEndpoints(ctx, dto)
+
+		if dto.CanaryVersionId != "" {
+			c.checkEndpoints(ctx, dto.WithVersion(dto.CanaryVersionId))
+		}
+	}
+}
+
+func (c *ReplicaHealthChecker) checkEndpoints(ctx context.Context, model *ModelDto) {
+	for _, endpoint := range model.Endpoints() {
+		start := time.Now()
+		healthy, err := c.probe(ctx, model.Protocol, endpoint.Url)
+		latency := time.Since(start)
+		if !healthy {
+			logger.Logger.Error("Model replica failed health check", "modelId", endpoint.ModelId, "url", endpoint.Url, "error", err)
+		}
+
+		c.balancer.ReportHealth(&endpoint, healthy, latency, err)
+	}
+}
+
+func (c *ReplicaHealthChecker) probe(ctx context.Context, protocol ModelProtocol, url string) (bool, error) {
+	if protocol == ProtocolGrpc {
+		return c.probeConnection(ctx, url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		return false, fmt.Errorf("health check responded with status %d", res.StatusCode)
+	}
+
+	return true, nil
+}
+
+// grpc servers don't answer plain GETs, accepting a connection is enough
+func (c *ReplicaHealthChecker) probeConnection(ctx context.Context, url string) (bool, error) {
+	target := strings.TrimPrefix(strings.TrimPrefix(url, "grpc://"), "grpcs://")
+
+	dialer := &net.Dialer{Timeout: defaultHealthCheckTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return false, err
+	}
+
+	conn.Close()
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"errors"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+
+	"gorm.io/gorm"
+)
+
+type ReplicaService interface {
+	AddReplica(modelId string, req *requests.ReplicaRequest) (*ModelReplicaDto, error)
+	DeleteReplica(modelId, replicaId string) error
+	GetReplicaStatuses(modelId string) ([]ReplicaStatusDto, error)
+}
+
+type ReplicaServiceImpl struct {
+	repository ModelRepository
+	balancer   ReplicaBalancer
+}
+
+func NewReplicaService(repository ModelRepository, balancer ReplicaBalancer) *ReplicaServiceImpl {
+	return &ReplicaServiceImpl{repository: repository, balancer: balancer}
+}
+
+func (s *ReplicaServiceImpl) AddReplica(modelId string, req *requests.ReplicaRequest) (*ModelReplicaDto, error) {
+	logger.Logger.Info("Adding model replica...", "modelId", modelId, "url", req.Url)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.Url == req.Url {
+		logger.Logger.Error("Replica duplicates model url", "modelId", modelId, "url", req.Url)
+		return nil, service_errors.NewErrBadRequest("Model already uses this url")
+	}
+
+	for _, replica := range model.Replicas {
+		if replica.Url == req.Url {
+			logger.Logger.Error("Replica with given url already exists", "modelId", modelId, "url", req.Url)
+			return nil, service_errors.NewErrBadRequest("Replica with this url already exists")
+		}
+	}
+
+	replica := &ModelReplica{ModelId: model.Id, VersionId: model.ActiveVersionId, Url: req.Url}
+	if err := s.repository.SaveReplica(replica); err != nil {
+		logger.Logger.Error("Failed to save model replica", "modelId", modelId, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to save model replica")
+	}
+
+	logger.Logger.Info("Added model replica.", "modelId", modelId, "id", replica.Id)
+	return ToModelReplicaDto(replica), nil
+}
+
+func (s *ReplicaServiceImpl) DeleteReplica(modelId, replicaId string) error {
+	logger.Logger.Info("Deleting model replica...", "modelId", modelId, "id", replicaId)
+
+	deleted, err := s.repository.DeleteReplica(modelId, replicaId)
+	if err != nil {
+		logger.Logger.Error("Failed to delete model replica", "modelId", modelId, "id", replicaId, "error", err)
+		return service_errors.NewErrInternalServer("Failed to delete model replica")
+	}
+
+	if !deleted {
+		logger.Logger.Error("Model replica not found", "modelId", modelId, "id", replicaId)
+		return service_errors.NewErrNotFound("Model replica not found")
+	}
+
+	logger.Logger.Info("Deleted model replica.", "modelId", modelId, "id", replicaId)
+	return nil
+}
+
+func (s *ReplicaServiceImpl) GetReplicaStatuses(modelId string) ([]ReplicaStatusDto, error) {
+	logger.Logger.Info("Fetching model replica statuses...", "modelId", modelId)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := s.balancer.Status(ToModelDto(model))
+
+	logger.Logger.Info("Fetched model replica statuses.", "modelId", modelId, "size", len(statuses))
+	return statuses, nil
+}
+
+func (s *ReplicaServiceImpl) findModel(modelId string) (*Model, error) {
+	model, err := s.repository.FindById(modelId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Model not found", "id", modelId)
+			return nil, service_errors.NewErrNotFound("Model not found")
+		}
+
+		logger.Logger.Error("Failed to fetch model", "id", modelId)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch model")
+	}
+
+	return model, nil
+}
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"errors"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/requests"
+
+	"gorm.io/gorm"
+)
+
+type VersionService interface {
+	GetVersions(modelId string) ([]ModelVersionDto, error)
+	AddVersion(modelId string, req *requests.ModelVersionRequest) (*ModelVersionDto, error)
+	ActivateVersion(modelId, versionId string) (*ModelDto, error)
+	StartCanary(modelId string, req *requests.CanaryRequest) (*ModelDto, error)
+	StopCanary(modelId string) (*ModelDto, error)
+	Rollback(modelId string) (*ModelDto, error)
+}
+
+type VersionServiceImpl struct {
+	repository ModelRepository
+}
+
+func NewVersionService(repository ModelRepository) *VersionServiceImpl {
+	return &VersionServiceImpl{repository: repository}
+}
+
+func (s *VersionServiceImpl) GetVersions(modelId string) ([]ModelVersionDto, error) {
+	logger.Logger.Info("Fetching model versions...", "modelId", modelId)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]ModelVersionDto, len(model.Versions))
+	for i, version := range model.Versions {
+		versions[i] = *ToModelVersionDto(&version)
+	}
+
+	logger.Logger.Info("Fetched model versions.", "modelId", modelId, "size", len(versions))
+	return versions, nil
+}
+
+func (s *VersionServiceImpl) AddVersion(modelId string, req *requests.ModelVersionRequest) (*ModelVersionDto, error) {
+	logger.Logger.Info("Adding model version...", "modelId", modelId, "url", req.Url)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	protocol := ModelProtocol(req.Protocol)
+	if protocol == "" {
+		protocol = ProtocolJson
+	}
+
+	version := &ModelVersion{
+		Number:        model.nextVersionNumber(),
+		Url:           req.Url,
+		Protocol:      protocol,
+		UpstreamModel: req.UpstreamModel,
+		Notes:         req.Notes,
+	}
+
+	if err := s.repository.SaveVersion(model, version, false); err != nil {
+		logger.Logger.Error("Failed to save model version", "modelId", modelId, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to save model version")
+	}
+
+	logger.Logger.Info("Added model version.", "modelId", modelId, "id", version.Id, "number", version.Number)
+	return ToModelVersionDto(version), nil
+}
+
+func (s *VersionServiceImpl) ActivateVersion(modelId, versionId string) (*ModelDto, error) {
+	logger.Logger.Info("Activating model version...", "modelId", modelId, "versionId", versionId)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	version := model.findVersion(versionId)
+	if version == nil {
+		logger.Logger.Error("Model version not found", "modelId", modelId, "versionId", versionId)
+		return nil, service_errors.NewErrNotFound("Model version not found")
+	}
+
+	if model.ActiveVersionId == version.Id {
+		logger.Logger.Error("Model version is already active", "modelId", modelId, "versionId", versionId)
+		return nil, service_errors.NewErrBadRequest("Model version is already active")
+	}
+
+	model.activate(version)
+	if err := s.save(model); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Activated model version.", "modelId", modelId, "versionId", versionId, "number", version.Number)
+	return ToModelDto(model), nil
+}
+
+func (s *VersionServiceImpl) StartCanary(modelId string, req *requests.CanaryRequest) (*ModelDto, error) {
+	logger.Logger.Info("Starting model canary...", "modelId", modelId, "versionId", req.VersionId, "percent", req.Percent)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	version := model.findVersion(req.VersionId)
+	if version == nil {
+		logger.Logger.Error("Model version not found", "modelId", modelId, "versionId", req.VersionId)
+		return nil, service_errors.NewErrNotFound("Model version not found")
+	}
+
+	if model.ActiveVersionId == version.Id {
+		logger.Logger.Error("Active version can't be a canary", "modelId", modelId, "versionId", req.VersionId)
+		return nil, service_errors.NewErrBadRequest("Active version can't be a canary")
+	}
+
+	model.CanaryVersionId = version.Id
+	model.CanaryPercent = req.Percent
+	if err := s.save(model); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Started model canary.", "modelId", modelId, "versionId", version.Id, "percent", req.Percent)
+	return ToModelDto(model), nil
+}
+
+func (s *VersionServiceImpl) StopCanary(modelId string) (*ModelDto, error) {
+	logger.Logger.Info("Stopping model canary...", "modelId", modelId)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.CanaryVersionId == "" {
+		logger.Logger.Error("Model has no canary", "modelId", modelId)
+		return nil, service_errors.NewErrBadRequest("Model has no canary")
+	}
+
+	model.CanaryVersionId = ""
+	model.CanaryPercent = 0
+	if err := s.save(model); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Stopped model canary.", "modelId", modelId)
+	return ToModelDto(model), nil
+}
+
+// Rollback undoes the latest rollout step: a running canary is stopped,
+// otherwise the previously active version is restored.
+func (s *VersionServiceImpl) Rollback(modelId string) (*ModelDto, error) {
+	logger.Logger.Info("Rolling back model...", "modelId", modelId)
+
+	model, err := s.findModel(modelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.CanaryVersionId != "" {
+		logger.Logger.Info("Rolling back model canary", "modelId", modelId, "versionId", model.CanaryVersionId)
+		model.CanaryVersionId = ""
+		model.CanaryPercent = 0
+	} else {
+		previous := model.findVersion(model.PreviousVersionId)
+		if previous == nil {
+			logger.Logger.Error("Model has no version to roll back to", "modelId", modelId)
+			return nil, service_errors.NewErrBadRequest("Model has no version to roll back to")
+		}
+
+		model.activate(previous)
+	}
+
+	if err := s.save(model); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("Rolled back model.", "modelId", modelId, "activeVersionId", model.ActiveVersionId)
+	return ToModelDto(model), nil
+}
+
+func (s *VersionServiceImpl) findModel(modelId string) (*Model, error) {
+	model, err := s.repository.FindById(modelId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Logger.Error("Model not found", "id", modelId)
+			return nil, service_errors.NewErrNotFound("Model not found")
+		}
+
+		logger.Logger.Error("Failed to fetch model", "id", modelId)
+		return nil, service_errors.NewErrInternalServer("Failed to fetch model")
+	}
+
+	if err := ensureInitialVersion(s.repository, model); err != nil {
+		logger.Logger.Error("Failed to create initial model version", "id", modelId, "error", err)
+		return nil, service_errors.NewErrInternalServer("Failed to create initial model version")
+	}
+
+	return model, nil
+}
+
+func (s *VersionServiceImpl) save(model *Model) error {
+	if err := s.repository.Save(model); err != nil {
+		logger.Logger.Error("Failed to update model", "id", model.Id, "error", err)
+		return service_errors.NewErrInternalServer("Failed to update model")
+	}
+
+	return nil
+}
+
+// models created before versioning get their current settings recorded as
+// version 1 the first time a version is needed
+func ensureInitialVersion(repository ModelRepository, model *Model) error {
+	if len(model.Versions) > 0 {
+		return nil
+	}
+
+	version := &ModelVersion{
+		Number:        1,
+		Url:           model.Url,
+		Protocol:      model.Protocol,
+		UpstreamModel: model.UpstreamModel,
+	}
+
+	return repository.SaveVersion(model, version, true)
+}
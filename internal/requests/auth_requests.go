@@ -20,10 +20,14 @@ type VerificationTokenRequest struct {
 }
 
 type EmailVerificationRequest struct {
-	Token    string `json:"token" validate:"required"`
+	Token string `json:"token" validate:"required"`
 }
 
 type PasswordResetRequest struct {
 	Token    string `json:"token" validate:"required"`
 	Password string `json:"password" validate:"required,min=8"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
@@ -0,0 +1,19 @@
+package requests
+
+type LexiconEntryRequest struct {
+	Language      string `json:"language" validate:"required,max=255"`
+	Scope         string `json:"scope" validate:"omitempty,oneof=user global"`
+	Grapheme      string `json:"grapheme" validate:"required,max=256"`
+	Type          string `json:"type" validate:"required,oneof=alias phoneme"`
+	Replacement   string `json:"replacement" validate:"required,max=1024"`
+	Alphabet      string `json:"alphabet" validate:"omitempty,oneof=ipa x-sampa"`
+	CaseSensitive bool   `json:"caseSensitive"`
+}
+
+type LexiconEntryUpdateRequest struct {
+	Grapheme      string `json:"grapheme" validate:"omitempty,max=256"`
+	Type          string `json:"type" validate:"omitempty,oneof=alias phoneme"`
+	Replacement   string `json:"replacement" validate:"omitempty,max=1024"`
+	Alphabet      string `json:"alphabet" validate:"omitempty,oneof=ipa x-sampa"`
+	CaseSensitive *bool  `json:"caseSensitive"`
+}
@@ -1,7 +1,51 @@
 package requests
 
 type ModelRequest struct {
-	Url      string `json:"url" validate:"required"`
-	Name     string `json:"name" validate:"required"`
-	Language string `json:"language" validate:"required"`
+	Url              string                    `json:"url" yaml:"url,omitempty" validate:"required"`
+	Name             string                    `json:"name" yaml:"name,omitempty" validate:"required"`
+	Language         string                    `json:"language" yaml:"language,omitempty" validate:"required"`
+	Description      string                    `json:"description" yaml:"description,omitempty" validate:"omitempty,max=2000"`
+	Gender           string                    `json:"gender" yaml:"gender,omitempty" validate:"omitempty,oneof=male female neutral"`
+	VoiceType        string                    `json:"voiceType" yaml:"voiceType,omitempty" validate:"omitempty,oneof=neural concatenative parametric"`
+	Tags             []string                  `json:"tags" yaml:"tags,omitempty" validate:"omitempty,max=32,dive,required,max=64"`
+	NativeSampleRate int                       `json:"nativeSampleRate" yaml:"nativeSampleRate,omitempty" validate:"omitempty,min=8000,max=192000"`
+	TimeoutMs        int                       `json:"timeoutMs" yaml:"timeoutMs,omitempty" validate:"omitempty,min=0,max=600000"`
+	LoadBalancing    string                    `json:"loadBalancing" yaml:"loadBalancing,omitempty" validate:"omitempty,oneof=round_robin least_outstanding"`
+	Protocol         string                    `json:"protocol" yaml:"protocol,omitempty" validate:"omitempty,oneof=json wav openai grpc"`
+	UpstreamModel    string                    `json:"upstreamModel" yaml:"upstreamModel,omitempty" validate:"omitempty,max=255"`
+	MaxInputLength   int                       `json:"maxInputLength" yaml:"maxInputLength,omitempty" validate:"omitempty,min=1,max=100000"`
+	Capabilities     *ModelCapabilitiesRequest `json:"capabilities" yaml:"capabilities,omitempty" validate:"omitempty"`
+}
+
+type ModelCapabilitiesRequest struct {
+	Rate     *ParameterRangeRequest `json:"rate" yaml:"rate,omitempty" validate:"omitempty"`
+	Pitch    *ParameterRangeRequest `json:"pitch" yaml:"pitch,omitempty" validate:"omitempty"`
+	Volume   *ParameterRangeRequest `json:"volume" yaml:"volume,omitempty" validate:"omitempty"`
+	Speakers []string               `json:"speakers" yaml:"speakers,omitempty" validate:"omitempty,dive,required,max=128"`
+	Styles   []string               `json:"styles" yaml:"styles,omitempty" validate:"omitempty,dive,required,max=64"`
+}
+
+type ParameterRangeRequest struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+type ReplicaRequest struct {
+	Url string `json:"url" validate:"required,url"`
+}
+
+type LanguageRuleRequest struct {
+	ModelId string `json:"modelId" validate:"required"`
+}
+
+type ModelVersionRequest struct {
+	Url           string `json:"url" validate:"required"`
+	Protocol      string `json:"protocol" validate:"omitempty,oneof=json wav openai grpc"`
+	UpstreamModel string `json:"upstreamModel" validate:"omitempty,max=255"`
+	Notes         string `json:"notes" validate:"omitempty,max=500"`
+}
+
+type CanaryRequest struct {
+	VersionId string `json:"versionId" validate:"required"`
+	Percent   int    `json:"percent" validate:"min=1,max=100"`
 }
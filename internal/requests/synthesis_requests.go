@@ -1,6 +1,52 @@
 package requests
 
 type SynthesisRequest struct {
-	Text    string `json:"text" validate:"required"`
+	Text     string `json:"text" validate:"required,max=100000"`
+	ModelId  string `json:"modelId" validate:"required_without=Language"`
+	Language string `json:"language" validate:"omitempty,max=35"`
+	Format   string `json:"format" validate:"omitempty,oneof=json wav mp3 ogg flac"`
+	TextType string `json:"textType" validate:"omitempty,oneof=text ssml"`
+	VoiceOptions
+	AudioOptions
+}
+
+type SynthesisJobRequest struct {
+	Text     string `json:"text" validate:"required,max=100000"`
+	ModelId  string `json:"modelId" validate:"required"`
+	Format   string `json:"format" validate:"omitempty,oneof=wav mp3 ogg flac"`
+	TextType string `json:"textType" validate:"omitempty,oneof=text ssml"`
+	VoiceOptions
+}
+
+type VoiceOptions struct {
+	Rate    float64 `json:"rate" validate:"omitempty,min=0.25,max=4"`
+	Pitch   float64 `json:"pitch" validate:"omitempty,min=-24,max=24"`
+	Volume  float64 `json:"volume" validate:"omitempty,min=-96,max=24"`
+	Speaker string  `json:"speaker" validate:"omitempty,max=128"`
+	Style   string  `json:"style" validate:"omitempty,max=64"`
+}
+
+type AudioOptions struct {
+	SampleRate  int     `json:"sampleRate" validate:"omitempty,min=8000,max=96000"`
+	TrimSilence bool    `json:"trimSilence"`
+	Loudness    float64 `json:"loudness" validate:"omitempty,min=-70,max=-1"`
+	BitDepth    int     `json:"bitDepth" validate:"omitempty,oneof=8 16 24 32"`
+}
+
+type NormalizationRequest struct {
+	Text     string `json:"text" validate:"required,max=100000"`
+	Language string `json:"language" validate:"required_without=ModelId"`
+	ModelId  string `json:"modelId"`
+}
+
+type BatchSynthesisRequest struct {
+	Items  []BatchSynthesisItem `json:"items" validate:"required,min=1,max=500,dive"`
+	Format string               `json:"format" validate:"omitempty,oneof=wav mp3 ogg flac"`
+	Output string               `json:"output" validate:"omitempty,oneof=zip json"`
+}
+
+type BatchSynthesisItem struct {
+	Id      string `json:"id" validate:"required,max=128"`
+	Text    string `json:"text" validate:"required,max=10000"`
 	ModelId string `json:"modelId" validate:"required"`
 }
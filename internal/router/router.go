@@ -2,11 +2,16 @@ package router
 
 import (
 	"vitaliiPsl/synthesizer/internal/auth"
+	"vitaliiPsl/synthesizer/internal/batch"
+	"vitaliiPsl/synthesizer/internal/cache"
 	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/job"
+	"vitaliiPsl/synthesizer/internal/lexicon"
 	"vitaliiPsl/synthesizer/internal/model"
 	"vitaliiPsl/synthesizer/internal/synthesis"
 	"vitaliiPsl/synthesizer/internal/users"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
@@ -18,6 +23,10 @@ func SetupRoutes(
 	modelController *model.ModelController,
 	synthesisController *synthesis.SynthesisController,
 	historyController *history.HistoryController,
+	jobController *job.JobController,
+	cacheController *cache.CacheController,
+	lexiconController *lexicon.LexiconController,
+	batchController *batch.BatchController,
 ) {
 
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -35,6 +44,9 @@ func SetupRoutes(
 	authApi.Post("/sign-in", authController.HandleSignIn)
 	authApi.Get("/sso/:provider", authController.HandleSsoSignIn)
 	authApi.Post("/sso/:provider/sign-in", authController.HandleSsoCallback)
+	authApi.Post("/refresh", authController.HandleRefresh)
+	authApi.Post("/logout", authMiddleware.ProtectedRoute(), authController.HandleLogout)
+	authApi.Post("/logout-all", authMiddleware.ProtectedRoute(), authController.HandleLogoutAll)
 	authApi.Post("/verify-email", authController.HandleEmailVerification)
 	authApi.Post("/reset-password", authController.HandleResetPassword)
 	authApi.Post("/send-password-reset-email", authController.HandleSendPasswordResetToken)
@@ -44,12 +56,50 @@ func SetupRoutes(
 	modelApi.Patch(":id", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleUpdateModel)
 	modelApi.Delete(":id", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleDeleteModel)
 	modelApi.Get("", authMiddleware.OpenRoute(), modelController.HandleFetchModels)
+	modelApi.Get("registry", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleExportRegistry)
+	modelApi.Put("registry", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleImportRegistry)
+	modelApi.Get("languages", authMiddleware.OpenRoute(), modelController.HandleFetchLanguageRules)
+	modelApi.Put("languages/:language", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleSetLanguageRule)
+	modelApi.Delete("languages/:language", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleDeleteLanguageRule)
+	modelApi.Get("archived", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleFetchArchivedModels)
+	modelApi.Post(":id/restore", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleRestoreModel)
+	modelApi.Delete(":id/purge", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandlePurgeModel)
+	modelApi.Get(":id", authMiddleware.OpenRoute(), modelController.HandleFetchModel)
+	modelApi.Get(":id/preview", authMiddleware.OpenRoute(), modelController.HandleFetchModelPreview)
+	modelApi.Put(":id/preview", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleSaveModelPreview)
+	modelApi.Get(":id/replicas", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleFetchReplicas)
+	modelApi.Post(":id/replicas", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleAddReplica)
+	modelApi.Delete(":id/replicas/:replicaId", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleDeleteReplica)
+	modelApi.Get(":id/versions", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleFetchVersions)
+	modelApi.Post(":id/versions", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleAddVersion)
+	modelApi.Post(":id/versions/:versionId/activate", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleActivateVersion)
+	modelApi.Put(":id/canary", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleStartCanary)
+	modelApi.Delete(":id/canary", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleStopCanary)
+	modelApi.Post(":id/rollback", authMiddleware.ProtectedRoute(users.RoleAdmin), modelController.HandleRollback)
 
 	synthesisApi := api.Group("/synthesis")
 	synthesisApi.Post("", authMiddleware.OpenRoute(), synthesisController.HandleSynthesis)
+	synthesisApi.Post("/batch", authMiddleware.ProtectedRoute(), batchController.HandleBatchSynthesis)
+	synthesisApi.Post("/normalize", authMiddleware.OpenRoute(), synthesisController.HandleNormalization)
+	synthesisApi.Post("/stream", authMiddleware.OpenRoute(), synthesisController.HandleStreamingSynthesis)
+	synthesisApi.Post("/subtitles", authMiddleware.OpenRoute(), synthesisController.HandleSubtitles)
+	synthesisApi.Get("/stream", synthesisController.RequireWebSocketUpgrade, authMiddleware.OpenRoute(), websocket.New(synthesisController.HandleStreamingSynthesisSocket))
+	synthesisApi.Get("/cache/stats", authMiddleware.ProtectedRoute(users.RoleAdmin), cacheController.HandleFetchCacheStats)
+	synthesisApi.Delete("/cache", authMiddleware.ProtectedRoute(users.RoleAdmin), cacheController.HandleClearCache)
+	synthesisApi.Post("/jobs", authMiddleware.ProtectedRoute(), jobController.HandleCreateJob)
+	synthesisApi.Get("/jobs/:id", authMiddleware.ProtectedRoute(), jobController.HandleFetchJob)
+	synthesisApi.Get("/jobs/:id/audio", authMiddleware.ProtectedRoute(), jobController.HandleFetchJobAudio)
+
+	lexiconApi := api.Group("/lexicons")
+	lexiconApi.Get("", authMiddleware.ProtectedRoute(), lexiconController.HandleFetchLexicons)
+	lexiconApi.Post("/entries", authMiddleware.ProtectedRoute(), lexiconController.HandleCreateEntry)
+	lexiconApi.Patch("/entries/:id", authMiddleware.ProtectedRoute(), lexiconController.HandleUpdateEntry)
+	lexiconApi.Delete("/entries/:id", authMiddleware.ProtectedRoute(), lexiconController.HandleDeleteEntry)
 
 	historyApi := api.Group("/history")
 	historyApi.Get("", authMiddleware.ProtectedRoute(), historyController.HandleFetchHistory)
 	historyApi.Delete("", authMiddleware.ProtectedRoute(), historyController.DeleteHistory)
+	historyApi.Get(":id/audio", authMiddleware.ProtectedRoute(), historyController.HandleFetchHistoryRecordAudio)
+	historyApi.Get(":id/subtitles", authMiddleware.ProtectedRoute(), historyController.HandleFetchHistoryRecordSubtitles)
 	historyApi.Delete(":id", authMiddleware.ProtectedRoute(), historyController.DeleteHistoryRecord)
 }
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"vitaliiPsl/synthesizer/internal/logger"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+type BlobStorage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+func NewBlobStorage() BlobStorage {
+	backend := os.Getenv("BLOB_STORAGE_BACKEND")
+
+	switch backend {
+	case "s3":
+		return NewS3BlobStorage()
+	case "", "local":
+		return NewLocalBlobStorage()
+	default:
+		logger.Logger.Error("Unknown blob storage backend", "backend", backend)
+		panic("unknown BLOB_STORAGE_BACKEND: " + backend)
+	}
+}
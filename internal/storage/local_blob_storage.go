@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultLocalStoragePath = "./data/blobs"
+
+type LocalBlobStorage struct {
+	root string
+}
+
+func NewLocalBlobStorage() *LocalBlobStorage {
+	root := os.Getenv("BLOB_STORAGE_PATH")
+	if root == "" {
+		root = defaultLocalStoragePath
+	}
+
+	return &LocalBlobStorage{root: root}
+}
+
+func (s *LocalBlobStorage) Put(key string, data []byte, contentType string) error {
+	path, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalBlobStorage) Get(key string) ([]byte, error) {
+	path, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	return data, err
+}
+
+func (s *LocalBlobStorage) Delete(key string) error {
+	path, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *LocalBlobStorage) resolve(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.root, cleaned), nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalBlobStorage(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("BLOB_STORAGE_PATH", root)
+	storage := NewLocalBlobStorage()
+
+	if err := storage.Put("previews/model-1.wav", []byte("audio"), "audio/wav"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "previews", "model-1.wav.tmp")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected temporary file to be renamed")
+	}
+
+	if err := storage.Put("previews/model-1.wav", []byte("updated"), "audio/wav"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := storage.Get("/previews/model-1.wav")
+	if err != nil || string(data) != "updated" {
+		t.Errorf("expected overwritten data, got %q (%v)", data, err)
+	}
+
+	if err := storage.Delete("previews/model-1.wav"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.Get("previews/model-1.wav"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+	if err := storage.Delete("previews/model-1.wav"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestLocalBlobStorageRejectsEscapingKeys(t *testing.T) {
+	t.Setenv("BLOB_STORAGE_PATH", t.TempDir())
+	storage := NewLocalBlobStorage()
+
+	for _, key := range []string{"", "/", "../secret", "previews/../../secret"} {
+		if err := storage.Put(key, []byte("x"), ""); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+		if _, err := storage.Get(key); err == nil {
+			t.Errorf("expected get of %q to fail", key)
+		}
+	}
+}
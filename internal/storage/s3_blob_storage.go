@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"vitaliiPsl/synthesizer/internal/logger"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DefaultRegion = "us-east-1"
+	s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+)
+
+type S3BlobStorage struct {
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyId     string
+	secretAccessKey string
+	client          *http.Client
+}
+
+func NewS3BlobStorage() *S3BlobStorage {
+	endpoint, err := url.Parse(os.Getenv("S3_ENDPOINT"))
+	if err != nil || endpoint.Host == "" {
+		logger.Logger.Error("Invalid S3 endpoint", "endpoint", os.Getenv("S3_ENDPOINT"))
+		panic("invalid S3_ENDPOINT")
+	}
+
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	return &S3BlobStorage{
+		endpoint:        endpoint,
+		bucket:          os.Getenv("S3_BUCKET"),
+		region:          region,
+		accessKeyId:     os.Getenv("S3_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		client:          &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *S3BlobStorage) Put(key string, data []byte, contentType string) error {
+	res, err := s.do(http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return s.responseError(res)
+	}
+
+	return nil
+}
+
+func (s *S3BlobStorage) Get(key string) ([]byte, error) {
+	res, err := s.do(http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrBlobNotFound
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, s.responseError(res)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (s *S3BlobStorage) Delete(key string) error {
+	res, err := s.do(http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return s.responseError(res)
+	}
+
+	return nil
+}
+
+func (s *S3BlobStorage) do(method, key string, body []byte, contentType string) (*http.Response, error) {
+	objectUrl := *s.endpoint
+	objectUrl.Path = strings.TrimRight(objectUrl.Path, "/") + "/" + s.bucket + "/" + strings.TrimLeft(key, "/")
+
+	req, err := http.NewRequest(method, objectUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.sign(req, body, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+func (s *S3BlobStorage) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + s.region + "/" + s3Service + "/aws4_request"
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+s.secretAccessKey), dateStamp)
+	signingKey = hmacSha256(signingKey, s.region)
+	signingKey = hmacSha256(signingKey, s3Service)
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKeyId, scope, s3SignedHeaders, signature))
+}
+
+func (s *S3BlobStorage) responseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 %s %s responded with status %d: %s", res.Request.Method, res.Request.URL.Path, res.StatusCode, body)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
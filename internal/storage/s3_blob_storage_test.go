@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAccessKeyId     = "AKIDEXAMPLE"
+	testSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+// fakeS3 is a minimal MinIO-style stub: path-style buckets, objects kept in
+// memory, and every request's signature checked against the shared secret.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	failing bool
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if reason := f.verify(r, body); reason != "" {
+		f.t.Errorf("%s %s: %s", r.Method, r.URL.Path, reason)
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	if f.failing {
+		http.Error(w, "<Error><Code>InternalError</Code></Error>", http.StatusInternalServerError)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeS3) verify(r *http.Request, body []byte) string {
+	match := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if match == nil {
+		return "malformed authorization header: " + r.Header.Get("Authorization")
+	}
+
+	accessKeyId, date, region, signedHeaders, signature := match[1], match[2], match[3], match[4], match[5]
+	if accessKeyId != testAccessKeyId || region != "eu-central-1" {
+		return "unexpected credential scope"
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, date) {
+		return "credential date doesn't match x-amz-date"
+	}
+
+	digest := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(digest[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return "payload hash doesn't match body"
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := r.Method + "\n" + r.URL.EscapedPath() + "\n" + r.URL.RawQuery + "\n" +
+		canonicalHeaders.String() + "\n" + signedHeaders + "\n" + payloadHash
+	requestDigest := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestDigest[:])
+
+	key := []byte("AWS4" + testSecretAccessKey)
+	for _, part := range []string{date, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+
+	if hex.EncodeToString(key) != signature {
+		return "signature mismatch"
+	}
+
+	return ""
+}
+
+func newTestS3(t *testing.T) (*S3BlobStorage, *fakeS3) {
+	fake := &fakeS3{t: t, objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	t.Setenv("S3_ENDPOINT", server.URL)
+	t.Setenv("S3_BUCKET", "synthesizer")
+	t.Setenv("S3_REGION", "eu-central-1")
+	t.Setenv("S3_ACCESS_KEY_ID", testAccessKeyId)
+	t.Setenv("S3_SECRET_ACCESS_KEY", testSecretAccessKey)
+
+	return NewS3BlobStorage(), fake
+}
+
+func TestS3BlobStorage(t *testing.T) {
+	storage, fake := newTestS3(t)
+
+	data := []byte("RIFF....WAVE")
+	if err := storage.Put("history/user 1/record.wav", data, "audio/wav"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := "/synthesizer/history/user 1/record.wav"
+	if string(fake.objects[stored]) != string(data) || fake.types[stored] != "audio/wav" {
+		t.Errorf("unexpected stored object %q (%s)", fake.objects[stored], fake.types[stored])
+	}
+
+	fetched, err := storage.Get("/history/user 1/record.wav")
+	if err != nil || string(fetched) != string(data) {
+		t.Errorf("expected stored data, got %q (%v)", fetched, err)
+	}
+
+	if err := storage.Delete("history/user 1/record.wav"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("expected object to be deleted, got %v", fake.objects)
+	}
+
+	if _, err := storage.Get("history/user 1/record.wav"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+
+	if err := storage.Delete("missing"); err != nil {
+		t.Errorf("expected deleting a missing object to succeed, got %v", err)
+	}
+}
+
+func TestS3BlobStorageErrors(t *testing.T) {
+	storage, fake := newTestS3(t)
+	fake.failing = true
+
+	if err := storage.Put("key", []byte("data"), ""); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("expected put to fail with the status, got %v", err)
+	}
+	if _, err := storage.Get("key"); err == nil || errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected get to fail, got %v", err)
+	}
+	if err := storage.Delete("key"); err == nil {
+		t.Error("expected delete to fail")
+	}
+}
@@ -0,0 +1,52 @@
+package subtitles
+
+import "fmt"
+
+type Format string
+
+const (
+	FormatSrt    Format = "srt"
+	FormatWebVtt Format = "vtt"
+)
+
+type Granularity string
+
+const (
+	GranularitySentence Granularity = "sentence"
+	GranularityWord     Granularity = "word"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "":
+		return FormatWebVtt, nil
+	case FormatSrt, FormatWebVtt:
+		return Format(value), nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle format: %s", value)
+	}
+}
+
+func ParseGranularity(value string) (Granularity, error) {
+	switch Granularity(value) {
+	case "":
+		return GranularitySentence, nil
+	case GranularitySentence, GranularityWord:
+		return Granularity(value), nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle granularity: %s", value)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatSrt:
+		return "application/x-subrip; charset=utf-8"
+	default:
+		return "text/vtt; charset=utf-8"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
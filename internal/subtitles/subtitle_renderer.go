@@ -0,0 +1,54 @@
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render produces SRT or WebVTT; words are used for word granularity when
+// the model provided them, sentences otherwise
+func Render(format Format, granularity Granularity, sentences, words []Timing) string {
+	cues := sentences
+	if granularity == GranularityWord && len(words) > 0 {
+		cues = words
+	}
+
+	var builder strings.Builder
+	if format == FormatWebVtt {
+		builder.WriteString("WEBVTT\n\n")
+	}
+
+	for i, cue := range cues {
+		if format == FormatSrt {
+			fmt.Fprintf(&builder, "%d\n", i+1)
+		}
+
+		fmt.Fprintf(&builder, "%s --> %s\n%s\n\n", timestamp(cue.StartMs, format), timestamp(max(cue.EndMs, cue.StartMs), format), escape(cue.Text, format))
+	}
+
+	return builder.String()
+}
+
+func timestamp(ms int, format Format) string {
+	ms = max(ms, 0)
+	hours, ms := ms/3_600_000, ms%3_600_000
+	minutes, ms := ms/60_000, ms%60_000
+	seconds, ms := ms/1000, ms%1000
+
+	separator := "."
+	if format == FormatSrt {
+		separator = ","
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, seconds, separator, ms)
+}
+
+// cue text can't contain blank lines, and WebVTT reserves "-->" and markup characters
+func escape(text string, format Format) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if format == FormatWebVtt {
+		text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	}
+
+	return text
+}
@@ -0,0 +1,93 @@
+package subtitles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var goldenSentences = []Timing{
+	{Text: "Hello there.", StartMs: 0, EndMs: 1250},
+	{Text: "This cue spans\n\ntwo lines & <tags>.", StartMs: 1250, EndMs: 59_999},
+	{Text: "Past one hour.", StartMs: 3_600_000, EndMs: 3_723_004},
+	{Text: "Backwards end.", StartMs: 99_999_999, EndMs: 5},
+}
+
+var goldenWords = []Timing{
+	{Text: "Hello", StartMs: 0, EndMs: 400},
+	{Text: "there.", StartMs: 450, EndMs: 1250},
+}
+
+func TestRenderGolden(t *testing.T) {
+	cases := []struct {
+		golden      string
+		format      Format
+		granularity Granularity
+		words       []Timing
+	}{
+		{golden: "sentences.srt", format: FormatSrt, granularity: GranularitySentence, words: goldenWords},
+		{golden: "sentences.vtt", format: FormatWebVtt, granularity: GranularitySentence, words: goldenWords},
+		{golden: "words.vtt", format: FormatWebVtt, granularity: GranularityWord, words: goldenWords},
+		// without word timings word granularity falls back to sentences
+		{golden: "sentences.vtt", format: FormatWebVtt, granularity: GranularityWord},
+	}
+
+	for _, c := range cases {
+		expected, err := os.ReadFile(filepath.Join("testdata", c.golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rendered := Render(c.format, c.granularity, goldenSentences, c.words); rendered != string(expected) {
+			t.Errorf("%s (%s): expected\n%s\ngot\n%s", c.golden, c.granularity, expected, rendered)
+		}
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if rendered := Render(FormatWebVtt, GranularitySentence, nil, nil); rendered != "WEBVTT\n\n" {
+		t.Errorf("expected a bare header, got %q", rendered)
+	}
+	if rendered := Render(FormatSrt, GranularitySentence, nil, nil); rendered != "" {
+		t.Errorf("expected nothing, got %q", rendered)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	cases := []struct {
+		ms       int
+		format   Format
+		expected string
+	}{
+		{ms: 0, format: FormatSrt, expected: "00:00:00,000"},
+		{ms: -20, format: FormatWebVtt, expected: "00:00:00.000"},
+		{ms: 61_001, format: FormatWebVtt, expected: "00:01:01.001"},
+		{ms: 3_599_999, format: FormatSrt, expected: "00:59:59,999"},
+		{ms: 3_600_000, format: FormatSrt, expected: "01:00:00,000"},
+		{ms: 36_000_000 + 1, format: FormatWebVtt, expected: "10:00:00.001"},
+		{ms: 360_000_000, format: FormatSrt, expected: "100:00:00,000"},
+	}
+
+	for _, c := range cases {
+		if stamp := timestamp(c.ms, c.format); stamp != c.expected {
+			t.Errorf("%d (%s): expected %s, got %s", c.ms, c.format, c.expected, stamp)
+		}
+	}
+}
+
+func TestOffsetAndScale(t *testing.T) {
+	timings := []Timing{{Text: "a", StartMs: 0, EndMs: 100}, {Text: "b", StartMs: 100, EndMs: 300}}
+
+	shifted := Offset(timings, 3_600_000)
+	if shifted[1].StartMs != 3_600_100 || shifted[1].EndMs != 3_600_300 || timings[1].StartMs != 100 {
+		t.Errorf("expected a shifted copy, got %+v from %+v", shifted, timings)
+	}
+	if Offset(nil, 10) != nil {
+		t.Error("expected no timings to stay empty")
+	}
+
+	scaled := Scale(timings, 0.5)
+	if scaled[1].StartMs != 50 || scaled[1].EndMs != 150 || timings[1].EndMs != 300 {
+		t.Errorf("expected a scaled copy, got %+v from %+v", scaled, timings)
+	}
+}
@@ -0,0 +1,33 @@
+package subtitles
+
+type Timing struct {
+	Text    string `json:"text"`
+	StartMs int    `json:"start_ms"`
+	EndMs   int    `json:"end_ms"`
+}
+
+func Offset(timings []Timing, offsetMs int) []Timing {
+	if len(timings) == 0 {
+		return nil
+	}
+
+	shifted := make([]Timing, len(timings))
+	for i, timing := range timings {
+		shifted[i] = Timing{Text: timing.Text, StartMs: timing.StartMs + offsetMs, EndMs: timing.EndMs + offsetMs}
+	}
+
+	return shifted
+}
+
+func Scale(timings []Timing, factor float64) []Timing {
+	if len(timings) == 0 || factor == 1 {
+		return timings
+	}
+
+	scaled := make([]Timing, len(timings))
+	for i, timing := range timings {
+		scaled[i] = Timing{Text: timing.Text, StartMs: int(float64(timing.StartMs) * factor), EndMs: int(float64(timing.EndMs) * factor)}
+	}
+
+	return scaled
+}
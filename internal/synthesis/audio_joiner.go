@@ -0,0 +1,70 @@
+package synthesis
+
+import "time"
+
+const (
+	defaultSentenceSilence = 120 * time.Millisecond
+	defaultCrossfade       = 10 * time.Millisecond
+)
+
+// audioJoiner concatenates synthesized pieces: pieces that end a sentence are
+// followed by a short pause, everything else is crossfaded into the next piece
+type audioJoiner struct {
+	sentenceSilence time.Duration
+	crossfade       time.Duration
+}
+
+func newAudioJoiner() *audioJoiner {
+	return &audioJoiner{
+		sentenceSilence: nonNegativeDurationFromEnv("SYNTHESIS_SENTENCE_SILENCE_MS", defaultSentenceSilence),
+		crossfade:       nonNegativeDurationFromEnv("SYNTHESIS_CROSSFADE_MS", defaultCrossfade),
+	}
+}
+
+// gap between the previous and the next piece; explicit breaks take precedence
+func (j *audioJoiner) gap(previousText string, explicitBreak float64, hasBreak bool) float64 {
+	if hasBreak {
+		return explicitBreak
+	}
+
+	if endsSentence(previousText) {
+		return j.sentenceSilence.Seconds()
+	}
+
+	return 0
+}
+
+// join appends next to dst, which must be owned by the caller; next is never modified
+func (j *audioJoiner) join(dst, next []float32, gap float64, samplingRate int) []float32 {
+	fade := min(int(j.crossfade.Seconds()*float64(samplingRate)), len(dst), len(next))
+
+	if gap > 0 || fade == 0 {
+		fadeOut(dst, fade)
+		dst = append(dst, silence(gap, samplingRate)...)
+		start := len(dst)
+		dst = append(dst, next...)
+		fadeIn(dst[start:], fade)
+		return dst
+	}
+
+	overlap := dst[len(dst)-fade:]
+	for i := range overlap {
+		weight := float32(i+1) / float32(fade+1)
+		overlap[i] = overlap[i]*(1-weight) + next[i]*weight
+	}
+
+	return append(dst, next[fade:]...)
+}
+
+func fadeOut(samples []float32, length int) {
+	tail := samples[len(samples)-length:]
+	for i := range tail {
+		tail[i] *= float32(length-i) / float32(length+1)
+	}
+}
+
+func fadeIn(samples []float32, length int) {
+	for i := 0; i < length; i++ {
+		samples[i] *= float32(i+1) / float32(length+1)
+	}
+}
@@ -0,0 +1,148 @@
+package synthesis
+
+import (
+	"testing"
+	"time"
+)
+
+// at 1000 Hz one sample is one millisecond
+const joinerRate = 1000
+
+func newTestJoiner() *audioJoiner {
+	return &audioJoiner{sentenceSilence: 100 * time.Millisecond, crossfade: 10 * time.Millisecond}
+}
+
+func constant(value float32, length int) []float32 {
+	samples := make([]float32, length)
+	for i := range samples {
+		samples[i] = value
+	}
+	return samples
+}
+
+func TestAudioJoinerGap(t *testing.T) {
+	joiner := newTestJoiner()
+
+	cases := []struct {
+		name     string
+		previous string
+		brk      float64
+		hasBreak bool
+		expected float64
+	}{
+		{name: "sentence end", previous: "Done.", expected: 0.1},
+		{name: "mid sentence", previous: "first part,", expected: 0},
+		{name: "explicit break", previous: "first part,", brk: 0.5, hasBreak: true, expected: 0.5},
+		{name: "explicit zero break", previous: "Done.", hasBreak: true, expected: 0},
+	}
+
+	for _, c := range cases {
+		if gap := joiner.gap(c.previous, c.brk, c.hasBreak); gap != c.expected {
+			t.Errorf("%s: expected %g, got %g", c.name, c.expected, gap)
+		}
+	}
+}
+
+func TestAudioJoinerInsertsPause(t *testing.T) {
+	joiner := newTestJoiner()
+	next := constant(1, 50)
+
+	joined := joiner.join(constant(1, 50), next, 0.1, joinerRate)
+
+	if len(joined) != 200 {
+		t.Fatalf("expected 50 + 100 + 50 samples, got %d", len(joined))
+	}
+	for i := 50; i < 150; i++ {
+		if joined[i] != 0 {
+			t.Fatalf("expected silence at %d, got %g", i, joined[i])
+		}
+	}
+	// the edges around the pause are faded
+	if joined[49] >= 1 || joined[150] >= 1 || joined[39] != 1 || joined[160] != 1 {
+		t.Errorf("unexpected fades: %g %g %g %g", joined[39], joined[49], joined[150], joined[160])
+	}
+	if next[0] != 1 {
+		t.Error("expected next to stay unmodified")
+	}
+}
+
+func TestAudioJoinerCrossfades(t *testing.T) {
+	joiner := newTestJoiner()
+	next := constant(-1, 50)
+
+	joined := joiner.join(constant(1, 50), next, 0, joinerRate)
+
+	if len(joined) != 90 {
+		t.Fatalf("expected the 10 sample crossfade to overlap, got %d samples", len(joined))
+	}
+	for i := 41; i < 50; i++ {
+		if joined[i] >= joined[i-1] {
+			t.Fatalf("expected the overlap to move from dst to next, got %g after %g", joined[i], joined[i-1])
+		}
+	}
+	if joined[39] != 1 || joined[50] != -1 {
+		t.Errorf("expected untouched samples outside the overlap, got %g and %g", joined[39], joined[50])
+	}
+	if next[0] != -1 {
+		t.Error("expected next to stay unmodified")
+	}
+}
+
+func TestAudioJoinerCrossfadeLongerThanSegment(t *testing.T) {
+	joiner := newTestJoiner()
+
+	// next is shorter than the crossfade and blends into the tail of dst
+	joined := joiner.join(constant(1, 50), constant(-1, 4), 0, joinerRate)
+	if len(joined) != 50 {
+		t.Errorf("expected a short piece to be absorbed by the crossfade, got %d samples", len(joined))
+	}
+
+	// dst is shorter than the crossfade
+	joined = joiner.join(constant(1, 3), constant(-1, 50), 0, joinerRate)
+	if len(joined) != 50 {
+		t.Errorf("expected the crossfade to shrink to the short piece, got %d samples", len(joined))
+	}
+
+	// an empty piece leaves dst as it is
+	joined = joiner.join(constant(1, 5), nil, 0, joinerRate)
+	if len(joined) != 5 || joined[4] != 1 {
+		t.Errorf("expected an empty piece to change nothing, got %v", joined)
+	}
+
+	// with a pause, fades shrink to the short pieces as well
+	joined = joiner.join(constant(1, 3), constant(-1, 4), 0.01, joinerRate)
+	if len(joined) != 17 {
+		t.Errorf("expected 3 + 10 + 4 samples, got %d", len(joined))
+	}
+}
+
+func TestNewAudioJoinerFromEnv(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     string
+		silence   time.Duration
+		crossfade time.Duration
+	}{
+		{name: "unset", value: "", silence: defaultSentenceSilence, crossfade: defaultCrossfade},
+		{name: "disabled", value: "0", silence: 0, crossfade: 0},
+		{name: "custom", value: "250", silence: 250 * time.Millisecond, crossfade: 250 * time.Millisecond},
+		{name: "negative", value: "-5", silence: defaultSentenceSilence, crossfade: defaultCrossfade},
+		{name: "invalid", value: "soon", silence: defaultSentenceSilence, crossfade: defaultCrossfade},
+	}
+
+	for _, c := range cases {
+		t.Setenv("SYNTHESIS_SENTENCE_SILENCE_MS", c.value)
+		t.Setenv("SYNTHESIS_CROSSFADE_MS", c.value)
+
+		joiner := newAudioJoiner()
+		if joiner.sentenceSilence != c.silence || joiner.crossfade != c.crossfade {
+			t.Errorf("%s: expected %v silence and %v crossfade, got %v and %v", c.name, c.silence, c.crossfade, joiner.sentenceSilence, joiner.crossfade)
+		}
+	}
+
+	t.Setenv("SYNTHESIS_CROSSFADE_MS", "0")
+	joined := newAudioJoiner().join(constant(1, 20), constant(0.5, 20), 0, joinerRate)
+	if len(joined) != 40 || joined[20] != 0.5 {
+		t.Errorf("expected a plain concatenation without crossfade, got %d samples", len(joined))
+	}
+}
@@ -0,0 +1,74 @@
+package synthesis
+
+import (
+	"net/http/httptest"
+	"testing"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/requests"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type wavOnlyAudioService struct {
+	audio.AudioService
+}
+
+func (s *wavOnlyAudioService) Supports(format audio.AudioFormat) bool {
+	return format == audio.FormatWav
+}
+
+func TestResolveFormat(t *testing.T) {
+	controller := &SynthesisController{audioService: &wavOnlyAudioService{}}
+
+	cases := []struct {
+		name     string
+		accept   string
+		format   string
+		expected audio.AudioFormat
+		status   int
+	}{
+		{name: "default", expected: audio.FormatJson, status: fiber.StatusOK},
+		{name: "any", accept: "*/*", expected: audio.FormatJson, status: fiber.StatusOK},
+		{name: "wav", accept: "audio/wav", expected: audio.FormatWav, status: fiber.StatusOK},
+		{name: "wav alias", accept: "audio/x-wav", expected: audio.FormatWav, status: fiber.StatusOK},
+		{name: "quality order", accept: "audio/mpeg;q=1, audio/wav;q=0.5", expected: audio.FormatWav, status: fiber.StatusOK},
+		{name: "audio wildcard", accept: "audio/*", expected: audio.FormatWav, status: fiber.StatusOK},
+		{name: "unknown type", accept: "audio/aac", status: fiber.StatusNotAcceptable},
+		{name: "encoder missing", accept: "audio/mpeg", status: fiber.StatusNotAcceptable},
+		{name: "explicit format", accept: "audio/aac", format: "wav", expected: audio.FormatWav, status: fiber.StatusOK},
+		{name: "explicit unknown format", format: "aac", status: fiber.StatusBadRequest},
+		{name: "explicit format without encoder", format: "mp3", status: fiber.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		app := fiber.New(fiber.Config{ErrorHandler: service_errors.ErrorHandler})
+		var resolved audio.AudioFormat
+		app.Get("/", func(ctx *fiber.Ctx) error {
+			format, err := controller.resolveFormat(ctx, &requests.SynthesisRequest{Format: c.format})
+			if err != nil {
+				return err
+			}
+
+			resolved = format
+			return ctx.SendStatus(fiber.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, res.StatusCode)
+		}
+		if c.status == fiber.StatusOK && resolved != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, resolved)
+		}
+	}
+}
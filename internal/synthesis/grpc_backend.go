@@ -0,0 +1,108 @@
+package synthesis
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"vitaliiPsl/synthesizer/internal/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const grpcSynthesizeMethod = "/synthesizer.v1.Synthesizer/Synthesize"
+
+// GrpcBackend calls the Synthesize rpc from synthesizer.proto. Urls use the
+// grpc:// scheme, or grpcs:// for TLS. Connections are kept per target.
+type GrpcBackend struct {
+	mu          sync.Mutex
+	connections map[string]*grpc.ClientConn
+}
+
+func NewGrpcBackend() *GrpcBackend {
+	return &GrpcBackend{connections: make(map[string]*grpc.ClientConn)}
+}
+
+func (b *GrpcBackend) Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	conn, err := b.connection(url)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &grpcSynthesizeRequest{
+		Text:            text,
+		Model:           model.UpstreamModel,
+		Rate:            params.Rate,
+		Pitch:           params.Pitch,
+		VolumeDb:        params.VolumeDb,
+		Speaker:         params.Speaker,
+		Style:           params.Style,
+		Phonemes:        params.Phonemes,
+		PhonemeAlphabet: params.PhonemeAlphabet,
+	}
+
+	var response grpcSynthesizeResponse
+	if err := conn.Invoke(ctx, grpcSynthesizeMethod, request, &response, grpc.ForceCodec(grpcCodec{})); err != nil {
+		return nil, &upstreamError{statusCode: httpStatusFromGrpc(status.Code(err)), err: err}
+	}
+
+	return &SynthesisResponse{
+		Samples:      response.Samples,
+		SamplingRate: int(response.SamplingRate),
+		Channels:     int(response.Channels),
+		Words:        response.Words,
+		Sentences:    response.Sentences,
+	}, nil
+}
+
+func (b *GrpcBackend) connection(url string) (*grpc.ClientConn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if conn, ok := b.connections[url]; ok {
+		return conn, nil
+	}
+
+	transport := insecure.NewCredentials()
+	target := strings.TrimPrefix(url, "grpc://")
+	if strings.HasPrefix(url, "grpcs://") {
+		transport = credentials.NewTLS(nil)
+		target = strings.TrimPrefix(url, "grpcs://")
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, err
+	}
+
+	b.connections[url] = conn
+	return conn, nil
+}
+
+// maps status codes onto the http statuses the retry and breaker logic knows
+func httpStatusFromGrpc(code codes.Code) int {
+	switch code {
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,269 @@
+package synthesis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// hand written protobuf encoding of the messages in synthesizer.proto
+
+type protoMessage interface {
+	marshalProto() []byte
+	unmarshalProto(data []byte) error
+}
+
+type grpcCodec struct{}
+
+func (grpcCodec) Marshal(v any) ([]byte, error) {
+	message, ok := v.(protoMessage)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal %T", v)
+	}
+
+	return message.marshalProto(), nil
+}
+
+func (grpcCodec) Unmarshal(data []byte, v any) error {
+	message, ok := v.(protoMessage)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal into %T", v)
+	}
+
+	return message.unmarshalProto(data)
+}
+
+func (grpcCodec) Name() string {
+	return "proto"
+}
+
+type grpcSynthesizeRequest struct {
+	Text            string
+	Model           string
+	Rate            float64
+	Pitch           float64
+	VolumeDb        float64
+	Speaker         string
+	Style           string
+	Phonemes        string
+	PhonemeAlphabet string
+}
+
+func (m *grpcSynthesizeRequest) marshalProto() []byte {
+	var data []byte
+	data = appendString(data, 1, m.Text)
+	data = appendString(data, 2, m.Model)
+	data = appendDouble(data, 3, m.Rate)
+	data = appendDouble(data, 4, m.Pitch)
+	data = appendDouble(data, 5, m.VolumeDb)
+	data = appendString(data, 6, m.Speaker)
+	data = appendString(data, 7, m.Style)
+	data = appendString(data, 8, m.Phonemes)
+	data = appendString(data, 9, m.PhonemeAlphabet)
+	return data
+}
+
+func (m *grpcSynthesizeRequest) unmarshalProto(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, kind protowire.Type, data []byte) (int, error) {
+		var text *string
+		var double *float64
+		switch number {
+		case 1:
+			text = &m.Text
+		case 2:
+			text = &m.Model
+		case 3:
+			double = &m.Rate
+		case 4:
+			double = &m.Pitch
+		case 5:
+			double = &m.VolumeDb
+		case 6:
+			text = &m.Speaker
+		case 7:
+			text = &m.Style
+		case 8:
+			text = &m.Phonemes
+		case 9:
+			text = &m.PhonemeAlphabet
+		}
+
+		if text != nil && kind == protowire.BytesType {
+			value, n := protowire.ConsumeString(data)
+			*text = value
+			return n, nil
+		}
+
+		if double != nil && kind == protowire.Fixed64Type {
+			value, n := protowire.ConsumeFixed64(data)
+			*double = math.Float64frombits(value)
+			return n, nil
+		}
+
+		return protowire.ConsumeFieldValue(number, kind, data), nil
+	})
+}
+
+type grpcSynthesizeResponse struct {
+	Samples      []float32
+	SamplingRate int32
+	Channels     int32
+	Words        []subtitles.Timing
+	Sentences    []subtitles.Timing
+}
+
+func (m *grpcSynthesizeResponse) marshalProto() []byte {
+	var data []byte
+	if len(m.Samples) > 0 {
+		packed := make([]byte, 0, len(m.Samples)*4)
+		for _, sample := range m.Samples {
+			packed = protowire.AppendFixed32(packed, math.Float32bits(sample))
+		}
+		data = protowire.AppendTag(data, 1, protowire.BytesType)
+		data = protowire.AppendBytes(data, packed)
+	}
+	data = appendVarint(data, 2, int64(m.SamplingRate))
+	data = appendVarint(data, 3, int64(m.Channels))
+	for _, word := range m.Words {
+		data = protowire.AppendTag(data, 4, protowire.BytesType)
+		data = protowire.AppendBytes(data, marshalTiming(word))
+	}
+	for _, sentence := range m.Sentences {
+		data = protowire.AppendTag(data, 5, protowire.BytesType)
+		data = protowire.AppendBytes(data, marshalTiming(sentence))
+	}
+	return data
+}
+
+func (m *grpcSynthesizeResponse) unmarshalProto(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, kind protowire.Type, data []byte) (int, error) {
+		switch {
+		case number == 1 && kind == protowire.BytesType:
+			packed, n := protowire.ConsumeBytes(data)
+			if n < 0 || len(packed)%4 != 0 {
+				return -1, errors.New("malformed packed samples")
+			}
+			for i := 0; i < len(packed); i += 4 {
+				value, _ := protowire.ConsumeFixed32(packed[i:])
+				m.Samples = append(m.Samples, math.Float32frombits(value))
+			}
+			return n, nil
+		case number == 1 && kind == protowire.Fixed32Type:
+			value, n := protowire.ConsumeFixed32(data)
+			m.Samples = append(m.Samples, math.Float32frombits(value))
+			return n, nil
+		case number == 2 && kind == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			m.SamplingRate = int32(value)
+			return n, nil
+		case number == 3 && kind == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			m.Channels = int32(value)
+			return n, nil
+		case (number == 4 || number == 5) && kind == protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return n, nil
+			}
+			timing, err := unmarshalTiming(value)
+			if err != nil {
+				return -1, err
+			}
+			if number == 4 {
+				m.Words = append(m.Words, timing)
+			} else {
+				m.Sentences = append(m.Sentences, timing)
+			}
+			return n, nil
+		}
+
+		return protowire.ConsumeFieldValue(number, kind, data), nil
+	})
+}
+
+func marshalTiming(timing subtitles.Timing) []byte {
+	var data []byte
+	data = appendString(data, 1, timing.Text)
+	data = appendVarint(data, 2, int64(timing.StartMs))
+	data = appendVarint(data, 3, int64(timing.EndMs))
+	return data
+}
+
+func unmarshalTiming(data []byte) (subtitles.Timing, error) {
+	var timing subtitles.Timing
+	err := consumeFields(data, func(number protowire.Number, kind protowire.Type, data []byte) (int, error) {
+		switch {
+		case number == 1 && kind == protowire.BytesType:
+			value, n := protowire.ConsumeString(data)
+			timing.Text = value
+			return n, nil
+		case number == 2 && kind == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			timing.StartMs = int(int32(value))
+			return n, nil
+		case number == 3 && kind == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			timing.EndMs = int(int32(value))
+			return n, nil
+		}
+
+		return protowire.ConsumeFieldValue(number, kind, data), nil
+	})
+
+	return timing, err
+}
+
+// walks the fields of a message; consume reads one field value and returns
+// its length, negative when the value is malformed
+func consumeFields(data []byte, consume func(number protowire.Number, kind protowire.Type, data []byte) (int, error)) error {
+	for len(data) > 0 {
+		number, kind, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		n, err := consume(number, kind, data)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+
+	return nil
+}
+
+// proto3 leaves default values off the wire
+
+func appendString(data []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return data
+	}
+
+	data = protowire.AppendTag(data, number, protowire.BytesType)
+	return protowire.AppendString(data, value)
+}
+
+func appendDouble(data []byte, number protowire.Number, value float64) []byte {
+	if value == 0 {
+		return data
+	}
+
+	data = protowire.AppendTag(data, number, protowire.Fixed64Type)
+	return protowire.AppendFixed64(data, math.Float64bits(value))
+}
+
+func appendVarint(data []byte, number protowire.Number, value int64) []byte {
+	if value == 0 {
+		return data
+	}
+
+	data = protowire.AppendTag(data, number, protowire.VarintType)
+	return protowire.AppendVarint(data, uint64(value))
+}
@@ -0,0 +1,34 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+// JsonBackend posts the text and voice parameters and reads float samples
+// back from a JSON body
+type JsonBackend struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+func NewJsonBackend(client *http.Client) *JsonBackend {
+	return &JsonBackend{client: client, maxBytes: maxResponseBytesFromEnv()}
+}
+
+func (b *JsonBackend) Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	body, err := postJson(ctx, b.client, url, newModelPayload(text, params), nil, b.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *SynthesisResponse
+	if err := json.Unmarshal(body, &response); err != nil || response == nil {
+		return nil, &upstreamError{err: fmt.Errorf("malformed model response: %v", err)}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,113 @@
+package synthesis
+
+import (
+	"strings"
+	"unicode"
+)
+
+const languageAuto = "auto"
+
+// runs with fewer letters than this are too short to judge and take the
+// language of their neighbours
+const minDetectableLetters = 4
+
+type languageSpan struct {
+	Text     string
+	Language string
+}
+
+// splitLanguages splits text into runs of a single language. Every sentence is
+// judged on its own, and a sentence is split further where the script
+// changes, so a Latin name inside Cyrillic text goes to a Latin voice. Runs
+// that can't be judged inherit the language around them, or the fallback.
+func splitLanguages(text string, detect func(string) string, fallback string) []languageSpan {
+	var spans []languageSpan
+	for _, sentence := range splitSentences(text) {
+		for _, run := range scriptRuns(sentence) {
+			language := ""
+			if countLetters(run) >= minDetectableLetters {
+				language = detect(run)
+			}
+			spans = append(spans, languageSpan{Text: run, Language: language})
+		}
+	}
+
+	known := fallback
+	for _, span := range spans {
+		if span.Language != "" {
+			known = span.Language
+			break
+		}
+	}
+
+	for i := range spans {
+		if spans[i].Language == "" {
+			spans[i].Language = known
+		}
+		known = spans[i].Language
+	}
+
+	var merged []languageSpan
+	for _, span := range spans {
+		if last := len(merged) - 1; last >= 0 && merged[last].Language == span.Language {
+			merged[last].Text += " " + span.Text
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	return merged
+}
+
+// scriptRuns groups the words of a sentence by writing system; words without
+// letters stay with the run before them
+func scriptRuns(sentence string) []string {
+	var runs []string
+	var current []string
+	currentScript := ""
+
+	for _, word := range strings.Fields(sentence) {
+		script := wordScript(word)
+		if script != "" && currentScript != "" && script != currentScript && len(current) > 0 {
+			runs = append(runs, strings.Join(current, " "))
+			current = nil
+		}
+
+		if script != "" {
+			currentScript = script
+		}
+		current = append(current, word)
+	}
+
+	if len(current) > 0 {
+		runs = append(runs, strings.Join(current, " "))
+	}
+
+	return runs
+}
+
+func wordScript(word string) string {
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			return "latin"
+		case unicode.Is(unicode.Cyrillic, r):
+			return "cyrillic"
+		case unicode.IsLetter(r):
+			return "other"
+		}
+	}
+
+	return ""
+}
+
+func countLetters(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,57 @@
+package synthesis
+
+import (
+	"testing"
+	"vitaliiPsl/synthesizer/internal/langid"
+)
+
+func TestSplitLanguages(t *testing.T) {
+	identifier := langid.NewIdentifier()
+	detect := func(text string) string {
+		return identifier.Detect(text, "en", "uk", "de").Language
+	}
+
+	cases := []struct {
+		text     string
+		expected []languageSpan
+	}{
+		{
+			text:     "Доброго ранку! Сьогодні гарна погода.",
+			expected: []languageSpan{{Text: "Доброго ранку! Сьогодні гарна погода.", Language: "uk"}},
+		},
+		{
+			text: "Доброго ранку, друзі. Welcome to the weekly meeting. Guten Morgen, wie geht es euch?",
+			expected: []languageSpan{
+				{Text: "Доброго ранку, друзі.", Language: "uk"},
+				{Text: "Welcome to the weekly meeting.", Language: "en"},
+				{Text: "Guten Morgen, wie geht es euch?", Language: "de"},
+			},
+		},
+		{
+			text: "Я щойно купив новий Samsung Galaxy для роботи.",
+			expected: []languageSpan{
+				{Text: "Я щойно купив новий", Language: "uk"},
+				{Text: "Samsung Galaxy", Language: "en"},
+				{Text: "для роботи.", Language: "uk"},
+			},
+		},
+		{
+			text:     "Ok. 42!",
+			expected: []languageSpan{{Text: "Ok. 42!", Language: "uk"}},
+		},
+	}
+
+	for _, c := range cases {
+		spans := splitLanguages(c.text, detect, "uk")
+		if len(spans) != len(c.expected) {
+			t.Errorf("%q: expected %+v, got %+v", c.text, c.expected, spans)
+			continue
+		}
+
+		for i := range spans {
+			if spans[i] != c.expected[i] {
+				t.Errorf("%q: span %d expected %+v, got %+v", c.text, i, c.expected[i], spans[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package synthesis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+	"vitaliiPsl/synthesizer/internal/lexicon"
+)
+
+// applyLexicon rewrites alias entries in place and moves phoneme entries into
+// segments of their own so the pronunciation travels with the grapheme.
+// All graphemes are matched in one pass over the original text, longer ones
+// first, so a replacement is never rewritten by another entry.
+func applyLexicon(segments []synthesisSegment, entries []lexicon.LexiconEntryDto) []synthesisSegment {
+	if len(entries) == 0 {
+		return segments
+	}
+
+	sorted := make([]lexicon.LexiconEntryDto, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Grapheme) > len(sorted[j].Grapheme) })
+
+	re := lexiconPattern(sorted)
+
+	var applied []synthesisSegment
+	for _, segment := range segments {
+		if segment.Text == "" || segment.Parameters.Phonemes != "" {
+			applied = append(applied, segment)
+			continue
+		}
+
+		applied = append(applied, rewriteSegment(segment, re, sorted)...)
+	}
+
+	return applied
+}
+
+// one alternation with a group per entry; go regexps prefer the earlier
+// alternative, which is the longer grapheme
+func lexiconPattern(entries []lexicon.LexiconEntryDto) *regexp.Regexp {
+	alternatives := make([]string, len(entries))
+	for i, entry := range entries {
+		pattern := regexp.QuoteMeta(entry.Grapheme)
+		if !entry.CaseSensitive {
+			pattern = "(?i:" + pattern + ")"
+		}
+		alternatives[i] = "(" + pattern + ")"
+	}
+
+	return regexp.MustCompile(boundary + "(" + strings.Join(alternatives, "|") + ")")
+}
+
+func rewriteSegment(segment synthesisSegment, re *regexp.Regexp, entries []lexicon.LexiconEntryDto) []synthesisSegment {
+	var result []synthesisSegment
+	var text strings.Builder
+	source := segment.Text
+	last := 0
+
+	flush := func() {
+		if hasWordRune(text.String()) {
+			result = append(result, synthesisSegment{Text: text.String(), Parameters: segment.Parameters})
+		}
+		text.Reset()
+	}
+
+	for _, match := range re.FindAllStringSubmatchIndex(source, -1) {
+		start, end := match[4], match[5]
+		if next, _ := utf8.DecodeRuneInString(source[end:]); isWordRune(next) {
+			continue
+		}
+
+		entry := entries[matchedEntry(match)]
+		text.WriteString(source[last:start])
+		last = end
+
+		if entry.Type == lexicon.EntryPhoneme {
+			flush()
+
+			params := segment.Parameters
+			params.Phonemes = entry.Replacement
+			params.PhonemeAlphabet = entry.Alphabet
+			result = append(result, synthesisSegment{Text: source[start:end], Parameters: params})
+			continue
+		}
+
+		text.WriteString(entry.Replacement)
+	}
+
+	if last == 0 {
+		return []synthesisSegment{segment}
+	}
+
+	text.WriteString(source[last:])
+	flush()
+	return result
+}
+
+// index of the entry whose group took part in the match; groups 1 and 2 are
+// the boundary and the whole grapheme
+func matchedEntry(match []int) int {
+	for i := 6; i < len(match); i += 2 {
+		if match[i] >= 0 {
+			return i/2 - 3
+		}
+	}
+
+	return 0
+}
+
+func hasWordRune(text string) bool {
+	return strings.IndexFunc(text, isWordRune) >= 0
+}
@@ -0,0 +1,78 @@
+package synthesis
+
+import (
+	"reflect"
+	"testing"
+	"vitaliiPsl/synthesizer/internal/lexicon"
+)
+
+func alias(grapheme, replacement string) lexicon.LexiconEntryDto {
+	return lexicon.LexiconEntryDto{Grapheme: grapheme, Type: lexicon.EntryAlias, Replacement: replacement}
+}
+
+func TestApplyLexiconAliases(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		entries  []lexicon.LexiconEntryDto
+		expected string
+	}{
+		{name: "single", text: "Ask the WHO today", entries: []lexicon.LexiconEntryDto{alias("WHO", "World Health Organization")}, expected: "Ask the World Health Organization today"},
+		{name: "case insensitive", text: "who knows", entries: []lexicon.LexiconEntryDto{alias("WHO", "World Health Organization")}, expected: "World Health Organization knows"},
+		{name: "whole words only", text: "Whole wholesale", entries: []lexicon.LexiconEntryDto{alias("whole", "hole")}, expected: "hole wholesale"},
+		{name: "longer grapheme wins", text: "New York and York", entries: []lexicon.LexiconEntryDto{alias("York", "Yorkshire"), alias("New York", "the Big Apple")}, expected: "the Big Apple and Yorkshire"},
+		{name: "no chained rewrites", text: "ABC is big", entries: []lexicon.LexiconEntryDto{alias("ABC", "AB plus C"), alias("AB", "A and B")}, expected: "AB plus C is big"},
+		{name: "no swap back", text: "cat dog", entries: []lexicon.LexiconEntryDto{alias("cat", "dog"), alias("dog", "cat")}, expected: "dog cat"},
+		{name: "punctuation in grapheme", text: "Mr. Smith", entries: []lexicon.LexiconEntryDto{alias("Mr.", "Mister")}, expected: "Mister Smith"},
+	}
+
+	for _, c := range cases {
+		segments := applyLexicon([]synthesisSegment{{Text: c.text}}, c.entries)
+		if len(segments) != 1 || segments[0].Text != c.expected {
+			t.Errorf("%s: expected %q, got %+v", c.name, c.expected, segments)
+		}
+	}
+}
+
+func TestApplyLexiconCaseSensitive(t *testing.T) {
+	entry := alias("US", "United States")
+	entry.CaseSensitive = true
+
+	segments := applyLexicon([]synthesisSegment{{Text: "US and us"}}, []lexicon.LexiconEntryDto{entry})
+	if segments[0].Text != "United States and us" {
+		t.Errorf("expected only the exact case to be replaced, got %q", segments[0].Text)
+	}
+}
+
+func TestApplyLexiconPhonemes(t *testing.T) {
+	entries := []lexicon.LexiconEntryDto{
+		{Grapheme: "tomato", Type: lexicon.EntryPhoneme, Replacement: "təˈmɑːtoʊ", Alphabet: "ipa"},
+		alias("lb", "pounds"),
+	}
+	base := VoiceParameters{Rate: 1.5}
+
+	segments := applyLexicon([]synthesisSegment{{Text: "Two lb of tomato, please", Parameters: base}, {Break: 0.5}}, entries)
+
+	phoneme := base
+	phoneme.Phonemes = "təˈmɑːtoʊ"
+	phoneme.PhonemeAlphabet = "ipa"
+	expected := []synthesisSegment{
+		{Text: "Two pounds of ", Parameters: base},
+		{Text: "tomato", Parameters: phoneme},
+		{Text: ", please", Parameters: base},
+		{Break: 0.5},
+	}
+
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("expected %+v, got %+v", expected, segments)
+	}
+}
+
+func TestApplyLexiconLeavesPhonemeSegments(t *testing.T) {
+	segment := synthesisSegment{Text: "tomato", Parameters: VoiceParameters{Phonemes: "x"}}
+
+	segments := applyLexicon([]synthesisSegment{segment}, []lexicon.LexiconEntryDto{alias("tomato", "potato")})
+	if !reflect.DeepEqual(segments, []synthesisSegment{segment}) {
+		t.Errorf("expected segments with phonemes to stay as they are, got %+v", segments)
+	}
+}
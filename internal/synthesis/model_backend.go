@@ -0,0 +1,101 @@
+package synthesis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+const defaultMaxResponseMegabytes = 64
+
+// ModelBackend speaks one upstream protocol. Transport failures and bad
+// statuses are reported as upstreamError so the client can retry them and
+// trip the circuit breaker.
+type ModelBackend interface {
+	Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error)
+}
+
+type unsupportedBackend struct {
+	protocol model.ModelProtocol
+}
+
+func (b unsupportedBackend) Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	return nil, fmt.Errorf("unsupported model protocol %q", b.protocol)
+}
+
+type modelPayload struct {
+	Text            string  `json:"text"`
+	Rate            float64 `json:"rate,omitempty"`
+	Pitch           float64 `json:"pitch,omitempty"`
+	VolumeDb        float64 `json:"volume_db,omitempty"`
+	Speaker         string  `json:"speaker,omitempty"`
+	Style           string  `json:"style,omitempty"`
+	Phonemes        string  `json:"phonemes,omitempty"`
+	PhonemeAlphabet string  `json:"phoneme_alphabet,omitempty"`
+}
+
+func newModelPayload(text string, params VoiceParameters) *modelPayload {
+	return &modelPayload{
+		Text:            text,
+		Rate:            params.Rate,
+		Pitch:           params.Pitch,
+		VolumeDb:        params.VolumeDb,
+		Speaker:         params.Speaker,
+		Style:           params.Style,
+		Phonemes:        params.Phonemes,
+		PhonemeAlphabet: params.PhonemeAlphabet,
+	}
+}
+
+// maxResponseBytesFromEnv bounds how much of a model response is read, so a
+// misbehaving upstream can't exhaust memory
+func maxResponseBytesFromEnv() int64 {
+	megabytes := intFromEnv("MODEL_MAX_RESPONSE_MB", defaultMaxResponseMegabytes)
+	if megabytes == 0 {
+		megabytes = defaultMaxResponseMegabytes
+	}
+
+	return int64(megabytes) * 1024 * 1024
+}
+
+func postJson(ctx context.Context, client *http.Client, url string, payload any, headers map[string]string, maxBytes int64) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	res, err := client.Do(request)
+	if err != nil {
+		return nil, &upstreamError{err: err}
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, maxBytes+1))
+	if err != nil {
+		return nil, &upstreamError{err: err}
+	}
+
+	if int64(len(resBody)) > maxBytes {
+		return nil, &upstreamError{err: fmt.Errorf("model response exceeds %d bytes", maxBytes)}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, &upstreamError{statusCode: res.StatusCode}
+	}
+
+	return resBody, nil
+}
@@ -0,0 +1,282 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vitaliiPsl/synthesizer/internal/audio"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testSamples = []float32{0, 0.25, -0.5, 0.75}
+
+func TestJsonBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload modelPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		if payload.Text != "hello" || payload.Rate != 1.5 || payload.Speaker != "anna" {
+			t.Errorf("unexpected payload: %+v", payload)
+		}
+
+		json.NewEncoder(w).Encode(&SynthesisResponse{Samples: testSamples, SamplingRate: 22050})
+	}))
+	defer server.Close()
+
+	response, err := NewJsonBackend(server.Client()).Synthesize(context.Background(), &model.ModelDto{}, server.URL, "hello", VoiceParameters{Rate: 1.5, Speaker: "anna"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, response, testSamples, 22050, 0)
+}
+
+func TestJsonBackendStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewJsonBackend(server.Client()).Synthesize(context.Background(), &model.ModelDto{}, server.URL, "hello", VoiceParameters{})
+	assertUpstreamStatus(t, err, http.StatusServiceUnavailable)
+}
+
+func TestJsonBackendResponseLimit(t *testing.T) {
+	t.Setenv("MODEL_MAX_RESPONSE_MB", "1")
+
+	cases := []struct {
+		name  string
+		size  int
+		fails bool
+	}{
+		{name: "at the limit", size: 1024 * 1024},
+		{name: "over the limit", size: 1024*1024 + 1, fails: true},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(make([]byte, c.size))
+		}))
+
+		body, err := postJson(context.Background(), server.Client(), server.URL, &modelPayload{Text: "hello"}, nil, NewJsonBackend(server.Client()).maxBytes)
+		server.Close()
+
+		var upstream *upstreamError
+		switch {
+		case c.fails && !errors.As(err, &upstream):
+			t.Errorf("%s: expected upstream error, got %v", c.name, err)
+		case !c.fails && (err != nil || len(body) != c.size):
+			t.Errorf("%s: expected %d bytes, got %d and %v", c.name, c.size, len(body), err)
+		}
+	}
+}
+
+func TestWavBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "audio/wav" {
+			t.Errorf("unexpected accept header: %s", accept)
+		}
+
+		data, _ := audio.NewWavEncoder().Encode(testSamples, 16000)
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	response, err := NewWavBackend(server.Client()).Synthesize(context.Background(), &model.ModelDto{}, server.URL, "hello", VoiceParameters{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, response, testSamples, 16000, 1)
+}
+
+func TestWavBackendMalformedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not audio"))
+	}))
+	defer server.Close()
+
+	_, err := NewWavBackend(server.Client()).Synthesize(context.Background(), &model.ModelDto{}, server.URL, "hello", VoiceParameters{})
+	assertUpstreamStatus(t, err, 0)
+}
+
+func TestOpenAiBackend(t *testing.T) {
+	t.Setenv("MODEL_OPENAI_API_KEY", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("unexpected authorization: %s", auth)
+		}
+
+		var request openAiSpeechRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		expected := openAiSpeechRequest{Model: "tts-1-hd", Input: "hello", Voice: "nova", ResponseFormat: "wav", Speed: 0.5}
+		if request != expected {
+			t.Errorf("unexpected request: %+v", request)
+		}
+
+		data, _ := audio.NewWavEncoder().EncodeWithBitDepth(testSamples, 24000, 24)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	model := &model.ModelDto{UpstreamModel: "tts-1-hd"}
+	response, err := NewOpenAiBackend(server.Client()).Synthesize(context.Background(), model, server.URL+"/v1", "hello", VoiceParameters{Rate: 0.5, Speaker: "nova"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, response, testSamples, 24000, 1)
+}
+
+func TestSpeechUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://api.openai.com", "https://api.openai.com/v1/audio/speech"},
+		{"https://api.openai.com/", "https://api.openai.com/v1/audio/speech"},
+		{"https://api.openai.com/v1", "https://api.openai.com/v1/audio/speech"},
+		{"http://localhost:8000/v1/audio/speech", "http://localhost:8000/v1/audio/speech"},
+	}
+
+	for _, test := range tests {
+		if url := speechUrl(test.url); url != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.url, test.expected, url)
+		}
+	}
+}
+
+type fakeSynthesizer struct {
+	handle func(request *grpcSynthesizeRequest) (*grpcSynthesizeResponse, error)
+}
+
+func startGrpcServer(t *testing.T, synthesizer *fakeSynthesizer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := grpc.NewServer(grpc.ForceServerCodec(grpcCodec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "synthesizer.v1.Synthesizer",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Synthesize",
+			Handler: func(srv any, ctx context.Context, decode func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				var request grpcSynthesizeRequest
+				if err := decode(&request); err != nil {
+					return nil, err
+				}
+
+				return srv.(*fakeSynthesizer).handle(&request)
+			},
+		}},
+	}, synthesizer)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return "grpc://" + listener.Addr().String()
+}
+
+func TestGrpcBackend(t *testing.T) {
+	words := []subtitles.Timing{{Text: "hello", StartMs: 0, EndMs: 320}}
+	url := startGrpcServer(t, &fakeSynthesizer{handle: func(request *grpcSynthesizeRequest) (*grpcSynthesizeResponse, error) {
+		expected := grpcSynthesizeRequest{Text: "hello", Model: "vits", Pitch: -2, Style: "calm"}
+		if *request != expected {
+			t.Errorf("unexpected request: %+v", request)
+		}
+
+		return &grpcSynthesizeResponse{Samples: testSamples, SamplingRate: 44100, Channels: 1, Words: words}, nil
+	}})
+
+	backend := NewGrpcBackend()
+	response, err := backend.Synthesize(context.Background(), &model.ModelDto{UpstreamModel: "vits"}, url, "hello", VoiceParameters{Pitch: -2, Style: "calm"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, response, testSamples, 44100, 1)
+	if len(response.Words) != 1 || response.Words[0] != words[0] {
+		t.Errorf("unexpected words: %+v", response.Words)
+	}
+}
+
+func TestGrpcBackendStatus(t *testing.T) {
+	url := startGrpcServer(t, &fakeSynthesizer{handle: func(request *grpcSynthesizeRequest) (*grpcSynthesizeResponse, error) {
+		return nil, status.Error(codes.Unavailable, "warming up")
+	}})
+
+	_, err := NewGrpcBackend().Synthesize(context.Background(), &model.ModelDto{}, url, "hello", VoiceParameters{})
+	assertUpstreamStatus(t, err, http.StatusServiceUnavailable)
+	if !isRetryable(err) {
+		t.Error("expected unavailable to be retryable")
+	}
+}
+
+func TestGrpcMessagesRoundTrip(t *testing.T) {
+	response := &grpcSynthesizeResponse{
+		Samples:      testSamples,
+		SamplingRate: 8000,
+		Channels:     2,
+		Sentences:    []subtitles.Timing{{Text: "Hi.", StartMs: 10, EndMs: 900}},
+	}
+
+	var decoded grpcSynthesizeResponse
+	if err := decoded.unmarshalProto(response.marshalProto()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, &SynthesisResponse{Samples: decoded.Samples, SamplingRate: int(decoded.SamplingRate), Channels: int(decoded.Channels)}, testSamples, 8000, 2)
+	if len(decoded.Sentences) != 1 || decoded.Sentences[0] != response.Sentences[0] {
+		t.Errorf("unexpected sentences: %+v", decoded.Sentences)
+	}
+}
+
+func assertResponse(t *testing.T, response *SynthesisResponse, samples []float32, samplingRate, channels int) {
+	t.Helper()
+
+	if response.SamplingRate != samplingRate {
+		t.Errorf("expected sampling rate %d, got %d", samplingRate, response.SamplingRate)
+	}
+	if response.Channels != channels {
+		t.Errorf("expected %d channels, got %d", channels, response.Channels)
+	}
+	if len(response.Samples) != len(samples) {
+		t.Fatalf("expected %d samples, got %d", len(samples), len(response.Samples))
+	}
+	for i := range samples {
+		// integer wav loses a little precision
+		if math.Abs(float64(response.Samples[i]-samples[i])) > 1e-4 {
+			t.Errorf("sample %d: expected %f, got %f", i, samples[i], response.Samples[i])
+		}
+	}
+}
+
+func assertUpstreamStatus(t *testing.T, err error, statusCode int) {
+	t.Helper()
+
+	var upstream *upstreamError
+	if !errors.As(err, &upstream) {
+		t.Fatalf("expected upstream error, got %v", err)
+	}
+	if upstream.statusCode != statusCode {
+		t.Errorf("expected status %d, got %d", statusCode, upstream.statusCode)
+	}
+}
@@ -0,0 +1,226 @@
+package synthesis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+const (
+	defaultModelTimeout        = 30 * time.Second
+	defaultModelMaxRetries     = 2
+	defaultBreakerThreshold    = 5
+	defaultBreakerOpenDuration = 30 * time.Second
+	retryBaseDelay             = 200 * time.Millisecond
+	retryMaxDelay              = 2 * time.Second
+)
+
+var errModelUnavailable = errors.New("model circuit breaker is open")
+
+type ModelClient interface {
+	Synthesize(ctx context.Context, model *model.ModelDto, text string, params VoiceParameters) (*SynthesisResponse, error)
+}
+
+type ModelClientImpl struct {
+	balancer            model.ReplicaBalancer
+	backends            map[model.ModelProtocol]ModelBackend
+	defaultTimeout      time.Duration
+	maxRetries          int
+	breakerThreshold    int
+	breakerOpenDuration time.Duration
+	mu                  sync.Mutex
+	breakers            map[string]*circuitBreaker
+}
+
+type upstreamError struct {
+	statusCode int
+	err        error
+}
+
+func (e *upstreamError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+
+	return fmt.Sprintf("model responded with status %d", e.statusCode)
+}
+
+func (e *upstreamError) Unwrap() error {
+	return e.err
+}
+
+func NewModelClient(balancer model.ReplicaBalancer, backends map[model.ModelProtocol]ModelBackend) *ModelClientImpl {
+	return &ModelClientImpl{
+		balancer:            balancer,
+		backends:            backends,
+		defaultTimeout:      durationFromEnv("MODEL_CLIENT_TIMEOUT_MS", defaultModelTimeout),
+		maxRetries:          intFromEnv("MODEL_CLIENT_MAX_RETRIES", defaultModelMaxRetries),
+		breakerThreshold:    intFromEnv("MODEL_CIRCUIT_BREAKER_THRESHOLD", defaultBreakerThreshold),
+		breakerOpenDuration: durationFromEnv("MODEL_CIRCUIT_BREAKER_OPEN_MS", defaultBreakerOpenDuration),
+		breakers:            make(map[string]*circuitBreaker),
+	}
+}
+
+func (c *ModelClientImpl) Synthesize(ctx context.Context, model *model.ModelDto, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	breaker := c.breaker(model.Id)
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepWithJitter(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		if !breaker.allow() {
+			logger.Logger.Error("Model circuit breaker is open", "modelId", model.Id)
+			return nil, service_errors.NewErrBadGateway("Model is temporarily unavailable")
+		}
+
+		endpoint, release := c.balancer.Acquire(model)
+		response, err := c.call(ctx, model, endpoint.Url, text, params)
+		release(err == nil || !isUpstreamFault(err))
+
+		switch {
+		case err == nil:
+			breaker.recordSuccess()
+			return response, nil
+		case ctx.Err() != nil:
+			breaker.release()
+			return nil, ctx.Err()
+		case isUpstreamFault(err):
+			breaker.recordFailure()
+		default:
+			breaker.release()
+		}
+
+		lastErr = err
+		if !isRetryable(err) {
+			break
+		}
+
+		logger.Logger.Error("Model call failed, retrying", "modelId", model.Id, "attempt", attempt+1, "error", err)
+	}
+
+	logger.Logger.Error("Failed to synthesize speech", "modelId", model.Id, "error", lastErr)
+	return nil, service_errors.NewErrBadGateway("Model server failed to synthesize speech")
+}
+
+func (c *ModelClientImpl) call(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	timeout := c.defaultTimeout
+	if model.TimeoutMs > 0 {
+		timeout = time.Duration(model.TimeoutMs) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return c.backend(model).Synthesize(ctx, model, url, text, params)
+}
+
+func (c *ModelClientImpl) backend(model *model.ModelDto) ModelBackend {
+	backend, ok := c.backends[model.Protocol]
+	if !ok {
+		return unsupportedBackend{protocol: model.Protocol}
+	}
+
+	return backend
+}
+
+func (c *ModelClientImpl) breaker(modelId string) *circuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[modelId]
+	if !ok {
+		breaker = newCircuitBreaker(c.breakerThreshold, c.breakerOpenDuration)
+		c.breakers[modelId] = breaker
+	}
+
+	return breaker
+}
+
+func isRetryable(err error) bool {
+	var upstream *upstreamError
+	if !errors.As(err, &upstream) {
+		return false
+	}
+
+	switch upstream.statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case 0:
+		var netErr net.Error
+		return errors.As(upstream.err, &netErr) || errors.Is(upstream.err, io.ErrUnexpectedEOF) || errors.Is(upstream.err, context.DeadlineExceeded)
+	default:
+		return false
+	}
+}
+
+func isUpstreamFault(err error) bool {
+	var upstream *upstreamError
+	return errors.As(err, &upstream) && (upstream.statusCode == 0 || upstream.statusCode >= 500)
+}
+
+func sleepWithJitter(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(retryDelay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// full jitter: a random delay up to an exponential backoff; the shift is
+// bounded so that many retries can't overflow it
+func retryDelay(attempt int) time.Duration {
+	backoff := retryMaxDelay
+	if shift := attempt - 1; shift < 16 {
+		backoff = min(retryBaseDelay<<shift, retryMaxDelay)
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+func intFromEnv(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+
+	return value
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return time.Duration(value) * time.Millisecond
+}
+
+// nonNegativeDurationFromEnv is durationFromEnv for settings where 0 turns the
+// feature off
+func nonNegativeDurationFromEnv(name string, fallback time.Duration) time.Duration {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+
+	return time.Duration(value) * time.Millisecond
+}
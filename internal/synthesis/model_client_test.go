@@ -0,0 +1,215 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+// flakyUpstream answers the first failures requests with status and
+// synthesizes afterwards
+type flakyUpstream struct {
+	server   *httptest.Server
+	calls    atomic.Int32
+	failures atomic.Int32
+}
+
+func newFlakyUpstream(t *testing.T, failures int, status int) *flakyUpstream {
+	upstream := &flakyUpstream{}
+	upstream.failures.Store(int32(failures))
+	upstream.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream.calls.Add(1)
+		if upstream.failures.Add(-1) >= 0 {
+			w.WriteHeader(status)
+			return
+		}
+
+		json.NewEncoder(w).Encode(&SynthesisResponse{Samples: testSamples, SamplingRate: 22050})
+	}))
+	t.Cleanup(upstream.server.Close)
+
+	return upstream
+}
+
+func (u *flakyUpstream) model() *model.ModelDto {
+	return &model.ModelDto{Id: "model", Url: u.server.URL, Protocol: model.ProtocolJson}
+}
+
+func newTestModelClient(u *flakyUpstream, maxRetries, threshold int, openDuration time.Duration) *ModelClientImpl {
+	return &ModelClientImpl{
+		balancer:            model.NewReplicaBalancer(),
+		backends:            map[model.ModelProtocol]ModelBackend{model.ProtocolJson: NewJsonBackend(u.server.Client())},
+		defaultTimeout:      5 * time.Second,
+		maxRetries:          maxRetries,
+		breakerThreshold:    threshold,
+		breakerOpenDuration: openDuration,
+		breakers:            make(map[string]*circuitBreaker),
+	}
+}
+
+func assertBadGateway(t *testing.T, err error, message string) {
+	t.Helper()
+
+	badGateway, ok := err.(*service_errors.ErrBadGateway)
+	if !ok {
+		t.Fatalf("expected ErrBadGateway, got %T: %v", err, err)
+	}
+	if badGateway.Error() != message {
+		t.Errorf("expected %q, got %q", message, badGateway.Error())
+	}
+}
+
+func TestModelClientRetriesTransientFailures(t *testing.T) {
+	upstream := newFlakyUpstream(t, 2, http.StatusServiceUnavailable)
+	client := newTestModelClient(upstream, 2, 10, time.Minute)
+
+	response, err := client.Synthesize(context.Background(), upstream.model(), "hello", VoiceParameters{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertResponse(t, response, testSamples, 22050, 0)
+	if calls := upstream.calls.Load(); calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestModelClientGivesUpAfterMaxRetries(t *testing.T) {
+	upstream := newFlakyUpstream(t, 10, http.StatusBadGateway)
+	client := newTestModelClient(upstream, 2, 10, time.Minute)
+
+	_, err := client.Synthesize(context.Background(), upstream.model(), "hello", VoiceParameters{})
+
+	assertBadGateway(t, err, "Model server failed to synthesize speech")
+	if calls := upstream.calls.Load(); calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestModelClientDoesNotRetryClientErrors(t *testing.T) {
+	upstream := newFlakyUpstream(t, 10, http.StatusBadRequest)
+	client := newTestModelClient(upstream, 2, 1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Synthesize(context.Background(), upstream.model(), "hello", VoiceParameters{})
+		assertBadGateway(t, err, "Model server failed to synthesize speech")
+	}
+
+	// a rejected request says nothing about the model's health
+	if calls := upstream.calls.Load(); calls != 3 {
+		t.Errorf("expected one call per request with the breaker closed, got %d", calls)
+	}
+}
+
+func TestModelClientOpensCircuit(t *testing.T) {
+	upstream := newFlakyUpstream(t, 2, http.StatusInternalServerError)
+	client := newTestModelClient(upstream, 0, 2, 50*time.Millisecond)
+	model := upstream.model()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Synthesize(context.Background(), model, "hello", VoiceParameters{})
+		assertBadGateway(t, err, "Model server failed to synthesize speech")
+	}
+
+	_, err := client.Synthesize(context.Background(), model, "hello", VoiceParameters{})
+	assertBadGateway(t, err, "Model is temporarily unavailable")
+	if calls := upstream.calls.Load(); calls != 2 {
+		t.Fatalf("expected the open circuit to keep requests away from the model, got %d calls", calls)
+	}
+
+	// after the open duration a probe goes through and, succeeding, closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Synthesize(context.Background(), model, "hello", VoiceParameters{}); err != nil {
+			t.Fatalf("expected the model to be reachable again: %v", err)
+		}
+	}
+	if calls := upstream.calls.Load(); calls != 4 {
+		t.Errorf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestModelClientReopensCircuitOnFailedProbe(t *testing.T) {
+	upstream := newFlakyUpstream(t, 10, http.StatusInternalServerError)
+	client := newTestModelClient(upstream, 0, 1, 50*time.Millisecond)
+	model := upstream.model()
+
+	client.Synthesize(context.Background(), model, "hello", VoiceParameters{})
+	time.Sleep(60 * time.Millisecond)
+
+	_, err := client.Synthesize(context.Background(), model, "hello", VoiceParameters{})
+	assertBadGateway(t, err, "Model server failed to synthesize speech")
+
+	_, err = client.Synthesize(context.Background(), model, "hello", VoiceParameters{})
+	assertBadGateway(t, err, "Model is temporarily unavailable")
+	if calls := upstream.calls.Load(); calls != 2 {
+		t.Errorf("expected the failed probe to reopen the circuit, got %d calls", calls)
+	}
+}
+
+func TestModelClientStopsRetryingWhenCancelled(t *testing.T) {
+	upstream := newFlakyUpstream(t, 10, http.StatusServiceUnavailable)
+	client := newTestModelClient(upstream, 100, 1000, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Synthesize(ctx, upstream.model(), "hello", VoiceParameters{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 64; attempt++ {
+		limit := retryMaxDelay
+		if attempt < 8 {
+			limit = min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+		}
+
+		for i := 0; i < 20; i++ {
+			if delay := retryDelay(attempt); delay <= 0 || delay > limit {
+				t.Fatalf("attempt %d: delay %s outside (0, %s]", attempt, delay, limit)
+			}
+		}
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	breaker := newCircuitBreaker(2, 20*time.Millisecond)
+
+	breaker.recordFailure()
+	if breaker.state != circuitClosed || !breaker.allow() {
+		t.Fatal("expected the circuit to stay closed below the threshold")
+	}
+
+	breaker.recordFailure()
+	if breaker.state != circuitOpen || breaker.allow() {
+		t.Fatal("expected the circuit to open at the threshold")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !breaker.allow() || breaker.state != circuitHalfOpen {
+		t.Fatal("expected a probe to be allowed after the open duration")
+	}
+	if breaker.allow() {
+		t.Fatal("expected a single probe at a time")
+	}
+
+	// a probe that ends without a verdict frees the slot
+	breaker.release()
+	if !breaker.allow() {
+		t.Fatal("expected another probe after release")
+	}
+
+	breaker.recordSuccess()
+	if breaker.state != circuitClosed || breaker.failures != 0 {
+		t.Fatalf("expected a successful probe to close the circuit, got state %d with %d failures", breaker.state, breaker.failures)
+	}
+}
@@ -0,0 +1,289 @@
+package synthesis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const englishNumber = `(\d{1,3}(?:,\d{3})+|\d+)`
+
+var englishOnes = [20]string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var englishTens = [10]string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = []struct {
+	value int64
+	name  string
+}{
+	{1_000_000_000_000, "trillion"},
+	{1_000_000_000, "billion"},
+	{1_000_000, "million"},
+	{1_000, "thousand"},
+}
+
+var englishIrregularOrdinals = map[string]string{
+	"one":    "first",
+	"two":    "second",
+	"three":  "third",
+	"five":   "fifth",
+	"eight":  "eighth",
+	"nine":   "ninth",
+	"twelve": "twelfth",
+}
+
+var englishMonths = [12]string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+type englishCurrency struct {
+	major, majorPlural, minor, minorPlural string
+}
+
+var englishCurrencies = map[string]englishCurrency{
+	"$":   {"dollar", "dollars", "cent", "cents"},
+	"USD": {"dollar", "dollars", "cent", "cents"},
+	"€":   {"euro", "euros", "cent", "cents"},
+	"EUR": {"euro", "euros", "cent", "cents"},
+	"£":   {"pound", "pounds", "penny", "pence"},
+	"GBP": {"pound", "pounds", "penny", "pence"},
+	"₴":   {"hryvnia", "hryvnias", "kopiyka", "kopiykas"},
+	"UAH": {"hryvnia", "hryvnias", "kopiyka", "kopiykas"},
+}
+
+var englishAbbreviations = map[string]string{
+	"Mr.":     "Mister",
+	"Mrs.":    "Missus",
+	"Ms.":     "Miz",
+	"Dr.":     "Doctor",
+	"Prof.":   "Professor",
+	"Jr.":     "Junior",
+	"Sr.":     "Senior",
+	"Ave.":    "Avenue",
+	"Inc.":    "Incorporated",
+	"Ltd.":    "Limited",
+	"approx.": "approximately",
+	"etc.":    "et cetera",
+	"e.g.":    "for example",
+	"i.e.":    "that is",
+	"vs.":     "versus",
+}
+
+func englishRules() []normalizationRule {
+	return []normalizationRule{
+		urlRule(urlWords{dot: "dot", slash: "slash", at: "at", dash: "dash", underscore: "underscore", www: "double-u double-u double-u"}),
+		abbreviationRule(englishAbbreviations),
+		{
+			// ISO 8601: 2024-03-05
+			pattern: regexp.MustCompile(boundary + `(\d{4})-(\d{1,2})-(\d{1,2})`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return englishDate(groups[0], groups[1], groups[2])
+			},
+		},
+		{
+			// US order: 03/05/2024
+			pattern: regexp.MustCompile(boundary + `(\d{1,2})/(\d{1,2})/(\d{4})`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return englishDate(groups[2], groups[0], groups[1])
+			},
+		},
+		{
+			// day first: 05.03.2024
+			pattern: regexp.MustCompile(boundary + `(\d{1,2})\.(\d{1,2})\.(\d{4})`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return englishDate(groups[2], groups[1], groups[0])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`()([$€£₴])\s?` + englishNumber + `(?:\.(\d{1,2}))?`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return englishMoney(groups[0], groups[1], groups[2])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + englishNumber + `(?:\.(\d{1,2}))?\s?(USD|EUR|GBP|UAH)`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return englishMoney(groups[2], groups[0], groups[1])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + englishNumber + `(?:\.(\d+))?\s?%`),
+			replace: func(groups []string, next rune) (string, bool) {
+				number, ok := englishDecimal(groups[0], groups[1])
+				return number + " percent", ok
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + `(\d+)(?i:st|nd|rd|th)`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				n, ok := parseGroupedInt(groups[0], "")
+				if !ok {
+					return "", false
+				}
+
+				return englishOrdinal(n), true
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + `([-−]?)` + englishNumber + `(?:\.(\d+))?`),
+			replace: func(groups []string, next rune) (string, bool) {
+				number, ok := englishDecimal(groups[1], groups[2])
+				if !ok {
+					number = spellDigits(groups[1]+groups[2], englishDigits())
+				}
+
+				if groups[0] != "" {
+					number = "minus " + number
+				}
+
+				return separateFromLetter(number, next), true
+			},
+		},
+	}
+}
+
+func englishCardinal(n int64) string {
+	if n < 0 {
+		return "minus " + englishCardinal(-n)
+	}
+
+	if n == 0 {
+		return englishOnes[0]
+	}
+
+	var words []string
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			words = append(words, englishTriplet(n/scale.value), scale.name)
+			n %= scale.value
+		}
+	}
+
+	if n > 0 {
+		words = append(words, englishTriplet(n))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func englishTriplet(n int64) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+
+	switch {
+	case n == 0:
+	case n < 20:
+		words = append(words, englishOnes[n])
+	case n%10 == 0:
+		words = append(words, englishTens[n/10])
+	default:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	}
+
+	return strings.Join(words, " ")
+}
+
+func englishOrdinal(n int64) string {
+	cardinal := englishCardinal(n)
+
+	cut := strings.LastIndexAny(cardinal, " -") + 1
+	last := cardinal[cut:]
+
+	switch {
+	case englishIrregularOrdinals[last] != "":
+		last = englishIrregularOrdinals[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+
+	return cardinal[:cut] + last
+}
+
+func englishYear(year int) string {
+	switch {
+	case year >= 2000 && year < 2010, year < 1100, year >= 10000:
+		return englishCardinal(int64(year))
+	case year%100 == 0:
+		return englishCardinal(int64(year/100)) + " hundred"
+	case year%100 < 10:
+		return englishCardinal(int64(year/100)) + " oh " + englishOnes[year%10]
+	default:
+		return englishCardinal(int64(year/100)) + " " + englishCardinal(int64(year%100))
+	}
+}
+
+func englishDate(year, month, day string) (string, bool) {
+	y, m, d, ok := parseDate(year, month, day)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s %s, %s", englishMonths[m-1], englishOrdinal(int64(d)), englishYear(y)), true
+}
+
+func englishDecimal(integer, fraction string) (string, bool) {
+	n, ok := parseGroupedInt(integer, ",")
+	if !ok {
+		return "", false
+	}
+
+	number := englishCardinal(n)
+	if fraction != "" {
+		number += " point " + spellDigits(fraction, englishDigits())
+	}
+
+	return number, true
+}
+
+func englishMoney(symbol, amount, fraction string) (string, bool) {
+	currency, ok := englishCurrencies[symbol]
+	if !ok {
+		return "", false
+	}
+
+	major, ok := parseGroupedInt(amount, ",")
+	if !ok {
+		return "", false
+	}
+	minor := parseMinorUnits(fraction)
+
+	var parts []string
+	if major > 0 || minor == 0 {
+		parts = append(parts, englishCardinal(major)+" "+pluralize(major, currency.major, currency.majorPlural))
+	}
+
+	if minor > 0 {
+		parts = append(parts, englishCardinal(minor)+" "+pluralize(minor, currency.minor, currency.minorPlural))
+	}
+
+	return strings.Join(parts, " and "), true
+}
+
+func englishDigits() [10]string {
+	var digits [10]string
+	copy(digits[:], englishOnes[:10])
+	return digits
+}
+
+func pluralize(n int64, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+
+	return plural
+}
@@ -0,0 +1,6 @@
+package synthesis
+
+type NormalizationResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
@@ -0,0 +1,407 @@
+package synthesis
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const ukrainianNumber = `(\d{1,3}(?:[ \x{00A0}]\d{3})+|\d+)`
+
+const ukrainianGroupSeparators = " \u00a0"
+
+type ukrainianGender int
+
+const (
+	masculine ukrainianGender = iota
+	feminine
+	neuter
+)
+
+type ukrainianCase int
+
+const (
+	nominativeMasculine ukrainianCase = iota
+	nominativeFeminine
+	nominativeNeuter
+	genitiveMasculine
+)
+
+var ukrainianOnes = [20]string{
+	"нуль", "один", "два", "три", "чотири", "п'ять", "шість", "сім", "вісім", "дев'ять",
+	"десять", "одинадцять", "дванадцять", "тринадцять", "чотирнадцять", "п'ятнадцять", "шістнадцять", "сімнадцять", "вісімнадцять", "дев'ятнадцять",
+}
+
+var ukrainianTens = [10]string{"", "", "двадцять", "тридцять", "сорок", "п'ятдесят", "шістдесят", "сімдесят", "вісімдесят", "дев'яносто"}
+
+var ukrainianHundreds = [10]string{"", "сто", "двісті", "триста", "чотириста", "п'ятсот", "шістсот", "сімсот", "вісімсот", "дев'ятсот"}
+
+// plural forms for one, few (2-4) and many
+type ukrainianNoun struct {
+	forms  [3]string
+	gender ukrainianGender
+}
+
+var ukrainianScales = []struct {
+	value int64
+	noun  ukrainianNoun
+}{
+	{1_000_000_000_000, ukrainianNoun{[3]string{"трильйон", "трильйони", "трильйонів"}, masculine}},
+	{1_000_000_000, ukrainianNoun{[3]string{"мільярд", "мільярди", "мільярдів"}, masculine}},
+	{1_000_000, ukrainianNoun{[3]string{"мільйон", "мільйони", "мільйонів"}, masculine}},
+	{1_000, ukrainianNoun{[3]string{"тисяча", "тисячі", "тисяч"}, feminine}},
+}
+
+var ukrainianOrdinalOnes = [20]string{
+	"нульов", "перш", "друг", "трет", "четверт", "п'ят", "шост", "сьом", "восьм", "дев'ят",
+	"десят", "одинадцят", "дванадцят", "тринадцят", "чотирнадцят", "п'ятнадцят", "шістнадцят", "сімнадцят", "вісімнадцят", "дев'ятнадцят",
+}
+
+var ukrainianOrdinalTens = [10]string{"", "", "двадцят", "тридцят", "сороков", "п'ятдесят", "шістдесят", "сімдесят", "вісімдесят", "дев'яност"}
+
+var ukrainianOrdinalHundreds = [10]string{"", "сот", "двохсот", "трьохсот", "чотирьохсот", "п'ятисот", "шестисот", "семисот", "восьмисот", "дев'ятисот"}
+
+var ukrainianOrdinalThousands = map[int64]string{
+	1: "тисячн", 2: "двохтисячн", 3: "трьохтисячн", 4: "чотирьохтисячн", 5: "п'ятитисячн",
+	6: "шеститисячн", 7: "семитисячн", 8: "восьмитисячн", 9: "дев'ятитисячн", 10: "десятитисячн",
+}
+
+var ukrainianHardEndings = [4]string{"ий", "а", "е", "ого"}
+
+var ukrainianSoftEndings = [4]string{"ій", "я", "є", "ього"}
+
+var ukrainianOrdinalSuffixes = map[string]ukrainianCase{
+	"й": nominativeMasculine, "ий": nominativeMasculine, "ій": nominativeMasculine,
+	"а": nominativeFeminine, "та": nominativeFeminine, "я": nominativeFeminine,
+	"е": nominativeNeuter, "те": nominativeNeuter, "є": nominativeNeuter,
+	"го": genitiveMasculine, "ого": genitiveMasculine, "ього": genitiveMasculine,
+}
+
+var ukrainianMonths = [12]string{
+	"січня", "лютого", "березня", "квітня", "травня", "червня",
+	"липня", "серпня", "вересня", "жовтня", "листопада", "грудня",
+}
+
+type ukrainianCurrency struct {
+	major, minor ukrainianNoun
+}
+
+var (
+	hryvnia = ukrainianCurrency{
+		major: ukrainianNoun{[3]string{"гривня", "гривні", "гривень"}, feminine},
+		minor: ukrainianNoun{[3]string{"копійка", "копійки", "копійок"}, feminine},
+	}
+	dollar = ukrainianCurrency{
+		major: ukrainianNoun{[3]string{"долар", "долари", "доларів"}, masculine},
+		minor: ukrainianNoun{[3]string{"цент", "центи", "центів"}, masculine},
+	}
+	euro = ukrainianCurrency{
+		major: ukrainianNoun{[3]string{"євро", "євро", "євро"}, neuter},
+		minor: ukrainianNoun{[3]string{"цент", "центи", "центів"}, masculine},
+	}
+	pound = ukrainianCurrency{
+		major: ukrainianNoun{[3]string{"фунт", "фунти", "фунтів"}, masculine},
+		minor: ukrainianNoun{[3]string{"пенс", "пенси", "пенсів"}, masculine},
+	}
+)
+
+var ukrainianCurrencies = map[string]ukrainianCurrency{
+	"₴": hryvnia, "грн": hryvnia, "грн.": hryvnia, "UAH": hryvnia,
+	"$": dollar, "USD": dollar, "дол.": dollar,
+	"€": euro, "EUR": euro,
+	"£": pound, "GBP": pound,
+}
+
+var ukrainianPercent = ukrainianNoun{[3]string{"відсоток", "відсотки", "відсотків"}, masculine}
+
+var ukrainianAbbreviations = map[string]string{
+	"т. д.":  "так далі",
+	"т.д.":   "так далі",
+	"т. п.":  "тому подібне",
+	"т.п.":   "тому подібне",
+	"напр.":  "наприклад",
+	"вул.":   "вулиця",
+	"просп.": "проспект",
+	"проф.":  "професор",
+	"д-р":    "доктор",
+	"ім.":    "імені",
+	"див.":   "дивіться",
+	"обл.":   "область",
+	"каб.":   "кабінет",
+}
+
+func ukrainianRules() []normalizationRule {
+	return []normalizationRule{
+		urlRule(urlWords{dot: "крапка", slash: "слеш", at: "собака", dash: "дефіс", underscore: "підкреслення", www: "дабл-ю дабл-ю дабл-ю"}),
+		abbreviationRule(ukrainianAbbreviations),
+		{
+			// ISO 8601: 2024-03-05
+			pattern: regexp.MustCompile(boundary + `(\d{4})-(\d{1,2})-(\d{1,2})(\s*(?:року|р\.))?`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return ukrainianDate(groups[0], groups[1], groups[2])
+			},
+		},
+		{
+			// day first: 05.03.2024
+			pattern: regexp.MustCompile(boundary + `(\d{1,2})[./](\d{1,2})[./](\d{4})(\s*(?:року|р\.))?`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return ukrainianDate(groups[2], groups[1], groups[0])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(`()([$€£₴])\s?` + ukrainianNumber + `(?:[,.](\d{1,2}))?`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				return ukrainianMoney(groups[0], groups[1], groups[2])
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + ukrainianNumber + `(?:,(\d{1,2}))?\s?(грн\.?|дол\.|₴|\$|€|£|UAH|USD|EUR|GBP)`),
+			replace: func(groups []string, next rune) (string, bool) {
+				if !strings.HasSuffix(groups[2], ".") && isWordRune(next) {
+					return "", false
+				}
+
+				money, ok := ukrainianMoney(groups[2], groups[0], groups[1])
+				if ok && strings.HasSuffix(groups[2], ".") && next == 0 {
+					money += "."
+				}
+
+				return money, ok
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + ukrainianNumber + `(?:,(\d+))?\s?%`),
+			replace: func(groups []string, next rune) (string, bool) {
+				n, ok := parseGroupedInt(groups[0], ukrainianGroupSeparators)
+				if !ok {
+					return "", false
+				}
+
+				if groups[1] != "" {
+					return ukrainianDecimal(n, groups[1]) + " відсотка", true
+				}
+
+				return ukrainianCount(n, ukrainianPercent), true
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + `(\d+)-(ього|ого|го|ий|ій|й|та|те|а|я|е|є)`),
+			wordEnd: true,
+			replace: func(groups []string, next rune) (string, bool) {
+				n, ok := parseGroupedInt(groups[0], "")
+				if !ok {
+					return "", false
+				}
+
+				return ukrainianOrdinal(n, ukrainianOrdinalSuffixes[groups[1]]), true
+			},
+		},
+		{
+			pattern: regexp.MustCompile(boundary + `([-−]?)` + ukrainianNumber + `(?:,(\d+))?`),
+			replace: func(groups []string, next rune) (string, bool) {
+				var number string
+				if n, ok := parseGroupedInt(groups[1], ukrainianGroupSeparators); ok {
+					number = ukrainianDecimal(n, groups[2])
+				} else {
+					number = spellDigits(groups[1]+groups[2], ukrainianDigits())
+				}
+
+				if groups[0] != "" {
+					number = "мінус " + number
+				}
+
+				return separateFromLetter(number, next), true
+			},
+		},
+	}
+}
+
+func ukrainianCardinal(n int64, gender ukrainianGender) string {
+	if n < 0 {
+		return "мінус " + ukrainianCardinal(-n, gender)
+	}
+
+	if n == 0 {
+		return ukrainianOnes[0]
+	}
+
+	var words []string
+	for _, scale := range ukrainianScales {
+		if n < scale.value {
+			continue
+		}
+
+		count := n / scale.value
+		if count == 1 && scale.noun.gender == feminine {
+			words = append(words, scale.noun.forms[0])
+		} else {
+			words = append(words, ukrainianCount(count, scale.noun))
+		}
+		n %= scale.value
+	}
+
+	if n > 0 {
+		words = append(words, ukrainianTriplet(n, gender))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func ukrainianTriplet(n int64, gender ukrainianGender) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, ukrainianHundreds[n/100])
+		n %= 100
+	}
+
+	if n >= 20 {
+		words = append(words, ukrainianTens[n/10])
+		n %= 10
+	}
+
+	if n > 0 {
+		words = append(words, ukrainianUnit(n, gender))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func ukrainianUnit(n int64, gender ukrainianGender) string {
+	switch {
+	case n == 1 && gender == feminine:
+		return "одна"
+	case n == 1 && gender == neuter:
+		return "одне"
+	case n == 2 && gender == feminine:
+		return "дві"
+	default:
+		return ukrainianOnes[n]
+	}
+}
+
+// count followed by the noun in the matching plural form
+func ukrainianCount(n int64, noun ukrainianNoun) string {
+	return ukrainianCardinal(n, noun.gender) + " " + noun.forms[ukrainianPluralForm(n)]
+}
+
+func ukrainianPluralForm(n int64) int {
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return 2
+	case n%10 == 1:
+		return 0
+	case n%10 >= 2 && n%10 <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// only the last component of a compound ordinal is inflected:
+// 2024 -> дві тисячі двадцять четвертий
+func ukrainianOrdinal(n int64, grammaticalCase ukrainianCase) string {
+	if n < 0 || n >= 1_000_000 {
+		return ukrainianCardinal(n, masculine)
+	}
+
+	high, low := n/1000*1000, n%1000
+
+	var prefix []string
+	var stem string
+	switch {
+	case n == 0:
+		stem = ukrainianOrdinalOnes[0]
+	case low == 0:
+		thousands, ok := ukrainianOrdinalThousands[n/1000]
+		if !ok {
+			return ukrainianCardinal(n, masculine)
+		}
+		stem = thousands
+	default:
+		if high > 0 {
+			prefix = append(prefix, ukrainianCardinal(high, masculine))
+		}
+
+		rest := low % 100
+		switch {
+		case rest == 0:
+			stem = ukrainianOrdinalHundreds[low/100]
+		case rest < 20:
+			stem = ukrainianOrdinalOnes[rest]
+		case rest%10 == 0:
+			stem = ukrainianOrdinalTens[rest/10]
+		default:
+			stem = ukrainianOrdinalOnes[rest%10]
+		}
+
+		if rest != 0 && low >= 100 {
+			prefix = append(prefix, ukrainianHundreds[low/100])
+		}
+		if rest >= 20 && rest%10 != 0 {
+			prefix = append(prefix, ukrainianTens[rest/10])
+		}
+	}
+
+	endings := ukrainianHardEndings
+	if stem == "трет" {
+		endings = ukrainianSoftEndings
+	}
+
+	return strings.Join(append(prefix, stem+endings[grammaticalCase]), " ")
+}
+
+func ukrainianDate(year, month, day string) (string, bool) {
+	y, m, d, ok := parseDate(year, month, day)
+	if !ok {
+		return "", false
+	}
+
+	return ukrainianOrdinal(int64(d), nominativeNeuter) + " " + ukrainianMonths[m-1] + " " + ukrainianOrdinal(int64(y), genitiveMasculine) + " року", true
+}
+
+func ukrainianDecimal(n int64, fraction string) string {
+	number := ukrainianCardinal(n, masculine)
+	if fraction == "" {
+		return number
+	}
+
+	if strings.HasPrefix(fraction, "0") || len(fraction) > 3 {
+		return number + " кома " + spellDigits(fraction, ukrainianDigits())
+	}
+
+	value, _ := strconv.ParseInt(fraction, 10, 64)
+	return number + " кома " + ukrainianCardinal(value, masculine)
+}
+
+func ukrainianMoney(symbol, amount, fraction string) (string, bool) {
+	currency, ok := ukrainianCurrencies[symbol]
+	if !ok {
+		return "", false
+	}
+
+	major, ok := parseGroupedInt(amount, ukrainianGroupSeparators)
+	if !ok {
+		return "", false
+	}
+	minor := parseMinorUnits(fraction)
+
+	var parts []string
+	if major > 0 || minor == 0 {
+		parts = append(parts, ukrainianCount(major, currency.major))
+	}
+
+	if minor > 0 {
+		parts = append(parts, ukrainianCount(minor, currency.minor))
+	}
+
+	return strings.Join(parts, " "), true
+}
+
+func ukrainianDigits() [10]string {
+	var digits [10]string
+	copy(digits[:], ukrainianOnes[:10])
+	return digits
+}
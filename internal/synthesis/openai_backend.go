@@ -0,0 +1,78 @@
+package synthesis
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+const (
+	openAiSpeechPath     = "/v1/audio/speech"
+	defaultOpenAiModel   = "tts-1"
+	defaultOpenAiVoice   = "alloy"
+	openAiResponseFormat = "wav"
+)
+
+type openAiSpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`
+	Instructions   string  `json:"instructions,omitempty"`
+}
+
+// OpenAiBackend talks to OpenAI compatible speech endpoints. The model url is
+// the server base, the speaker picks the voice and the style is sent as
+// instructions.
+type OpenAiBackend struct {
+	client   *http.Client
+	apiKey   string
+	maxBytes int64
+}
+
+func NewOpenAiBackend(client *http.Client) *OpenAiBackend {
+	return &OpenAiBackend{client: client, apiKey: os.Getenv("MODEL_OPENAI_API_KEY"), maxBytes: maxResponseBytesFromEnv()}
+}
+
+func (b *OpenAiBackend) Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	request := &openAiSpeechRequest{
+		Model:          model.UpstreamModel,
+		Input:          text,
+		Voice:          params.Speaker,
+		ResponseFormat: openAiResponseFormat,
+		Speed:          params.Rate,
+		Instructions:   params.Style,
+	}
+
+	if request.Model == "" {
+		request.Model = defaultOpenAiModel
+	}
+
+	if request.Voice == "" {
+		request.Voice = defaultOpenAiVoice
+	}
+
+	var headers map[string]string
+	if b.apiKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + b.apiKey}
+	}
+
+	body, err := postJson(ctx, b.client, speechUrl(url), request, headers, b.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeWavResponse(body)
+}
+
+func speechUrl(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	if strings.HasSuffix(url, openAiSpeechPath) {
+		return url
+	}
+
+	return strings.TrimSuffix(url, "/v1") + openAiSpeechPath
+}
@@ -0,0 +1,66 @@
+package synthesis
+
+import (
+	"strings"
+	"unicode"
+)
+
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\n' {
+			sentences = appendSentence(sentences, runes[start:i])
+			start = i + 1
+			continue
+		}
+
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && (isSentenceTerminator(runes[end]) || isClosingPunctuation(runes[end])) {
+			end++
+		}
+
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			i = end - 1
+			continue
+		}
+
+		sentences = appendSentence(sentences, runes[start:end])
+		start = end
+		i = end - 1
+	}
+
+	return appendSentence(sentences, runes[start:])
+}
+
+func appendSentence(sentences []string, runes []rune) []string {
+	if sentence := strings.TrimSpace(string(runes)); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	return sentences
+}
+
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+
+	return false
+}
+
+func isClosingPunctuation(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '»', '”', '’':
+		return true
+	}
+
+	return false
+}
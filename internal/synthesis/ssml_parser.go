@@ -0,0 +1,504 @@
+package synthesis
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxBreakSeconds = 10.0
+
+var breakStrengths = map[string]float64{
+	"none":     0,
+	"x-weak":   0.1,
+	"weak":     0.25,
+	"medium":   0.4,
+	"strong":   0.7,
+	"x-strong": 1.0,
+}
+
+var prosodyRates = map[string]float64{
+	"x-slow":  0.5,
+	"slow":    0.75,
+	"medium":  1,
+	"default": 1,
+	"fast":    1.5,
+	"x-fast":  2,
+}
+
+var prosodyPitches = map[string]float64{
+	"x-low":   -6,
+	"low":     -3,
+	"medium":  0,
+	"default": 0,
+	"high":    3,
+	"x-high":  6,
+}
+
+var prosodyVolumes = map[string]float64{
+	"silent":  -96,
+	"x-soft":  -6,
+	"soft":    -3,
+	"medium":  0,
+	"default": 0,
+	"loud":    3,
+	"x-loud":  6,
+}
+
+type synthesisSegment struct {
+	Text       string
+	Break      float64
+	Parameters VoiceParameters
+}
+
+type ssmlParser struct {
+	decoder  *xml.Decoder
+	segments []synthesisSegment
+	boundary bool
+}
+
+func IsSsml(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "<speak")
+}
+
+func ValidateSsml(document string) error {
+	_, err := parseSsml(document)
+	return err
+}
+
+func parseSsml(document string) ([]synthesisSegment, error) {
+	decoder := xml.NewDecoder(strings.NewReader(document))
+	decoder.Strict = true
+
+	p := &ssmlParser{decoder: decoder}
+	if err := p.parseRoot(); err != nil {
+		return nil, err
+	}
+
+	var segments []synthesisSegment
+	for _, segment := range p.segments {
+		if segment.Break == 0 {
+			segment.Text = strings.Join(strings.Fields(segment.Text), " ")
+			if segment.Text == "" {
+				continue
+			}
+		}
+		segments = append(segments, segment)
+	}
+
+	for _, segment := range segments {
+		if segment.Text != "" {
+			return segments, nil
+		}
+	}
+
+	return nil, errors.New("SSML document contains no text to synthesize")
+}
+
+func (p *ssmlParser) parseRoot() error {
+	for {
+		token, err := p.decoder.Token()
+		if err == io.EOF {
+			return errors.New("SSML document must have a <speak> root element")
+		}
+		if err != nil {
+			return p.syntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "speak" {
+				return p.errorf("root element must be <speak>, found <%s>", t.Name.Local)
+			}
+
+			if err := p.parseChildren("speak", VoiceParameters{}); err != nil {
+				return err
+			}
+
+			return p.parseTrailing()
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return p.errorf("text is not allowed outside of <speak>")
+			}
+		}
+	}
+}
+
+func (p *ssmlParser) parseTrailing() error {
+	for {
+		token, err := p.decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return p.syntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			return p.errorf("only one <speak> element is allowed, found <%s> after it", t.Name.Local)
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return p.errorf("text is not allowed outside of <speak>")
+			}
+		}
+	}
+}
+
+func (p *ssmlParser) parseChildren(parent string, params VoiceParameters) error {
+	for {
+		token, err := p.decoder.Token()
+		if err == io.EOF || unexpectedEnd(err) {
+			return fmt.Errorf("unexpected end of SSML document: <%s> is not closed", parent)
+		}
+		if err != nil {
+			return p.syntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			p.appendText(string(t), params)
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			if err := p.parseElement(t, params); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *ssmlParser) parseElement(element xml.StartElement, params VoiceParameters) error {
+	name := element.Name.Local
+
+	switch name {
+	case "break":
+		duration, err := p.parseBreak(element)
+		if err != nil {
+			return err
+		}
+
+		p.segments = append(p.segments, synthesisSegment{Break: duration})
+		p.boundary = true
+		return p.parseEmpty(name)
+	case "prosody":
+		child, err := p.parseProsody(element)
+		if err != nil {
+			return err
+		}
+
+		p.boundary = true
+		if err := p.parseChildren(name, params.combine(child)); err != nil {
+			return err
+		}
+		p.boundary = true
+		return nil
+	case "p", "s":
+		p.boundary = true
+		if err := p.parseChildren(name, params); err != nil {
+			return err
+		}
+		p.boundary = true
+		return nil
+	case "say-as":
+		interpretAs := attribute(element, "interpret-as")
+		if interpretAs == "" {
+			return p.errorf("<say-as> requires an interpret-as attribute")
+		}
+
+		content, err := p.readText(name)
+		if err != nil {
+			return err
+		}
+
+		text, err := interpretSayAs(interpretAs, content)
+		if err != nil {
+			return p.errorf("%s", err.Error())
+		}
+
+		p.appendText(" "+text+" ", params)
+		return nil
+	case "sub":
+		alias := attribute(element, "alias")
+		if alias == "" {
+			return p.errorf("<sub> requires an alias attribute")
+		}
+
+		if _, err := p.readText(name); err != nil {
+			return err
+		}
+
+		p.appendText(" "+alias+" ", params)
+		return nil
+	case "phoneme":
+		ph := attribute(element, "ph")
+		if ph == "" {
+			return p.errorf("<phoneme> requires a ph attribute")
+		}
+
+		content, err := p.readText(name)
+		if err != nil {
+			return err
+		}
+
+		phonemeParams := params
+		phonemeParams.Phonemes = ph
+		phonemeParams.PhonemeAlphabet = attribute(element, "alphabet")
+
+		p.boundary = true
+		p.appendText(content, phonemeParams)
+		p.boundary = true
+		return nil
+	case "mark":
+		return p.parseEmpty(name)
+	default:
+		return p.errorf("unsupported SSML element <%s>", name)
+	}
+}
+
+func (p *ssmlParser) parseBreak(element xml.StartElement) (float64, error) {
+	if value := attribute(element, "time"); value != "" {
+		var seconds float64
+		var err error
+
+		switch {
+		case strings.HasSuffix(value, "ms"):
+			seconds, err = strconv.ParseFloat(strings.TrimSuffix(value, "ms"), 64)
+			seconds /= 1000
+		case strings.HasSuffix(value, "s"):
+			seconds, err = strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64)
+		default:
+			err = errors.New("missing unit")
+		}
+
+		if err != nil || seconds < 0 || math.IsNaN(seconds) {
+			return 0, p.errorf("invalid <break> time %q, expected a value such as \"500ms\" or \"1.5s\"", value)
+		}
+
+		if seconds > maxBreakSeconds {
+			return 0, p.errorf("<break> time %q exceeds the maximum of %gs", value, maxBreakSeconds)
+		}
+
+		return seconds, nil
+	}
+
+	strength := attribute(element, "strength")
+	if strength == "" {
+		return breakStrengths["medium"], nil
+	}
+
+	seconds, ok := breakStrengths[strength]
+	if !ok {
+		return 0, p.errorf("invalid <break> strength %q", strength)
+	}
+
+	return seconds, nil
+}
+
+func (p *ssmlParser) parseProsody(element xml.StartElement) (VoiceParameters, error) {
+	var params VoiceParameters
+
+	if value := attribute(element, "rate"); value != "" {
+		rate, ok := prosodyRates[value]
+		if !ok {
+			relative, err := parseRelative(value, "%")
+			if err != nil || relative <= 0 {
+				return params, p.errorf("invalid <prosody> rate %q", value)
+			}
+			rate = relative
+		}
+
+		if rate < 0.25 || rate > 4 {
+			return params, p.errorf("<prosody> rate %q is out of range", value)
+		}
+		params.Rate = rate
+	}
+
+	if value := attribute(element, "pitch"); value != "" {
+		pitch, ok := prosodyPitches[value]
+		if !ok {
+			var err error
+			switch {
+			case strings.HasSuffix(value, "st"):
+				pitch, err = strconv.ParseFloat(strings.TrimSuffix(value, "st"), 64)
+			case strings.HasSuffix(value, "%"):
+				var relative float64
+				relative, err = parseRelative(value, "%")
+				if err == nil && relative <= 0 {
+					err = errors.New("non-positive pitch")
+				}
+				pitch = 12 * math.Log2(relative)
+			default:
+				err = errors.New("unsupported unit")
+			}
+
+			if err != nil {
+				return params, p.errorf("invalid <prosody> pitch %q, expected a level, semitones (\"+2st\") or percentage", value)
+			}
+		}
+
+		if math.Abs(pitch) > 24 {
+			return params, p.errorf("<prosody> pitch %q is out of range", value)
+		}
+		params.Pitch = pitch
+	}
+
+	if value := attribute(element, "volume"); value != "" {
+		volume, ok := prosodyVolumes[value]
+		if !ok {
+			var err error
+			volume, err = strconv.ParseFloat(strings.TrimSuffix(value, "dB"), 64)
+			if err != nil || !strings.HasSuffix(value, "dB") {
+				return params, p.errorf("invalid <prosody> volume %q, expected a level or decibels (\"+6dB\")", value)
+			}
+		}
+
+		if volume > 24 || volume < prosodyVolumes["silent"] {
+			return params, p.errorf("<prosody> volume %q is out of range", value)
+		}
+		params.VolumeDb = volume
+	}
+
+	return params, nil
+}
+
+func (p *ssmlParser) parseEmpty(name string) error {
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return p.syntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return p.errorf("<%s> must be empty", name)
+			}
+		case xml.StartElement:
+			return p.errorf("<%s> must be empty", name)
+		}
+	}
+}
+
+func (p *ssmlParser) readText(name string) (string, error) {
+	var text strings.Builder
+
+	for {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return "", p.syntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			return text.String(), nil
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			return "", p.errorf("<%s> may only contain text, found <%s>", name, t.Name.Local)
+		}
+	}
+}
+
+func (p *ssmlParser) appendText(text string, params VoiceParameters) {
+	last := len(p.segments) - 1
+	if !p.boundary && last >= 0 && p.segments[last].Break == 0 && p.segments[last].Parameters == params {
+		p.segments[last].Text += text
+		return
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	p.segments = append(p.segments, synthesisSegment{Text: text, Parameters: params})
+	p.boundary = false
+}
+
+func (p *ssmlParser) errorf(format string, args ...interface{}) error {
+	line, _ := p.decoder.InputPos()
+	return fmt.Errorf("invalid SSML at line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *ssmlParser) syntaxError(err error) error {
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("malformed SSML at line %d: %s", syntaxErr.Line, syntaxErr.Msg)
+	}
+
+	return fmt.Errorf("malformed SSML: %s", err.Error())
+}
+
+// the strict decoder reports a document cut short as a syntax error
+func unexpectedEnd(err error) bool {
+	var syntaxErr *xml.SyntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.Msg == "unexpected EOF"
+}
+
+func attribute(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+
+	return ""
+}
+
+func parseRelative(value, unit string) (float64, error) {
+	if !strings.HasSuffix(value, unit) {
+		return strconv.ParseFloat(value, 64)
+	}
+
+	number := strings.TrimSuffix(value, unit)
+	percent, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if strings.HasPrefix(number, "+") || strings.HasPrefix(number, "-") {
+		return 1 + percent/100, nil
+	}
+
+	return percent / 100, nil
+}
+
+func interpretSayAs(interpretAs, content string) (string, error) {
+	content = strings.TrimSpace(content)
+
+	switch interpretAs {
+	case "characters", "spell-out", "verbatim":
+		return spaced(content, func(r rune) bool { return !unicode.IsSpace(r) }), nil
+	case "digits", "telephone":
+		return spaced(content, unicode.IsDigit), nil
+	case "cardinal", "number", "ordinal", "date", "time", "unit", "currency", "address", "expletive":
+		return content, nil
+	default:
+		return "", fmt.Errorf("unsupported <say-as> interpret-as %q", interpretAs)
+	}
+}
+
+func spaced(content string, keep func(rune) bool) string {
+	var parts []string
+	for _, group := range strings.FieldsFunc(content, func(r rune) bool { return unicode.IsSpace(r) || r == '-' || r == '(' || r == ')' }) {
+		var letters []string
+		for _, r := range group {
+			if keep(r) {
+				letters = append(letters, string(r))
+			}
+		}
+
+		if len(letters) > 0 {
+			parts = append(parts, strings.Join(letters, " "))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,148 @@
+package synthesis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+func TestParseSsml(t *testing.T) {
+	cases := []struct {
+		name     string
+		document string
+		expected []synthesisSegment
+	}{
+		{
+			name:     "plain text",
+			document: "<speak>Hello   world</speak>",
+			expected: []synthesisSegment{{Text: "Hello world"}},
+		},
+		{
+			name:     "break time",
+			document: `<speak>Hello<break time="500ms"/>world</speak>`,
+			expected: []synthesisSegment{{Text: "Hello"}, {Break: 0.5}, {Text: "world"}},
+		},
+		{
+			name:     "break strength",
+			document: `<speak>Hello<break strength="strong"/>world<break/></speak>`,
+			expected: []synthesisSegment{{Text: "Hello"}, {Break: 0.7}, {Text: "world"}, {Break: 0.4}},
+		},
+		{
+			name:     "prosody",
+			document: `<speak>Hello <prosody rate="slow" pitch="+2st" volume="-3dB">world</prosody></speak>`,
+			expected: []synthesisSegment{{Text: "Hello"}, {Text: "world", Parameters: VoiceParameters{Rate: 0.75, Pitch: 2, VolumeDb: -3}}},
+		},
+		{
+			name:     "nested prosody",
+			document: `<speak><prosody rate="200%"><prosody rate="50%">word</prosody></prosody></speak>`,
+			expected: []synthesisSegment{{Text: "word", Parameters: VoiceParameters{Rate: 1}}},
+		},
+		{
+			name:     "relative rate",
+			document: `<speak><prosody rate="+50%">fast</prosody></speak>`,
+			expected: []synthesisSegment{{Text: "fast", Parameters: VoiceParameters{Rate: 1.5}}},
+		},
+		{
+			name:     "sentences",
+			document: "<speak><p><s>One.</s><s>Two.</s></p></speak>",
+			expected: []synthesisSegment{{Text: "One."}, {Text: "Two."}},
+		},
+		{
+			name:     "say-as and sub",
+			document: `<speak>Call <say-as interpret-as="telephone">555-12</say-as> at <sub alias="World Wide Web">WWW</sub></speak>`,
+			expected: []synthesisSegment{{Text: "Call 5 5 5, 1 2 at World Wide Web"}},
+		},
+		{
+			name:     "phoneme",
+			document: `<speak>say <phoneme alphabet="ipa" ph="təˈmeɪtoʊ">tomato</phoneme></speak>`,
+			expected: []synthesisSegment{{Text: "say"}, {Text: "tomato", Parameters: VoiceParameters{Phonemes: "təˈmeɪtoʊ", PhonemeAlphabet: "ipa"}}},
+		},
+		{
+			name:     "mark and surrounding whitespace",
+			document: "<?xml version=\"1.0\"?>\n<speak>Hello <mark name=\"here\"/>world</speak>\n",
+			expected: []synthesisSegment{{Text: "Hello world"}},
+		},
+	}
+
+	for _, c := range cases {
+		segments, err := parseSsml(c.document)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(segments, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, segments)
+		}
+	}
+}
+
+func TestParseSsmlRejectsInvalidDocuments(t *testing.T) {
+	cases := []struct {
+		name     string
+		document string
+		message  string
+	}{
+		{name: "unclosed element", document: "<speak>Hello", message: "<speak> is not closed"},
+		{name: "mismatched tags", document: "<speak><p>Hello</s></speak>", message: "malformed SSML at line 1"},
+		{name: "bad entity", document: "<speak>Fish &chips</speak>", message: "malformed SSML"},
+		{name: "wrong root", document: "<voice>Hello</voice>", message: "root element must be <speak>, found <voice>"},
+		{name: "no root", document: "", message: "must have a <speak> root element"},
+		{name: "second root", document: "<speak>One</speak><speak>Two</speak>", message: "only one <speak> element is allowed"},
+		{name: "text outside root", document: "<speak>One</speak> two", message: "text is not allowed outside of <speak>"},
+		{name: "no text", document: `<speak><break time="1s"/></speak>`, message: "contains no text to synthesize"},
+		{name: "unsupported tag", document: "<speak><audio src=\"a.wav\"/>Hello</speak>", message: "unsupported SSML element <audio>"},
+		{name: "unsupported say-as", document: `<speak><say-as interpret-as="poem">x</say-as></speak>`, message: `unsupported <say-as> interpret-as "poem"`},
+		{name: "say-as without interpretation", document: "<speak><say-as>x</say-as></speak>", message: "requires an interpret-as attribute"},
+		{name: "sub without alias", document: "<speak><sub>x</sub></speak>", message: "<sub> requires an alias attribute"},
+		{name: "phoneme without ph", document: "<speak><phoneme>x</phoneme></speak>", message: "<phoneme> requires a ph attribute"},
+		{name: "nested markup in sub", document: `<speak><sub alias="a"><p>x</p></sub></speak>`, message: "<sub> may only contain text, found <p>"},
+		{name: "break with content", document: "<speak>a<break>text</break></speak>", message: "<break> must be empty"},
+		{name: "break without unit", document: `<speak>a<break time="500"/></speak>`, message: `invalid <break> time "500"`},
+		{name: "negative break", document: `<speak>a<break time="-1s"/></speak>`, message: `invalid <break> time "-1s"`},
+		{name: "break too long", document: `<speak>a<break time="11s"/></speak>`, message: `<break> time "11s" exceeds the maximum of 10s`},
+		{name: "unknown break strength", document: `<speak>a<break strength="huge"/></speak>`, message: `invalid <break> strength "huge"`},
+		{name: "invalid rate", document: `<speak><prosody rate="quick">a</prosody></speak>`, message: `invalid <prosody> rate "quick"`},
+		{name: "rate too low", document: `<speak><prosody rate="10%">a</prosody></speak>`, message: `<prosody> rate "10%" is out of range`},
+		{name: "rate too high", document: `<speak><prosody rate="500%">a</prosody></speak>`, message: `<prosody> rate "500%" is out of range`},
+		{name: "invalid pitch", document: `<speak><prosody pitch="2Hz">a</prosody></speak>`, message: `invalid <prosody> pitch "2Hz"`},
+		{name: "pitch too high", document: `<speak><prosody pitch="+25st">a</prosody></speak>`, message: `<prosody> pitch "+25st" is out of range`},
+		{name: "non-positive pitch", document: `<speak><prosody pitch="0%">a</prosody></speak>`, message: `invalid <prosody> pitch "0%"`},
+		{name: "invalid volume", document: `<speak><prosody volume="6">a</prosody></speak>`, message: `invalid <prosody> volume "6"`},
+		{name: "volume too loud", document: `<speak><prosody volume="+30dB">a</prosody></speak>`, message: `<prosody> volume "+30dB" is out of range`},
+		{name: "volume too soft", document: `<speak><prosody volume="-200dB">a</prosody></speak>`, message: `<prosody> volume "-200dB" is out of range`},
+	}
+
+	for _, c := range cases {
+		_, err := parseSsml(c.document)
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected %q in %q", c.name, c.message, err.Error())
+		}
+	}
+}
+
+func TestParseSsmlReportsLine(t *testing.T) {
+	_, err := parseSsml("<speak>\nHello\n<blink>world</blink>\n</speak>")
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid SSML at line 3:") {
+		t.Errorf("expected the error to point at line 3, got %v", err)
+	}
+}
+
+func TestPlanSegmentsRejectsInvalidSsmlAsBadRequest(t *testing.T) {
+	_, err := planSegments(&requests.SynthesisRequest{Text: `<speak><prosody rate="500%">a</prosody></speak>`})
+
+	badRequest, ok := err.(*service_errors.ErrBadRequest)
+	if !ok {
+		t.Fatalf("expected ErrBadRequest, got %T: %v", err, err)
+	}
+	if !strings.Contains(badRequest.Error(), "rate \"500%\" is out of range") {
+		t.Errorf("expected the parser message, got %q", badRequest.Error())
+	}
+}
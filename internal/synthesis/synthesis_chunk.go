@@ -0,0 +1,15 @@
+package synthesis
+
+import "vitaliiPsl/synthesizer/internal/subtitles"
+
+type SynthesisChunk struct {
+	Index        int                `json:"index"`
+	Text         string             `json:"text"`
+	Samples      []float32          `json:"samples,omitempty"`
+	SamplingRate int                `json:"sampling_rate"`
+	BitDepth     int                `json:"bit_depth,omitempty"`
+	Format       string             `json:"format,omitempty"`
+	Audio        []byte             `json:"audio,omitempty"`
+	Words        []subtitles.Timing `json:"words,omitempty"`
+	Sentences    []subtitles.Timing `json:"sentences,omitempty"`
+}
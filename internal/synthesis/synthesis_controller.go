@@ -1,22 +1,32 @@
 package synthesis
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"vitaliiPsl/synthesizer/internal/audio"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/subtitles"
 	"vitaliiPsl/synthesizer/internal/users"
 	"vitaliiPsl/synthesizer/internal/validation"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
 type SynthesisController struct {
 	synthesisService  SynthesisService
+	audioService      audio.AudioService
 	validationService *validation.ValidationService
 }
 
-func NewSynthesisController(synthesisService SynthesisService, validationService *validation.ValidationService) *SynthesisController {
+func NewSynthesisController(synthesisService SynthesisService, audioService audio.AudioService, validationService *validation.ValidationService) *SynthesisController {
 	return &SynthesisController{
 		synthesisService:  synthesisService,
+		audioService:      audioService,
 		validationService: validationService,
 	}
 }
@@ -24,40 +34,328 @@ func NewSynthesisController(synthesisService SynthesisService, validationService
 func (controller *SynthesisController) HandleSynthesis(c *fiber.Ctx) error {
 	logger.Logger.Info("Handling speech synthesis...")
 
-	var ok bool
-	var userDto *users.UserDto
+	userId, ok := optionalUserId(c.Locals("user"))
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.SynthesisRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse synthesis request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateSynthesisRequest(&req); err != nil {
+		logger.Logger.Error("Synthesis request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	format, err := controller.resolveFormat(c, &req)
+	if err != nil {
+		logger.Logger.Error("Failed to resolve audio format", "message", err.Error())
+		return err
+	}
+
+	result, err := controller.synthesisService.HandleSynthesisRequest(c.UserContext(), &req, userId)
+	if err != nil {
+		logger.Logger.Error("Failed to synthesize speech", "message", err.Error())
+		return err
+	}
+
+	c.Vary(fiber.HeaderAccept)
+	if format == audio.FormatJson {
+		logger.Logger.Info("Handled speech synthesis.")
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+
+	encoded, err := controller.audioService.EncodeWithBitDepth(result.Samples, result.SamplingRate, result.BitDepth, format)
+	if err != nil {
+		logger.Logger.Error("Failed to encode synthesized speech", "message", err.Error())
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, encoded.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"synthesis.%s\"", format.Extension()))
+
+	logger.Logger.Info("Handled speech synthesis.", "format", format)
+	return c.Status(fiber.StatusOK).Send(encoded.Data)
+}
+
+func (controller *SynthesisController) HandleNormalization(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling text normalization...")
+
+	var req requests.NormalizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse normalization request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateNormalizationRequest(&req); err != nil {
+		logger.Logger.Error("Normalization request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	result, err := controller.synthesisService.NormalizeText(&req)
+	if err != nil {
+		logger.Logger.Error("Failed to normalize text", "message", err.Error())
+		return err
+	}
+
+	logger.Logger.Info("Handled text normalization.", "language", result.Language)
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+func (controller *SynthesisController) HandleSubtitles(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling subtitles synthesis...")
+
+	userId, ok := optionalUserId(c.Locals("user"))
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+	}
+
+	var req requests.SynthesisRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Logger.Error("Failed to parse synthesis request", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := controller.validationService.ValidateSynthesisRequest(&req); err != nil {
+		logger.Logger.Error("Synthesis request didn't pass validation", "message", err.Error())
+		return err
+	}
+
+	format, err := subtitles.ParseFormat(c.Query("format"))
+	if err != nil {
+		return service_errors.NewErrBadRequest(err.Error())
+	}
+
+	granularity, err := subtitles.ParseGranularity(c.Query("granularity"))
+	if err != nil {
+		return service_errors.NewErrBadRequest(err.Error())
+	}
 
-	tempUser := c.Locals("user")
-	if tempUser != nil {
-		userDto, ok = tempUser.(*users.UserDto)
+	result, err := controller.synthesisService.HandleSynthesisRequest(c.UserContext(), &req, userId)
+	if err != nil {
+		logger.Logger.Error("Failed to synthesize speech", "message", err.Error())
+		return err
+	}
+
+	c.Attachment("synthesis." + format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+
+	logger.Logger.Info("Handled subtitles synthesis.", "format", format)
+	return c.Status(fiber.StatusOK).SendString(subtitles.Render(format, granularity, result.Sentences, result.Words))
+}
+
+func (controller *SynthesisController) offeredContentTypes() []string {
+	var offered []string
+	for _, contentType := range audio.ContentTypes() {
+		format, _ := audio.FormatFromContentType(contentType)
+		if format == audio.FormatJson || controller.audioService.Supports(format) {
+			offered = append(offered, contentType)
+		}
+	}
+
+	return offered
+}
+
+func (controller *SynthesisController) resolveFormat(c *fiber.Ctx, req *requests.SynthesisRequest) (audio.AudioFormat, error) {
+	format := audio.FormatJson
+
+	if req.Format != "" {
+		parsed, ok := audio.ParseAudioFormat(req.Format)
+		if !ok {
+			return "", service_errors.NewErrBadRequest("Unsupported audio format: " + req.Format)
+		}
+		format = parsed
+	} else {
+		// only formats that can actually be encoded are offered
+		accepted := c.Accepts(controller.offeredContentTypes()...)
+		if accepted == "" {
+			return "", service_errors.NewErrNotAcceptable("None of the accepted content types is supported")
+		}
+
+		parsed, ok := audio.FormatFromContentType(accepted)
 		if !ok {
-			logger.Logger.Error("Failed to convert context value to UserDto")
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
+			return "", service_errors.NewErrNotAcceptable("Unsupported content type: " + accepted)
 		}
+		format = parsed
 	}
 
-	userId := ""
-	if userDto != nil {
-		userId = userDto.Id
+	if format != audio.FormatJson && !controller.audioService.Supports(format) {
+		return "", service_errors.NewErrBadRequest("Unsupported audio format: " + string(format))
+	}
+
+	return format, nil
+}
+
+func (controller *SynthesisController) HandleStreamingSynthesis(c *fiber.Ctx) error {
+	logger.Logger.Info("Handling streaming speech synthesis...")
+
+	userId, ok := optionalUserId(c.Locals("user"))
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
 	}
 
 	var req requests.SynthesisRequest
 	if err := c.BodyParser(&req); err != nil {
-		logger.Logger.Error("Failed to parse synthesis request", err)
+		logger.Logger.Error("Failed to parse synthesis request", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if err := controller.validationService.ValidateSynthesisRequest(&req); err != nil {
-		logger.Logger.Error("Synthesis request didn't pass validation", "message", err.Error(), err)
+	format, err := controller.validateStreamingRequest(&req)
+	if err != nil {
+		logger.Logger.Error("Streaming synthesis request didn't pass validation", "message", err.Error())
 		return err
 	}
 
-	result, err := controller.synthesisService.HandleSynthesisRequest(&req, userId)
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream := newStreamWriter(w, w.Flush, cancel)
+		stopKeepAlive := stream.keepAlive(ctx, streamHeartbeatInterval)
+
+		_, err := controller.synthesisService.HandleStreamingSynthesisRequest(ctx, &req, userId, func(chunk *SynthesisChunk) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := controller.encodeChunk(chunk, format); err != nil {
+				return err
+			}
+			return stream.send(&SynthesisStreamMessage{Type: StreamMessageChunk, Chunk: chunk})
+		})
+		stopKeepAlive()
+
+		if err := stream.send(streamResultMessage(err)); err != nil {
+			logger.Logger.Info("Client went away during streaming synthesis.", "message", err.Error())
+		}
+		logger.Logger.Info("Handled streaming speech synthesis.")
+	})
+
+	return nil
+}
+
+func (controller *SynthesisController) RequireWebSocketUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	return c.Next()
+}
+
+func (controller *SynthesisController) HandleStreamingSynthesisSocket(conn *websocket.Conn) {
+	logger.Logger.Info("Handling streaming speech synthesis over WebSocket...")
+
+	userId, ok := optionalUserId(conn.Locals("user"))
+	if !ok {
+		logger.Logger.Error("Failed to convert context value to UserDto")
+		conn.WriteJSON(&SynthesisStreamMessage{Type: StreamMessageError, Error: "Internal server error"})
+		return
+	}
+
+	var req requests.SynthesisRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		logger.Logger.Error("Failed to parse synthesis request", "error", err)
+		conn.WriteJSON(&SynthesisStreamMessage{Type: StreamMessageError, Error: "Invalid request body"})
+		return
+	}
+
+	format, err := controller.validateStreamingRequest(&req)
+	if err != nil {
+		logger.Logger.Error("Streaming synthesis request didn't pass validation", "message", err.Error())
+		conn.WriteJSON(streamResultMessage(err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		for {
+			var message SynthesisStreamMessage
+			if err := conn.ReadJSON(&message); err != nil || message.Type == StreamMessageCancel {
+				return
+			}
+		}
+	}()
+
+	_, err = controller.synthesisService.HandleStreamingSynthesisRequest(ctx, &req, userId, func(chunk *SynthesisChunk) error {
+		if err := controller.encodeChunk(chunk, format); err != nil {
+			return err
+		}
+		return conn.WriteJSON(&SynthesisStreamMessage{Type: StreamMessageChunk, Chunk: chunk})
+	})
+
+	conn.WriteJSON(streamResultMessage(err))
+	logger.Logger.Info("Handled streaming speech synthesis over WebSocket.")
+}
+
+func (controller *SynthesisController) validateStreamingRequest(req *requests.SynthesisRequest) (audio.AudioFormat, error) {
+	if err := controller.validationService.ValidateSynthesisRequest(req); err != nil {
+		return "", err
+	}
+
+	if req.Format == "" {
+		return audio.FormatJson, nil
+	}
+
+	format, ok := audio.ParseAudioFormat(req.Format)
+	if !ok || (format != audio.FormatJson && !controller.audioService.Supports(format)) {
+		return "", service_errors.NewErrBadRequest("Unsupported audio format: " + req.Format)
+	}
+
+	return format, nil
+}
+
+func (controller *SynthesisController) encodeChunk(chunk *SynthesisChunk, format audio.AudioFormat) error {
+	if format == audio.FormatJson {
+		return nil
+	}
+
+	encoded, err := controller.audioService.EncodeWithBitDepth(chunk.Samples, chunk.SamplingRate, chunk.BitDepth, format)
 	if err != nil {
-		logger.Logger.Error("Failed to synthesize speech", "message", err.Error(), err)
 		return err
 	}
 
-	logger.Logger.Info("Handled speech synthesis.")
-	return c.Status(fiber.StatusOK).JSON(result)
+	chunk.Samples = nil
+	chunk.Format = string(format)
+	chunk.Audio = encoded.Data
+	return nil
+}
+
+func streamResultMessage(err error) *SynthesisStreamMessage {
+	if err == nil {
+		return &SynthesisStreamMessage{Type: StreamMessageDone}
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return &SynthesisStreamMessage{Type: StreamMessageCancelled}
+	}
+
+	switch e := err.(type) {
+	case *service_errors.ErrNotFound, *service_errors.ErrBadRequest, *service_errors.ErrForbidden, *service_errors.ErrUnauthorized, *service_errors.ErrBadGateway, *service_errors.ErrGone, *service_errors.ErrServiceUnavailable:
+		return &SynthesisStreamMessage{Type: StreamMessageError, Error: e.Error()}
+	default:
+		return &SynthesisStreamMessage{Type: StreamMessageError, Error: "Failed to synthesize speech"}
+	}
+}
+
+func optionalUserId(value interface{}) (string, bool) {
+	if value == nil {
+		return "", true
+	}
+
+	userDto, ok := value.(*users.UserDto)
+	if !ok {
+		return "", false
+	}
+
+	return userDto.Id, true
 }
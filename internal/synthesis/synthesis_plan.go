@@ -0,0 +1,217 @@
+package synthesis
+
+import (
+	"strings"
+	"vitaliiPsl/synthesizer/internal/dsp"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/lexicon"
+	"vitaliiPsl/synthesizer/internal/logger"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+// a run of consecutive segments synthesized by the same model
+type segmentRoute struct {
+	model    *model.ModelDto
+	segments []synthesisSegment
+}
+
+// prepared segments of a request together with the model each one is sent to
+type synthesisPlan struct {
+	segments []synthesisSegment
+	models   []*model.ModelDto
+	// the model of the first route, recorded in history
+	primary *model.ModelDto
+	lexicon *lexicon.ResolvedLexicon
+	// set when segments go to different models, whose sampling rates may differ
+	mixed bool
+}
+
+func (s *SynthesisServiceImpl) plan(req *requests.SynthesisRequest, userId string, pack bool) (*synthesisPlan, error) {
+	segments, err := planSegments(req)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := s.route(req, userId, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &synthesisPlan{primary: routes[0].model}
+	lexicons := make(map[string]*lexicon.ResolvedLexicon)
+
+	for _, route := range routes {
+		if err := validateSegments(route.model, route.segments); err != nil {
+			return nil, err
+		}
+
+		resolved, ok := lexicons[route.model.Language]
+		if !ok {
+			resolved, err = s.lexiconService.ResolveLexicon(userId, route.model.Language)
+			if err != nil {
+				return nil, err
+			}
+			lexicons[route.model.Language] = resolved
+		}
+
+		if plan.lexicon == nil {
+			plan.lexicon = resolved
+		}
+
+		routed := applyLexicon(route.segments, resolved.Entries)
+		routed = s.normalizeSegments(route.model.Language, routed)
+		routed = splitSegments(routed, s.maxInputLength(route.model), pack)
+
+		for _, segment := range routed {
+			plan.segments = append(plan.segments, segment)
+			plan.models = append(plan.models, route.model)
+		}
+
+		if route.model.Id != plan.primary.Id {
+			plan.mixed = true
+		}
+	}
+
+	return plan, nil
+}
+
+// route decides which model synthesizes which part of the request: the named
+// model, the default model of the named language, or, for automatic
+// detection, the default model of every language found in the text
+func (s *SynthesisServiceImpl) route(req *requests.SynthesisRequest, userId string, segments []synthesisSegment) ([]segmentRoute, error) {
+	if req.ModelId != "" {
+		selected, err := s.modelService.GetModelById(req.ModelId)
+		if err != nil {
+			return nil, err
+		}
+
+		serving, err := servingModel(selected, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return []segmentRoute{{model: serving, segments: segments}}, nil
+	}
+
+	if !strings.EqualFold(req.Language, languageAuto) {
+		serving, err := s.modelForLanguage(req.Language, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		return []segmentRoute{{model: serving, segments: segments}}, nil
+	}
+
+	languages, err := s.modelService.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(languages) == 0 {
+		logger.Logger.Error("No models available for language detection")
+		return nil, service_errors.NewErrNotFound("No models are available")
+	}
+
+	detect := func(text string) string {
+		return s.identifier.Detect(text, languages...).Language
+	}
+
+	var texts []string
+	for _, segment := range segments {
+		texts = append(texts, segment.Text)
+	}
+
+	fallback := detect(strings.Join(texts, " "))
+	if fallback == "" {
+		logger.Logger.Error("Failed to detect the language of the text")
+		return nil, service_errors.NewErrBadRequest("Couldn't detect the language of the text, specify a language or a model")
+	}
+
+	var routes []segmentRoute
+	models := make(map[string]*model.ModelDto)
+	current := ""
+
+	add := func(language string, segment synthesisSegment) error {
+		if len(routes) == 0 || language != current {
+			serving, ok := models[language]
+			if !ok {
+				var err error
+				if serving, err = s.modelForLanguage(language, userId); err != nil {
+					return err
+				}
+				models[language] = serving
+			}
+
+			routes = append(routes, segmentRoute{model: serving})
+			current = language
+		}
+
+		last := &routes[len(routes)-1]
+		last.segments = append(last.segments, segment)
+		return nil
+	}
+
+	for _, segment := range segments {
+		if segment.Text == "" {
+			language := current
+			if language == "" {
+				language = fallback
+			}
+
+			if err := add(language, segment); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, span := range splitLanguages(segment.Text, detect, fallback) {
+			piece := segment
+			piece.Text = span.Text
+			if err := add(span.Language, piece); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	logger.Logger.Info("Detected languages.", "routes", len(routes), "languages", len(models))
+	return routes, nil
+}
+
+func (s *SynthesisServiceImpl) modelForLanguage(language, userId string) (*model.ModelDto, error) {
+	selected, err := s.modelService.GetModelForLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+
+	return servingModel(selected, userId)
+}
+
+// servingModel rejects models that can't synthesize and picks the version
+// serving this caller
+func servingModel(selected *model.ModelDto, userId string) (*model.ModelDto, error) {
+	if selected.ArchivedAt != nil {
+		logger.Logger.Error("Model is archived", "modelId", selected.Id)
+		return nil, service_errors.NewErrGone("Model has been archived and is no longer available for synthesis")
+	}
+
+	if selected.Down() {
+		logger.Logger.Error("Model is down", "modelId", selected.Id)
+		return nil, service_errors.NewErrServiceUnavailable("Model is currently unavailable, try again later")
+	}
+
+	return selected.ForRequest(userId), nil
+}
+
+// models of a mixed plan may disagree on the sampling rate, their audio is
+// brought to the rate of the first piece
+func conformSamplingRate(response *SynthesisResponse, samplingRate int) *SynthesisResponse {
+	if samplingRate == 0 || response.SamplingRate == samplingRate {
+		return response
+	}
+
+	conformed := *response
+	conformed.Samples = dsp.Resample(response.Samples, response.SamplingRate, samplingRate)
+	conformed.SamplingRate = samplingRate
+	return &conformed
+}
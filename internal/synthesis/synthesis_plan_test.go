@@ -0,0 +1,70 @@
+package synthesis
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/requests"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestServingModel(t *testing.T) {
+	archivedAt := time.Now()
+
+	cases := []struct {
+		name  string
+		model *model.ModelDto
+		gone  bool
+	}{
+		{name: "active", model: &model.ModelDto{Id: "model", Url: "http://model"}},
+		{name: "archived", model: &model.ModelDto{Id: "model", Url: "http://model", ArchivedAt: &archivedAt}, gone: true},
+	}
+
+	for _, c := range cases {
+		serving, err := servingModel(c.model, "user-1")
+		if !c.gone {
+			if err != nil || serving.Url != "http://model" {
+				t.Errorf("%s: expected the model to serve, got %+v and %v", c.name, serving, err)
+			}
+			continue
+		}
+
+		var gone *service_errors.ErrGone
+		if !errors.As(err, &gone) {
+			t.Errorf("%s: expected gone, got %v", c.name, err)
+		}
+	}
+}
+
+func TestSynthesisWithArchivedModelIsGone(t *testing.T) {
+	archivedAt := time.Now()
+	client := &countingModelClient{}
+	service := newStreamingService(client)
+	service.modelService = &stubModelService{model: &model.ModelDto{Id: "model", Language: "en", ArchivedAt: &archivedAt}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: service_errors.ErrorHandler})
+	app.Post("/synthesis", func(c *fiber.Ctx) error {
+		_, err := service.HandleSynthesisRequest(context.Background(), &requests.SynthesisRequest{ModelId: "model", Text: "Hello."}, "user-1")
+		if err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	res, err := app.Test(httptest.NewRequest("POST", "/synthesis", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != fiber.StatusGone {
+		t.Errorf("expected 410, got %d", res.StatusCode)
+	}
+	if client.calls != 0 {
+		t.Errorf("archived model should not be called, got %d calls", client.calls)
+	}
+}
@@ -1,6 +1,17 @@
 package synthesis
 
+import "vitaliiPsl/synthesizer/internal/subtitles"
+
 type SynthesisResponse struct {
 	Samples      []float32 `json:"samples"`
 	SamplingRate int       `json:"sampling_rate"`
+	// interleaved channels reported by the model, down-mixed right after synthesis
+	Channels int `json:"channels,omitempty"`
+	// set when samples were quantized to an integer PCM depth
+	BitDepth int `json:"bit_depth,omitempty"`
+
+	// alignment is optional: words only come from models that report them,
+	// sentences fall back to estimates from chunk durations
+	Words     []subtitles.Timing `json:"words,omitempty"`
+	Sentences []subtitles.Timing `json:"sentences,omitempty"`
 }
@@ -1,83 +1,513 @@
 package synthesis
 
 import (
-	"encoding/json"
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"vitaliiPsl/synthesizer/internal/audio"
+	"vitaliiPsl/synthesizer/internal/cache"
+	"vitaliiPsl/synthesizer/internal/dsp"
+	service_errors "vitaliiPsl/synthesizer/internal/error"
 	"vitaliiPsl/synthesizer/internal/history"
+	"vitaliiPsl/synthesizer/internal/langid"
+	"vitaliiPsl/synthesizer/internal/lexicon"
 	"vitaliiPsl/synthesizer/internal/logger"
 	"vitaliiPsl/synthesizer/internal/model"
 	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+const textTypeSsml = "ssml"
 
-	"github.com/gofiber/fiber/v2"
+const (
+	defaultMaxInputLength   = 400
+	defaultChunkConcurrency = 4
 )
 
 type SynthesisService interface {
-	HandleSynthesisRequest(req *requests.SynthesisRequest, userId string) (*SynthesisResponse, error)
+	HandleSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string) (*SynthesisResponse, error)
+	HandleStreamingSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string, onChunk func(*SynthesisChunk) error) (*history.HistoryRecordDto, error)
+	NormalizeText(req *requests.NormalizationRequest) (*NormalizationResponse, error)
 }
 
 type SynthesisServiceImpl struct {
 	modelService   model.ModelService
 	historyService history.HistoryService
+	audioService   audio.AudioService
+	cache          cache.SynthesisCache
+	modelClient    ModelClient
+	normalizer     TextNormalizer
+	lexiconService lexicon.LexiconService
+	joiner         *audioJoiner
+	processor      *dsp.AudioProcessor
+	identifier     *langid.Identifier
+
+	defaultMaxInputLength int
+	chunkConcurrency      int
 }
 
-func NewSynthesisService(modelService model.ModelService, historyService history.HistoryService) *SynthesisServiceImpl {
+func NewSynthesisService(modelService model.ModelService, historyService history.HistoryService, audioService audio.AudioService, synthesisCache cache.SynthesisCache, modelClient ModelClient, normalizer TextNormalizer, lexiconService lexicon.LexiconService) *SynthesisServiceImpl {
 	return &SynthesisServiceImpl{
 		modelService:   modelService,
 		historyService: historyService,
+		audioService:   audioService,
+		cache:          synthesisCache,
+		modelClient:    modelClient,
+		normalizer:     normalizer,
+		lexiconService: lexiconService,
+		joiner:         newAudioJoiner(),
+		processor:      dsp.NewAudioProcessor(),
+		identifier:     langid.NewIdentifier(),
+
+		defaultMaxInputLength: intFromEnv("MODEL_MAX_INPUT_LENGTH", defaultMaxInputLength),
+		chunkConcurrency:      max(1, intFromEnv("SYNTHESIS_CHUNK_CONCURRENCY", defaultChunkConcurrency)),
 	}
 }
 
-func (s *SynthesisServiceImpl) HandleSynthesisRequest(req *requests.SynthesisRequest, userId string) (*SynthesisResponse, error) {
+func (s *SynthesisServiceImpl) HandleSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string) (*SynthesisResponse, error) {
 	logger.Logger.Info("Handling synthesis...", "userId", userId)
 
-	model, err := s.modelService.GetModelById(req.ModelId)
+	plan, err := s.plan(req, userId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	responses, err := s.synthesizeAll(ctx, plan)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := s.performSynthesis(model, req.Text)
+	response, err := s.assemble(plan.segments, responses)
 	if err != nil {
 		return nil, err
 	}
+	response = s.postProcess(response, req.AudioOptions)
+	latency := time.Since(startedAt)
 
 	if userId != "" {
-		err = s.saveHistoryRecord(req, userId)
+		_, err = s.saveHistoryRecord(req, userId, plan.primary, plan.lexicon, response, latency)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	logger.Logger.Info("Handled synthesis.", "userId", userId)
+	logger.Logger.Info("Handled synthesis.", "userId", userId, "segments", len(plan.segments))
 	return response, nil
 }
 
-func (s *SynthesisServiceImpl) performSynthesis(model *model.ModelDto, text string) (*SynthesisResponse, error) {
-	logger.Logger.Info("Performing synthesis...", "name", model.Name, "language", model.Name, "url", model.Url)
+func (s *SynthesisServiceImpl) HandleStreamingSynthesisRequest(ctx context.Context, req *requests.SynthesisRequest, userId string, onChunk func(*SynthesisChunk) error) (*history.HistoryRecordDto, error) {
+	logger.Logger.Info("Handling streaming synthesis...", "userId", userId)
+
+	if req.TrimSilence || req.Loudness != 0 {
+		logger.Logger.Error("Streaming synthesis doesn't support whole audio processing", "userId", userId)
+		return nil, service_errors.NewErrBadRequest("Silence trimming and loudness normalization are not available for streaming")
+	}
+
+	plan, err := s.plan(req, userId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var synthesized SynthesisResponse
+	var samplingRate int
+	var latency time.Duration
+	var pendingSilence float64
+	var hasBreak bool
+	var previousText string
+	index := 0
+
+	emit := func(text string, response *SynthesisResponse) error {
+		response = s.postProcess(response, req.AudioOptions)
+
+		var words, sentences []subtitles.Timing
+		if text != "" {
+			words, sentences = pieceTimings(text, response)
+		}
+
+		if userId != "" {
+			offsetMs := samplesToMs(len(synthesized.Samples), response.SamplingRate)
+			synthesized.Words = append(synthesized.Words, subtitles.Offset(words, offsetMs)...)
+			synthesized.Sentences = append(synthesized.Sentences, subtitles.Offset(sentences, offsetMs)...)
+			synthesized.Samples = append(synthesized.Samples, response.Samples...)
+			synthesized.SamplingRate = response.SamplingRate
+			synthesized.BitDepth = response.BitDepth
+		}
+
+		chunk := &SynthesisChunk{
+			Index:        index,
+			Text:         text,
+			Samples:      response.Samples,
+			SamplingRate: response.SamplingRate,
+			BitDepth:     response.BitDepth,
+			Words:        words,
+			Sentences:    sentences,
+		}
+		index++
+
+		if err := onChunk(chunk); err != nil {
+			logger.Logger.Info("Streaming synthesis aborted by consumer.", "userId", userId, "sent", index-1, "message", err.Error())
+			return err
+		}
+
+		return nil
+	}
+
+	for i, segment := range plan.segments {
+		if err := ctx.Err(); err != nil {
+			logger.Logger.Info("Streaming synthesis cancelled.", "userId", userId, "sent", i, "total", len(plan.segments))
+			return nil, err
+		}
+
+		if segment.Text == "" {
+			pendingSilence += segment.Break
+			hasBreak = true
+			continue
+		}
+
+		startedAt := time.Now()
+		response, err := s.synthesize(ctx, plan.models[i], segment)
+		if err != nil {
+			return nil, err
+		}
+		latency += time.Since(startedAt)
+
+		if plan.mixed {
+			response = conformSamplingRate(response, samplingRate)
+		}
+
+		if err := checkSamplingRate(samplingRate, response.SamplingRate); err != nil {
+			return nil, err
+		}
+
+		gap := pendingSilence
+		if index > 0 {
+			gap = s.joiner.gap(previousText, pendingSilence, hasBreak)
+		}
+
+		if gap > 0 {
+			if err := emit("", &SynthesisResponse{Samples: silence(gap, response.SamplingRate), SamplingRate: response.SamplingRate}); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := emit(segment.Text, response); err != nil {
+			return nil, err
+		}
+		samplingRate = response.SamplingRate
+		previousText = segment.Text
+		pendingSilence = 0
+		hasBreak = false
+	}
+
+	if pendingSilence > 0 && samplingRate > 0 {
+		if err := emit("", &SynthesisResponse{Samples: silence(pendingSilence, samplingRate), SamplingRate: samplingRate}); err != nil {
+			return nil, err
+		}
+	}
+
+	var record *history.HistoryRecordDto
+	if userId != "" {
+		record, err = s.saveHistoryRecord(req, userId, plan.primary, plan.lexicon, &synthesized, latency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Logger.Info("Handled streaming synthesis.", "userId", userId, "chunks", index)
+	return record, nil
+}
+
+func (s *SynthesisServiceImpl) NormalizeText(req *requests.NormalizationRequest) (*NormalizationResponse, error) {
+	language := req.Language
+	if language == "" {
+		model, err := s.modelService.GetModelById(req.ModelId)
+		if err != nil {
+			return nil, err
+		}
+		language = model.Language
+	}
+
+	if !s.normalizer.Supports(language) {
+		logger.Logger.Error("Text normalization is not supported", "language", language)
+		return nil, service_errors.NewErrBadRequest("Text normalization is not supported for language: " + language)
+	}
+
+	return &NormalizationResponse{Text: s.normalizer.Normalize(req.Text, language), Language: language}, nil
+}
+
+// synthesizes text segments with bounded concurrency; the first failure cancels the rest
+func (s *SynthesisServiceImpl) synthesizeAll(ctx context.Context, plan *synthesisPlan) ([]*SynthesisResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*SynthesisResponse, len(plan.segments))
+	semaphore := make(chan struct{}, s.chunkConcurrency)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, segment := range plan.segments {
+		if segment.Text == "" {
+			continue
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, segment synthesisSegment) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			response, err := s.synthesize(ctx, plan.models[i], segment)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			responses[i] = response
+		}(i, segment)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if plan.mixed {
+		samplingRate := 0
+		for i, response := range responses {
+			if response == nil {
+				continue
+			}
+
+			responses[i] = conformSamplingRate(response, samplingRate)
+			samplingRate = responses[i].SamplingRate
+		}
+	}
+
+	return responses, nil
+}
+
+func (s *SynthesisServiceImpl) assemble(segments []synthesisSegment, responses []*SynthesisResponse) (*SynthesisResponse, error) {
+	var result SynthesisResponse
+	var pendingSilence float64
+	var hasBreak bool
+	var previousText string
+
+	for i, segment := range segments {
+		if segment.Text == "" {
+			pendingSilence += segment.Break
+			hasBreak = true
+			continue
+		}
+
+		response := responses[i]
+		if err := checkSamplingRate(result.SamplingRate, response.SamplingRate); err != nil {
+			return nil, err
+		}
+		result.SamplingRate = response.SamplingRate
+
+		if previousText == "" {
+			result.Samples = append(result.Samples, silence(pendingSilence, result.SamplingRate)...)
+			result.Samples = append(result.Samples, response.Samples...)
+		} else {
+			gap := s.joiner.gap(previousText, pendingSilence, hasBreak)
+			result.Samples = s.joiner.join(result.Samples, response.Samples, gap, result.SamplingRate)
+		}
+
+		offsetMs := samplesToMs(len(result.Samples)-len(response.Samples), result.SamplingRate)
+		words, sentences := pieceTimings(segment.Text, response)
+		result.Words = append(result.Words, subtitles.Offset(words, offsetMs)...)
+		result.Sentences = append(result.Sentences, subtitles.Offset(sentences, offsetMs)...)
+
+		previousText = segment.Text
+		pendingSilence = 0
+		hasBreak = false
+	}
+	result.Samples = append(result.Samples, silence(pendingSilence, result.SamplingRate)...)
+
+	return &result, nil
+}
+
+func (s *SynthesisServiceImpl) maxInputLength(model *model.ModelDto) int {
+	if model.MaxInputLength > 0 {
+		return model.MaxInputLength
+	}
+
+	return s.defaultMaxInputLength
+}
+
+func (s *SynthesisServiceImpl) normalizeSegments(language string, segments []synthesisSegment) []synthesisSegment {
+	normalized := make([]synthesisSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Text != "" {
+			segment.Text = s.normalizer.Normalize(segment.Text, language)
+			if segment.Text == "" {
+				continue
+			}
+		}
+		normalized = append(normalized, segment)
+	}
 
-	agent := fiber.Post(model.Url)
-	agent.JSON(fiber.Map{"text": text})
+	return normalized
+}
+
+func (s *SynthesisServiceImpl) synthesize(ctx context.Context, model *model.ModelDto, segment synthesisSegment) (*SynthesisResponse, error) {
+	params, post, err := resolveVoiceParameters(&model.Capabilities, segment.Parameters)
+	if err != nil {
+		return nil, service_errors.NewErrBadRequest("Unsupported voice parameters: " + err.Error())
+	}
 
-	statusCode, resBody, errs := agent.Bytes()
-	if len(errs) > 0 || statusCode != 200 {
-		logger.Logger.Error("Failed to synthesize speech")
-		return nil, errs[0]
+	key := cache.NewCacheKey(model.Id, model.ServingVersionId, model.Url, segment.Text, params.cacheVariant())
+	if cached, ok := s.cache.Get(key); ok {
+		logger.Logger.Info("Synthesis cache hit.", "modelId", model.Id)
+		return post.response(&SynthesisResponse{Samples: cached.Samples, SamplingRate: cached.SamplingRate, Words: cached.Words, Sentences: cached.Sentences}), nil
 	}
 
-	var response *SynthesisResponse
-	err := json.Unmarshal(resBody, &response)
+	logger.Logger.Info("Performing synthesis...", "name", model.Name, "language", model.Language, "url", model.Url)
+
+	response, err := s.modelClient.Synthesize(ctx, model, segment.Text, params)
 	if err != nil {
-		logger.Logger.Error("Failed to unmarshal synthesis response")
 		return nil, err
 	}
 
-	return response, nil
+	// everything after the model works on a single channel
+	if response.Channels > 1 {
+		response.Samples = dsp.Downmix(response.Samples, response.Channels)
+		response.Channels = 0
+	}
+
+	s.cache.Put(key, &cache.CachedAudio{Samples: response.Samples, SamplingRate: response.SamplingRate, Words: response.Words, Sentences: response.Sentences})
+	return post.response(response), nil
 }
 
-func (s *SynthesisServiceImpl) saveHistoryRecord(req *requests.SynthesisRequest, userId string) error {
+func (s *SynthesisServiceImpl) postProcess(response *SynthesisResponse, options requests.AudioOptions) *SynthesisResponse {
+	processed := s.processor.Process(response.Samples, response.SamplingRate, 1, dsp.Options{
+		SamplingRate: options.SampleRate,
+		TrimSilence:  options.TrimSilence,
+		LoudnessLufs: options.Loudness,
+		BitDepth:     options.BitDepth,
+	})
+
+	durationMs := samplesToMs(len(processed.Samples), processed.SamplingRate)
+	return &SynthesisResponse{
+		Samples:      processed.Samples,
+		SamplingRate: processed.SamplingRate,
+		BitDepth:     processed.BitDepth,
+		Words:        trimTimings(response.Words, processed.TrimmedMs, durationMs),
+		Sentences:    trimTimings(response.Sentences, processed.TrimmedMs, durationMs),
+	}
+}
+
+func planSegments(req *requests.SynthesisRequest) ([]synthesisSegment, error) {
+	base := voiceParametersFromOptions(req.VoiceOptions)
+	segments := []synthesisSegment{{Text: req.Text, Parameters: base}}
+
+	if req.TextType == textTypeSsml || (req.TextType == "" && IsSsml(req.Text)) {
+		parsed, err := parseSsml(req.Text)
+		if err != nil {
+			logger.Logger.Error("Invalid SSML", "error", err)
+			return nil, service_errors.NewErrBadRequest(err.Error())
+		}
+
+		for i := range parsed {
+			parsed[i].Parameters = base.combine(parsed[i].Parameters)
+		}
+		segments = parsed
+	}
+
+	return segments, nil
+}
+
+func splitSegments(segments []synthesisSegment, maxLength int, pack bool) []synthesisSegment {
+	var pieces []synthesisSegment
+	for _, segment := range segments {
+		if segment.Text == "" {
+			pieces = append(pieces, segment)
+			continue
+		}
+
+		for _, piece := range segmentText(segment.Text, maxLength, pack) {
+			pieces = append(pieces, synthesisSegment{Text: piece, Parameters: segment.Parameters})
+		}
+	}
+
+	return pieces
+}
+
+func validateSegments(model *model.ModelDto, segments []synthesisSegment) error {
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+
+		if _, _, err := resolveVoiceParameters(&model.Capabilities, segment.Parameters); err != nil {
+			logger.Logger.Error("Unsupported voice parameters", "modelId", model.Id, "error", err)
+			return service_errors.NewErrBadRequest("Unsupported voice parameters: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func checkSamplingRate(current, next int) error {
+	if current != 0 && current != next {
+		logger.Logger.Error("Model returned inconsistent sampling rates", "expected", current, "actual", next)
+		return service_errors.NewErrBadGateway("Model returned inconsistent sampling rates")
+	}
+
+	return nil
+}
+
+func (s *SynthesisServiceImpl) saveHistoryRecord(req *requests.SynthesisRequest, userId string, model *model.ModelDto, resolvedLexicon *lexicon.ResolvedLexicon, response *SynthesisResponse, latency time.Duration) (*history.HistoryRecordDto, error) {
+	durationMs := 0
+	if response.SamplingRate > 0 {
+		durationMs = len(response.Samples) * 1000 / response.SamplingRate
+	}
+
 	historyDto := &history.HistoryRecordDto{
-		UserId: userId,
-		Text:   req.Text,
+		UserId:               userId,
+		Text:                 req.Text,
+		Language:             model.Language,
+		ModelId:              model.Id,
+		ModelName:            model.Name,
+		ModelVersionId:       model.ServingVersionId,
+		ModelVersion:         model.ServingVersion,
+		DurationMs:           durationMs,
+		SampleRate:           response.SamplingRate,
+		CharacterCount:       utf8.RuneCountInString(req.Text),
+		LatencyMs:            int(latency.Milliseconds()),
+		GlobalLexiconVersion: resolvedLexicon.GlobalVersion,
+		UserLexiconVersion:   resolvedLexicon.UserVersion,
+		Words:                response.Words,
+		Sentences:            response.Sentences,
+	}
+
+	encoded, err := s.audioService.EncodeWithBitDepth(response.Samples, response.SamplingRate, response.BitDepth, audio.FormatWav)
+	if err != nil {
+		return nil, err
+	}
+
+	historyAudio := &history.HistoryAudio{
+		Data:        encoded.Data,
+		ContentType: encoded.ContentType,
+		Extension:   encoded.Format.Extension(),
 	}
 
-	_, err := s.historyService.SaveHistoryRecord(historyDto)
-	return err
+	return s.historyService.SaveHistoryRecord(historyDto, historyAudio)
 }
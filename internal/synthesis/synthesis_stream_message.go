@@ -0,0 +1,16 @@
+package synthesis
+
+const (
+	StreamMessageChunk     = "chunk"
+	StreamMessageDone      = "done"
+	StreamMessageCancel    = "cancel"
+	StreamMessageCancelled = "cancelled"
+	StreamMessageError     = "error"
+	StreamMessageHeartbeat = "heartbeat"
+)
+
+type SynthesisStreamMessage struct {
+	Type  string          `json:"type"`
+	Chunk *SynthesisChunk `json:"chunk,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
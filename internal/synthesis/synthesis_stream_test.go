@@ -0,0 +1,206 @@
+package synthesis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"vitaliiPsl/synthesizer/internal/cache"
+	"vitaliiPsl/synthesizer/internal/dsp"
+	"vitaliiPsl/synthesizer/internal/lexicon"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/requests"
+)
+
+type stubModelService struct {
+	model.ModelService
+	model *model.ModelDto
+}
+
+func (s *stubModelService) GetModelById(modelId string) (*model.ModelDto, error) {
+	return s.model, nil
+}
+
+type emptyLexiconService struct {
+	lexicon.LexiconService
+}
+
+func (s *emptyLexiconService) ResolveLexicon(userId, language string) (*lexicon.ResolvedLexicon, error) {
+	return &lexicon.ResolvedLexicon{}, nil
+}
+
+type noopCache struct {
+	cache.SynthesisCache
+}
+
+func (c *noopCache) Get(key cache.CacheKey) (*cache.CachedAudio, bool) {
+	return nil, false
+}
+
+func (c *noopCache) Put(key cache.CacheKey, audio *cache.CachedAudio) {}
+
+type countingModelClient struct {
+	calls  int
+	onCall func(calls int)
+}
+
+func (c *countingModelClient) Synthesize(ctx context.Context, model *model.ModelDto, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	c.calls++
+	if c.onCall != nil {
+		c.onCall(c.calls)
+	}
+
+	return &SynthesisResponse{Samples: make([]float32, 1600), SamplingRate: 16000}, nil
+}
+
+func newStreamingService(client ModelClient) *SynthesisServiceImpl {
+	return &SynthesisServiceImpl{
+		modelService:   &stubModelService{model: &model.ModelDto{Id: "model", Language: "en"}},
+		cache:          &noopCache{},
+		modelClient:    client,
+		normalizer:     NewTextNormalizer(),
+		lexiconService: &emptyLexiconService{},
+		joiner:         newAudioJoiner(),
+		processor:      dsp.NewAudioProcessor(),
+
+		defaultMaxInputLength: defaultMaxInputLength,
+		chunkConcurrency:      1,
+	}
+}
+
+var streamingRequest = &requests.SynthesisRequest{ModelId: "model", Text: "First sentence. Second sentence. Third sentence."}
+
+func TestStreamingSynthesisStopsWhenConsumerFails(t *testing.T) {
+	client := &countingModelClient{}
+	service := newStreamingService(client)
+
+	failure := errors.New("broken pipe")
+	chunks := 0
+	_, err := service.HandleStreamingSynthesisRequest(context.Background(), streamingRequest, "", func(chunk *SynthesisChunk) error {
+		chunks++
+		return failure
+	})
+
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the consumer error, got %v", err)
+	}
+	if chunks != 1 || client.calls != 1 {
+		t.Errorf("expected synthesis to stop after the first chunk, got %d chunks and %d calls", chunks, client.calls)
+	}
+}
+
+func TestStreamingSynthesisStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &countingModelClient{onCall: func(calls int) { cancel() }}
+	service := newStreamingService(client)
+
+	_, err := service.HandleStreamingSynthesisRequest(ctx, streamingRequest, "", func(chunk *SynthesisChunk) error {
+		return ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("expected no sentence after cancellation to be synthesized, got %d calls", client.calls)
+	}
+	if message := streamResultMessage(err); message.Type != StreamMessageCancelled {
+		t.Errorf("expected a %s message, got %s", StreamMessageCancelled, message.Type)
+	}
+}
+
+func TestStreamingSynthesisSendsEverySentence(t *testing.T) {
+	client := &countingModelClient{}
+	service := newStreamingService(client)
+
+	var texts []string
+	_, err := service.HandleStreamingSynthesisRequest(context.Background(), streamingRequest, "", func(chunk *SynthesisChunk) error {
+		if chunk.Text != "" {
+			texts = append(texts, chunk.Text)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 3 || client.calls != 3 {
+		t.Errorf("expected 3 sentences, got %q after %d calls", texts, client.calls)
+	}
+}
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestStreamWriterCancelsOnWriteFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newStreamWriter(&failingWriter{}, func() error { return nil }, cancel)
+
+	if err := stream.send(&SynthesisStreamMessage{Type: StreamMessageChunk}); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the stream context to be cancelled")
+	}
+	if err := stream.send(&SynthesisStreamMessage{Type: StreamMessageDone}); !errors.Is(err, errStreamClosed) {
+		t.Errorf("expected errStreamClosed, got %v", err)
+	}
+}
+
+func TestStreamWriterCancelsOnFlushFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buffer bytes.Buffer
+	stream := newStreamWriter(&buffer, func() error { return errors.New("connection reset") }, cancel)
+
+	if err := stream.send(&SynthesisStreamMessage{Type: StreamMessageChunk}); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the stream context to be cancelled")
+	}
+}
+
+func TestStreamWriterKeepAlive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buffer bytes.Buffer
+	stream := newStreamWriter(&buffer, func() error { return nil }, cancel)
+
+	stop := stream.keepAlive(ctx, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	if !strings.Contains(buffer.String(), `"type":"heartbeat"`) {
+		t.Errorf("expected heartbeats, got %q", buffer.String())
+	}
+	if ctx.Err() != nil {
+		t.Error("expected stopping the heartbeat to leave the stream open")
+	}
+}
+
+func TestStreamWriterKeepAliveNoticesDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := newStreamWriter(&failingWriter{}, func() error { return nil }, cancel)
+	stop := stream.keepAlive(ctx, time.Millisecond)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected a failed heartbeat to cancel the stream")
+	}
+}
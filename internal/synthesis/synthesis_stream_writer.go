@@ -0,0 +1,86 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// how often an idle stream is flushed, so a client that went away is noticed
+// while an upstream call is still in flight
+const streamHeartbeatInterval = 5 * time.Second
+
+var errStreamClosed = errors.New("stream closed")
+
+// streamWriter serializes messages of a chunked stream and cancels the
+// synthesis as soon as one of them can't be delivered
+type streamWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	flush   func() error
+	cancel  context.CancelFunc
+	closed  bool
+}
+
+func newStreamWriter(w io.Writer, flush func() error, cancel context.CancelFunc) *streamWriter {
+	return &streamWriter{
+		encoder: json.NewEncoder(w),
+		flush:   flush,
+		cancel:  cancel,
+	}
+}
+
+func (w *streamWriter) send(message *SynthesisStreamMessage) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errStreamClosed
+	}
+
+	err := w.encoder.Encode(message)
+	if err == nil {
+		err = w.flush()
+	}
+
+	if err != nil {
+		w.closed = true
+		w.cancel()
+		return err
+	}
+
+	return nil
+}
+
+// keepAlive sends heartbeats until ctx is done or a heartbeat fails; the
+// returned func stops it and waits, after which the writer may be released
+func (w *streamWriter) keepAlive(ctx context.Context, interval time.Duration) func() {
+	ctx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.send(&SynthesisStreamMessage{Type: StreamMessageHeartbeat}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		stop()
+		<-done
+	}
+}
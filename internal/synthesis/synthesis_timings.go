@@ -0,0 +1,60 @@
+package synthesis
+
+import (
+	"unicode/utf8"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+// timings of a synthesized piece relative to its own start. Sentences reported
+// by the model are kept, otherwise the piece duration is shared between its
+// sentences in proportion to their length.
+func pieceTimings(text string, response *SynthesisResponse) ([]subtitles.Timing, []subtitles.Timing) {
+	if len(response.Sentences) > 0 || response.SamplingRate == 0 {
+		return response.Words, response.Sentences
+	}
+
+	durationMs := len(response.Samples) * 1000 / response.SamplingRate
+	sentences := splitSentences(text)
+
+	total := 0
+	for _, sentence := range sentences {
+		total += utf8.RuneCountInString(sentence)
+	}
+
+	timings := make([]subtitles.Timing, 0, len(sentences))
+	elapsed := 0
+	for _, sentence := range sentences {
+		elapsed += utf8.RuneCountInString(sentence)
+		timing := subtitles.Timing{Text: sentence, EndMs: durationMs * elapsed / max(total, 1)}
+		if len(timings) > 0 {
+			timing.StartMs = timings[len(timings)-1].EndMs
+		}
+		timings = append(timings, timing)
+	}
+
+	return response.Words, timings
+}
+
+func samplesToMs(samples, samplingRate int) int {
+	if samplingRate == 0 {
+		return 0
+	}
+
+	return samples * 1000 / samplingRate
+}
+
+// moves timings back by the audio trimmed from the start and keeps them
+// within the remaining duration
+func trimTimings(timings []subtitles.Timing, trimmedMs, durationMs int) []subtitles.Timing {
+	if trimmedMs == 0 {
+		return timings
+	}
+
+	shifted := subtitles.Offset(timings, -trimmedMs)
+	for i := range shifted {
+		shifted[i].StartMs = min(max(shifted[i].StartMs, 0), durationMs)
+		shifted[i].EndMs = min(max(shifted[i].EndMs, 0), durationMs)
+	}
+
+	return shifted
+}
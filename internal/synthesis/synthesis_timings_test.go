@@ -0,0 +1,81 @@
+package synthesis
+
+import (
+	"reflect"
+	"testing"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+func TestPieceTimingsSharesDurationBySentenceLength(t *testing.T) {
+	// one second of audio for 10 + 30 runes
+	response := &SynthesisResponse{Samples: make([]float32, 16000), SamplingRate: 16000}
+
+	words, sentences := pieceTimings("Short one. This one is thirty runes long.", response)
+
+	expected := []subtitles.Timing{
+		{Text: "Short one.", StartMs: 0, EndMs: 250},
+		{Text: "This one is thirty runes long.", StartMs: 250, EndMs: 1000},
+	}
+	if words != nil || !reflect.DeepEqual(sentences, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sentences)
+	}
+}
+
+func TestPieceTimingsKeepsModelTimings(t *testing.T) {
+	response := &SynthesisResponse{
+		Samples:      make([]float32, 16000),
+		SamplingRate: 16000,
+		Words:        []subtitles.Timing{{Text: "Hi", StartMs: 10, EndMs: 200}},
+		Sentences:    []subtitles.Timing{{Text: "Hi.", StartMs: 10, EndMs: 250}},
+	}
+
+	words, sentences := pieceTimings("Hi.", response)
+	if !reflect.DeepEqual(words, response.Words) || !reflect.DeepEqual(sentences, response.Sentences) {
+		t.Errorf("expected the model's timings, got %+v and %+v", words, sentences)
+	}
+
+	if _, sentences := pieceTimings("Hi.", &SynthesisResponse{}); sentences != nil {
+		t.Errorf("expected no timings without a sampling rate, got %+v", sentences)
+	}
+}
+
+func TestPieceTimingsPastOneHour(t *testing.T) {
+	response := &SynthesisResponse{Samples: make([]float32, 2*3600*100), SamplingRate: 100}
+
+	_, sentences := pieceTimings("First half. Other half.", response)
+	if sentences[1].StartMs != 3_600_000 || sentences[1].EndMs != 7_200_000 {
+		t.Errorf("expected the second sentence to span the second hour, got %+v", sentences[1])
+	}
+}
+
+func TestTrimTimings(t *testing.T) {
+	timings := []subtitles.Timing{
+		{Text: "lost", StartMs: 0, EndMs: 80},
+		{Text: "kept", StartMs: 100, EndMs: 600},
+		{Text: "cut", StartMs: 900, EndMs: 1500},
+	}
+
+	trimmed := trimTimings(timings, 100, 1000)
+
+	expected := []subtitles.Timing{
+		{Text: "lost", StartMs: 0, EndMs: 0},
+		{Text: "kept", StartMs: 0, EndMs: 500},
+		{Text: "cut", StartMs: 800, EndMs: 1000},
+	}
+	if !reflect.DeepEqual(trimmed, expected) {
+		t.Errorf("expected %+v, got %+v", expected, trimmed)
+	}
+
+	if untouched := trimTimings(timings, 0, 10); !reflect.DeepEqual(untouched, timings) {
+		t.Errorf("expected timings to stay when nothing was trimmed, got %+v", untouched)
+	}
+}
+
+func TestSamplesToMs(t *testing.T) {
+	if ms := samplesToMs(22050*3600+11025, 22050); ms != 3_600_500 {
+		t.Errorf("expected 3600500, got %d", ms)
+	}
+	if ms := samplesToMs(100, 0); ms != 0 {
+		t.Errorf("expected 0 without a sampling rate, got %d", ms)
+	}
+}
@@ -0,0 +1,257 @@
+package synthesis
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// numbers longer than this are read digit by digit
+const maxNumberDigits = 15
+
+const boundary = `(^|[^\p{L}\p{N}])`
+
+var horizontalSpace = regexp.MustCompile(`[ \t\x{00A0}]+`)
+
+type TextNormalizer interface {
+	Normalize(text, language string) string
+	Supports(language string) bool
+}
+
+type TextNormalizerImpl struct {
+	ruleSets map[string][]normalizationRule
+}
+
+// every pattern starts with the boundary group, which is kept as is; the
+// remaining groups are handed to replace together with the rune following
+// the match. Returning false leaves the match untouched.
+type normalizationRule struct {
+	pattern *regexp.Regexp
+	wordEnd bool
+	replace func(groups []string, next rune) (string, bool)
+}
+
+func NewTextNormalizer() *TextNormalizerImpl {
+	return &TextNormalizerImpl{
+		ruleSets: map[string][]normalizationRule{
+			"en": englishRules(),
+			"uk": ukrainianRules(),
+		},
+	}
+}
+
+func (n *TextNormalizerImpl) Supports(language string) bool {
+	_, ok := n.ruleSets[languageCode(language)]
+	return ok
+}
+
+func (n *TextNormalizerImpl) Normalize(text, language string) string {
+	rules, ok := n.ruleSets[languageCode(language)]
+	if !ok {
+		return text
+	}
+
+	for _, rule := range rules {
+		text = rule.apply(text)
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (rule normalizationRule) apply(text string) string {
+	matches := rule.pattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, match := range matches {
+		next, _ := utf8.DecodeRuneInString(text[match[1]:])
+		if next == utf8.RuneError {
+			next = 0
+		}
+
+		if rule.wordEnd && isWordRune(next) {
+			continue
+		}
+
+		groups := make([]string, 0, len(match)/2-2)
+		for i := 4; i < len(match); i += 2 {
+			if match[i] < 0 {
+				groups = append(groups, "")
+				continue
+			}
+			groups = append(groups, text[match[i]:match[i+1]])
+		}
+
+		replacement, ok := rule.replace(groups, next)
+		if !ok {
+			continue
+		}
+
+		result.WriteString(text[last:match[3]])
+		result.WriteString(replacement)
+		last = match[1]
+	}
+	result.WriteString(text[last:])
+
+	return result.String()
+}
+
+func languageCode(language string) string {
+	code := strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	switch code {
+	case "english", "eng":
+		return "en"
+	case "ukrainian", "ukr", "ua":
+		return "uk"
+	default:
+		return code
+	}
+}
+
+func abbreviationRule(abbreviations map[string]string) normalizationRule {
+	keys := make([]string, 0, len(abbreviations))
+	for key := range abbreviations {
+		keys = append(keys, key)
+	}
+
+	// longest first so that "Mrs." wins over "Mr."
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	for i, key := range keys {
+		keys[i] = regexp.QuoteMeta(key)
+	}
+
+	return normalizationRule{
+		pattern: regexp.MustCompile(boundary + `(` + strings.Join(keys, "|") + `)`),
+		replace: func(groups []string, next rune) (string, bool) {
+			abbreviation := groups[0]
+			expansion := abbreviations[abbreviation]
+
+			switch {
+			case !strings.HasSuffix(abbreviation, "."):
+				return expansion, !isWordRune(next)
+			case next == 0:
+				return expansion + ".", true
+			default:
+				return separateFromLetter(expansion, next), true
+			}
+		},
+	}
+}
+
+type urlWords struct {
+	dot, slash, at, dash, underscore, www string
+}
+
+func urlRule(words urlWords) normalizationRule {
+	spoken := strings.NewReplacer(
+		".", " "+words.dot+" ",
+		"/", " "+words.slash+" ",
+		"@", " "+words.at+" ",
+		"-", " "+words.dash+" ",
+		"_", " "+words.underscore+" ",
+	)
+
+	return normalizationRule{
+		pattern: regexp.MustCompile(boundary + `((?i:https?://)?(?:[\w.+-]+@)?(?i:www\.)?[\w-]+(?:\.[\w-]+)*\.(?i:com|org|net|io|dev|edu|gov|info|ua|uk|eu|co)(?:/[^\s]*)?)`),
+		wordEnd: true,
+		replace: func(groups []string, next rune) (string, bool) {
+			url := groups[0]
+			trimmed := strings.TrimRight(url, ".,;:!?)")
+			trailing := url[len(trimmed):]
+
+			lower := strings.ToLower(trimmed)
+			for _, scheme := range []string{"https://", "http://"} {
+				if strings.HasPrefix(lower, scheme) {
+					trimmed = trimmed[len(scheme):]
+					break
+				}
+			}
+
+			prefix := ""
+			if strings.HasPrefix(strings.ToLower(trimmed), "www.") {
+				prefix = words.www + " " + words.dot + " "
+				trimmed = trimmed[4:]
+			}
+
+			return " " + prefix + spoken.Replace(strings.TrimRight(trimmed, "/")) + trailing, true
+		},
+	}
+}
+
+func parseGroupedInt(value, separators string) (int64, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(separators, r) {
+			return -1
+		}
+		return r
+	}, value)
+
+	if len(digits) > maxNumberDigits {
+		return 0, false
+	}
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	return n, err == nil
+}
+
+func parseMinorUnits(fraction string) int64 {
+	if fraction == "" {
+		return 0
+	}
+
+	if len(fraction) == 1 {
+		fraction += "0"
+	}
+
+	n, _ := strconv.ParseInt(fraction[:2], 10, 64)
+	return n
+}
+
+func parseDate(year, month, day string) (int, int, int, bool) {
+	y, errY := strconv.Atoi(year)
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errY != nil || errM != nil || errD != nil || m < 1 || m > 12 || d < 1 || d > 31 {
+		return 0, 0, 0, false
+	}
+
+	return y, m, d, true
+}
+
+func spellDigits(digits string, names [10]string) string {
+	words := make([]string, 0, len(digits))
+	for _, r := range digits {
+		if r >= '0' && r <= '9' {
+			words = append(words, names[r-'0'])
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func separateFromLetter(word string, next rune) string {
+	if unicode.IsLetter(next) {
+		return word + " "
+	}
+
+	return word
+}
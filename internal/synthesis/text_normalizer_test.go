@@ -0,0 +1,137 @@
+package synthesis
+
+import "testing"
+
+func TestNormalizeEnglish(t *testing.T) {
+	normalizer := NewTextNormalizer()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"cardinal", "I have 42 apples", "I have forty-two apples"},
+		{"zero", "0", "zero"},
+		{"large number", "1,234,567", "one million two hundred thirty-four thousand five hundred sixty-seven"},
+		{"negative number", "It is -5 outside", "It is minus five outside"},
+		{"decimal", "Pi is 3.14", "Pi is three point one four"},
+		{"number followed by letters", "a 5kg bag", "a five kg bag"},
+		{"digit string too long", "1234567890123456", "one two three four five six seven eight nine zero one two three four five six"},
+		{"ordinal", "the 21st century", "the twenty-first century"},
+		{"irregular ordinal", "the 12th and 3rd", "the twelfth and third"},
+		{"tens ordinal", "her 40th birthday", "her fortieth birthday"},
+		{"iso date", "Due 2024-03-05.", "Due March fifth, twenty twenty-four."},
+		{"us date", "On 07/04/1776", "On July fourth, seventeen seventy-six"},
+		{"dotted date", "On 05.03.2008", "On March fifth, two thousand eight"},
+		{"oh year", "In 1905-01-01", "In January first, nineteen oh five"},
+		{"invalid date", "2024-13-40", "two thousand twenty-four-thirteen-forty"},
+		{"dollars", "It costs $5", "It costs five dollars"},
+		{"dollars and cents", "It costs $1,000.50", "It costs one thousand dollars and fifty cents"},
+		{"single dollar", "$1.01", "one dollar and one cent"},
+		{"cents only", "$0.99", "ninety-nine cents"},
+		{"pounds", "£2.5", "two pounds and fifty pence"},
+		{"currency code", "20 EUR", "twenty euros"},
+		{"percent", "50% off", "fifty percent off"},
+		{"decimal percent", "2.5%", "two point five percent"},
+		{"abbreviation", "Dr. Smith and Mrs. Jones", "Doctor Smith and Missus Jones"},
+		{"abbreviation at end", "apples, pears, etc.", "apples, pears, et cetera."},
+		{"abbreviation inside word", "Mr.Smith", "Mister Smith"},
+		{"latin abbreviation", "fruit, e.g. apples", "fruit, for example apples"},
+		{"url", "Visit https://example.com/docs.", "Visit example dot com slash docs."},
+		{"www url", "See www.example.org", "See double-u double-u double-u dot example dot org"},
+		{"email", "Write to help@example.com", "Write to help at example dot com"},
+		{"newlines kept", "1\n2", "one\ntwo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := normalizer.Normalize(test.input, "en"); actual != test.expected {
+				t.Errorf("Normalize(%q) = %q, expected %q", test.input, actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeUkrainian(t *testing.T) {
+	normalizer := NewTextNormalizer()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"cardinal", "У мене 42 яблука", "У мене сорок два яблука"},
+		{"zero", "0", "нуль"},
+		{"thousands", "1000", "тисяча"},
+		{"thousands with separator", "21 500", "двадцять одна тисяча п'ятсот"},
+		{"few thousands", "2 000 000", "два мільйони"},
+		{"negative number", "Надворі -5", "Надворі мінус п'ять"},
+		{"decimal", "3,14", "три кома чотирнадцять"},
+		{"decimal with leading zero", "3,05", "три кома нуль п'ять"},
+		{"ordinal masculine", "5-й поверх", "п'ятий поверх"},
+		{"ordinal feminine", "3-я спроба", "третя спроба"},
+		{"ordinal genitive", "до 21-го століття", "до двадцять першого століття"},
+		{"ordinal neuter", "40-е місце", "сорокове місце"},
+		{"ordinal hundreds", "100-й", "сотий"},
+		{"dotted date", "5.03.2024", "п'яте березня дві тисячі двадцять четвертого року"},
+		{"date with year word", "01.09.2000 р.", "перше вересня двохтисячного року"},
+		{"iso date", "2023-12-31", "тридцять перше грудня дві тисячі двадцять третього року"},
+		{"hryvnia one", "1 грн", "одна гривня"},
+		{"hryvnia few", "2 грн", "дві гривні"},
+		{"hryvnia many", "5,50 грн", "п'ять гривень п'ятдесят копійок"},
+		{"hryvnia teens", "12 ₴", "дванадцять гривень"},
+		{"dollars prefix", "$21", "двадцять один долар"},
+		{"dollars few", "$3", "три долари"},
+		{"euro", "€5", "п'ять євро"},
+		{"kopiykas only", "0,01 грн", "одна копійка"},
+		{"abbreviated currency at end", "Ціна 10 грн.", "Ціна десять гривень."},
+		{"percent one", "1%", "один відсоток"},
+		{"percent few", "23%", "двадцять три відсотки"},
+		{"percent many", "11%", "одинадцять відсотків"},
+		{"decimal percent", "2,5%", "два кома п'ять відсотка"},
+		{"abbreviation", "яблука, груші, т.д.", "яблука, груші, так далі."},
+		{"abbreviation street", "вул. Хрещатик", "вулиця Хрещатик"},
+		{"abbreviation inside word", "ппроф. Іваненко", "ппроф. Іваненко"},
+		{"url", "Сайт example.com.ua", "Сайт example крапка com крапка ua"},
+		{"email", "пишіть на info@example.com", "пишіть на info собака example крапка com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := normalizer.Normalize(test.input, "uk"); actual != test.expected {
+				t.Errorf("Normalize(%q) = %q, expected %q", test.input, actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	normalizer := NewTextNormalizer()
+
+	tests := []struct {
+		language  string
+		supported bool
+	}{
+		{"en", true},
+		{"en-US", true},
+		{"English", true},
+		{"uk", true},
+		{"uk_UA", true},
+		{"Ukrainian", true},
+		{"ua", true},
+		{"de", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			if actual := normalizer.Supports(test.language); actual != test.supported {
+				t.Errorf("Supports(%q) = %v, expected %v", test.language, actual, test.supported)
+			}
+		})
+	}
+
+	if actual := normalizer.Normalize("Zahl 42", "de"); actual != "Zahl 42" {
+		t.Errorf("unsupported language should be left unchanged, got %q", actual)
+	}
+}
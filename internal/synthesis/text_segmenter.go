@@ -0,0 +1,128 @@
+package synthesis
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// segmentText splits text into pieces of at most maxLength runes, preferring
+// sentence boundaries, then clause boundaries, then whitespace. With pack set,
+// consecutive pieces are merged back together while they still fit, so that
+// long inputs need as few model calls as possible.
+func segmentText(text string, maxLength int, pack bool) []string {
+	var pieces []string
+	for _, sentence := range splitSentences(text) {
+		pieces = append(pieces, splitLongSentence(sentence, maxLength)...)
+	}
+
+	if !pack || maxLength <= 0 {
+		return pieces
+	}
+
+	return packPieces(pieces, maxLength)
+}
+
+func splitLongSentence(sentence string, maxLength int) []string {
+	if maxLength <= 0 || utf8.RuneCountInString(sentence) <= maxLength {
+		return []string{sentence}
+	}
+
+	var pieces []string
+	for _, clause := range splitClauses(sentence) {
+		if utf8.RuneCountInString(clause) <= maxLength {
+			pieces = append(pieces, clause)
+			continue
+		}
+
+		for _, word := range strings.Fields(clause) {
+			pieces = append(pieces, splitRunes(word, maxLength)...)
+		}
+	}
+
+	return packPieces(pieces, maxLength)
+}
+
+func splitClauses(sentence string) []string {
+	var clauses []string
+	runes := []rune(sentence)
+	start := 0
+
+	for i, r := range runes {
+		if !isClauseTerminator(r) || (i+1 < len(runes) && !unicode.IsSpace(runes[i+1])) {
+			continue
+		}
+
+		if clause := strings.TrimSpace(string(runes[start : i+1])); clause != "" {
+			clauses = append(clauses, clause)
+		}
+		start = i + 1
+	}
+
+	if clause := strings.TrimSpace(string(runes[start:])); clause != "" {
+		clauses = append(clauses, clause)
+	}
+
+	return clauses
+}
+
+func splitRunes(word string, maxLength int) []string {
+	runes := []rune(word)
+	if len(runes) <= maxLength {
+		return []string{word}
+	}
+
+	var parts []string
+	for start := 0; start < len(runes); start += maxLength {
+		parts = append(parts, string(runes[start:min(start+maxLength, len(runes))]))
+	}
+
+	return parts
+}
+
+func packPieces(pieces []string, maxLength int) []string {
+	var packed []string
+	var current strings.Builder
+	currentLength := 0
+
+	for _, piece := range pieces {
+		length := utf8.RuneCountInString(piece)
+		if currentLength > 0 && currentLength+1+length > maxLength {
+			packed = append(packed, current.String())
+			current.Reset()
+			currentLength = 0
+		}
+
+		if currentLength > 0 {
+			current.WriteByte(' ')
+			currentLength++
+		}
+		current.WriteString(piece)
+		currentLength += length
+	}
+
+	if currentLength > 0 {
+		packed = append(packed, current.String())
+	}
+
+	return packed
+}
+
+// whether a pause belongs after the text, as opposed to a piece cut mid-sentence
+func endsSentence(text string) bool {
+	trimmed := strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || isClosingPunctuation(r)
+	})
+
+	last, _ := utf8.DecodeLastRuneInString(trimmed)
+	return isSentenceTerminator(last)
+}
+
+func isClauseTerminator(r rune) bool {
+	switch r {
+	case ',', ';', ':', '—', '–', '，', '；':
+		return true
+	}
+
+	return false
+}
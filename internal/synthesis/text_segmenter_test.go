@@ -0,0 +1,90 @@
+package synthesis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func assertPieceLengths(t *testing.T, name string, pieces []string, maxLength int) {
+	t.Helper()
+
+	for _, piece := range pieces {
+		if length := utf8.RuneCountInString(piece); length > maxLength || length == 0 {
+			t.Errorf("%s: piece %q has %d runes, expected 1 to %d", name, piece, length, maxLength)
+		}
+	}
+}
+
+func TestSegmentTextAtMaxLength(t *testing.T) {
+	// multi-byte runes: the limit counts characters, not bytes
+	text := strings.Repeat("é", 6) + " " + strings.Repeat("ü", 3)
+
+	if pieces := segmentText(text, 10, true); !reflect.DeepEqual(pieces, []string{text}) {
+		t.Errorf("expected text of exactly the max length to stay whole, got %q", pieces)
+	}
+
+	pieces := segmentText(text+"x", 10, true)
+	if !reflect.DeepEqual(pieces, []string{strings.Repeat("é", 6), strings.Repeat("ü", 3) + "x"}) {
+		t.Errorf("expected text one rune over the limit to be split at the space, got %q", pieces)
+	}
+}
+
+func TestSegmentTextLongWord(t *testing.T) {
+	word := strings.Repeat("a", 25)
+
+	pieces := segmentText(word, 10, true)
+	if !reflect.DeepEqual(pieces, []string{strings.Repeat("a", 10), strings.Repeat("a", 10), strings.Repeat("a", 5)}) {
+		t.Errorf("expected the word to be cut into pieces of the max length, got %q", pieces)
+	}
+
+	pieces = segmentText("go "+word+" now.", 10, true)
+	assertPieceLengths(t, "word in a sentence", pieces, 10)
+	if joined := strings.ReplaceAll(strings.Join(pieces, ""), " ", ""); joined != "go"+word+"now." {
+		t.Errorf("expected no text to be lost, got %q", pieces)
+	}
+}
+
+func TestSegmentTextBoundaries(t *testing.T) {
+	cases := []struct {
+		name      string
+		text      string
+		maxLength int
+		pack      bool
+		expected  []string
+	}{
+		{name: "no limit", text: "One. Two.", maxLength: 0, pack: true, expected: []string{"One.", "Two."}},
+		{name: "packed sentences", text: "One. Two. Three.", maxLength: 10, pack: true, expected: []string{"One. Two.", "Three."}},
+		{name: "unpacked sentences", text: "One. Two. Three.", maxLength: 10, pack: false, expected: []string{"One.", "Two.", "Three."}},
+		{name: "clauses first", text: "first part, second part", maxLength: 15, pack: false, expected: []string{"first part,", "second part"}},
+		{name: "words when a clause is too long", text: "alpha beta gamma delta", maxLength: 11, pack: false, expected: []string{"alpha beta", "gamma delta"}},
+		{name: "decimal point", text: "Pi is 3.14 today.", maxLength: 100, pack: false, expected: []string{"Pi is 3.14 today."}},
+		{name: "line breaks", text: "Title\nBody text", maxLength: 100, pack: false, expected: []string{"Title", "Body text"}},
+	}
+
+	for _, c := range cases {
+		pieces := segmentText(c.text, c.maxLength, c.pack)
+		if !reflect.DeepEqual(pieces, c.expected) {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, pieces)
+		}
+	}
+}
+
+func TestEndsSentence(t *testing.T) {
+	cases := map[string]bool{
+		"Done.":         true,
+		"Really?! ":     true,
+		`He said "go."`: true,
+		"(see above.)":  true,
+		"first part,":   false,
+		"cut mid":       false,
+		"":              false,
+	}
+
+	for text, expected := range cases {
+		if endsSentence(text) != expected {
+			t.Errorf("%q: expected %v", text, expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package synthesis
+
+import "math"
+
+const (
+	stretchFrameMs     = 30
+	stretchToleranceMs = 8
+)
+
+// timeStretch changes the tempo of speech without changing its pitch using
+// waveform-similarity overlap-add: every frame is shifted within a small
+// tolerance so that it lines up with the natural continuation of the previous one
+func timeStretch(samples []float32, samplingRate int, rate float64) []float32 {
+	frame := samplingRate * stretchFrameMs / 1000
+	if rate == 1 || rate <= 0 || frame < 4 || len(samples) < 2*frame {
+		return samples
+	}
+
+	tolerance := samplingRate * stretchToleranceMs / 1000
+	synthesisHop := frame / 2
+	analysisHop := float64(synthesisHop) * rate
+
+	window := make([]float32, frame)
+	for i := range window {
+		window[i] = float32(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frame)))
+	}
+
+	outLength := int(float64(len(samples)) / rate)
+	out := make([]float32, outLength+frame)
+	weights := make([]float32, outLength+frame)
+
+	previous := 0
+	for k := 0; ; k++ {
+		outPos := k * synthesisHop
+		nominal := int(float64(k) * analysisHop)
+		if outPos+frame > len(out) || nominal+frame > len(samples) {
+			break
+		}
+
+		position := nominal
+		if k > 0 {
+			position = bestAlignment(samples, previous+synthesisHop, nominal, tolerance, frame, synthesisHop)
+		}
+
+		for i := 0; i < frame; i++ {
+			out[outPos+i] += samples[position+i] * window[i]
+			weights[outPos+i] += window[i]
+		}
+		previous = position
+	}
+
+	for i := range out {
+		if weights[i] > 1e-3 {
+			out[i] /= weights[i]
+		}
+	}
+
+	return out[:outLength]
+}
+
+func bestAlignment(samples []float32, natural, nominal, tolerance, frame, overlap int) int {
+	if natural+overlap > len(samples) {
+		return nominal
+	}
+
+	best := nominal
+	bestScore := math.Inf(-1)
+	for delta := -tolerance; delta <= tolerance; delta++ {
+		candidate := nominal + delta
+		if candidate < 0 || candidate+frame > len(samples) {
+			continue
+		}
+
+		var score float64
+		for i := 0; i < overlap; i++ {
+			score += float64(samples[natural+i]) * float64(samples[candidate+i])
+		}
+
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	return best
+}
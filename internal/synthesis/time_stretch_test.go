@@ -0,0 +1,57 @@
+package synthesis
+
+import (
+	"math"
+	"testing"
+)
+
+func sineWave(frequency float64, samplingRate, length int) []float32 {
+	samples := make([]float32, length)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*frequency*float64(i)/float64(samplingRate)))
+	}
+	return samples
+}
+
+func TestTimeStretchLength(t *testing.T) {
+	samplingRate := 16000
+	samples := sineWave(220, samplingRate, samplingRate)
+
+	for _, rate := range []float64{0.25, 0.5, 0.8, 1.25, 2, 4} {
+		stretched := timeStretch(samples, samplingRate, rate)
+
+		expected := int(float64(len(samples)) / rate)
+		if len(stretched) != expected {
+			t.Errorf("rate %g: expected %d samples, got %d", rate, expected, len(stretched))
+		}
+
+		// the middle of the output keeps the level of the input
+		var peak float32
+		for _, sample := range stretched[len(stretched)/4 : len(stretched)*3/4] {
+			peak = max(peak, sample)
+		}
+		if peak < 0.4 || peak > 0.6 {
+			t.Errorf("rate %g: expected a peak near 0.5, got %g", rate, peak)
+		}
+	}
+}
+
+func TestTimeStretchLeavesInputAlone(t *testing.T) {
+	samplingRate := 16000
+
+	cases := []struct {
+		name    string
+		samples []float32
+		rate    float64
+	}{
+		{name: "unit rate", samples: sineWave(220, samplingRate, samplingRate), rate: 1},
+		{name: "invalid rate", samples: sineWave(220, samplingRate, samplingRate), rate: 0},
+		{name: "too short", samples: sineWave(220, samplingRate, 100), rate: 2},
+	}
+
+	for _, c := range cases {
+		if stretched := timeStretch(c.samples, samplingRate, c.rate); len(stretched) != len(c.samples) {
+			t.Errorf("%s: expected %d samples, got %d", c.name, len(c.samples), len(stretched))
+		}
+	}
+}
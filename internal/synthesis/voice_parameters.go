@@ -0,0 +1,170 @@
+package synthesis
+
+import (
+	"fmt"
+	"math"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/requests"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+const (
+	minRate = 0.25
+	maxRate = 4.0
+)
+
+type VoiceParameters struct {
+	Rate            float64 `json:"rate,omitempty"`
+	Pitch           float64 `json:"pitch,omitempty"`
+	VolumeDb        float64 `json:"volume_db,omitempty"`
+	Speaker         string  `json:"speaker,omitempty"`
+	Style           string  `json:"style,omitempty"`
+	Phonemes        string  `json:"phonemes,omitempty"`
+	PhonemeAlphabet string  `json:"phoneme_alphabet,omitempty"`
+}
+
+// adjustments the model can't make itself, applied to the returned samples
+type postProcessing struct {
+	Stretch float64
+	GainDb  float64
+}
+
+func voiceParametersFromOptions(options requests.VoiceOptions) VoiceParameters {
+	return VoiceParameters{
+		Rate:     options.Rate,
+		Pitch:    options.Pitch,
+		VolumeDb: options.Volume,
+		Speaker:  options.Speaker,
+		Style:    options.Style,
+	}
+}
+
+func (p VoiceParameters) cacheVariant() string {
+	if p == (VoiceParameters{}) {
+		return ""
+	}
+
+	return fmt.Sprintf("rate=%g;pitch=%g;volume=%g;speaker=%s;style=%s;phonemes=%s;alphabet=%s", p.Rate, p.Pitch, p.VolumeDb, p.Speaker, p.Style, p.Phonemes, p.PhonemeAlphabet)
+}
+
+func (p VoiceParameters) combine(child VoiceParameters) VoiceParameters {
+	rate := p.Rate
+	if child.Rate != 0 {
+		if rate == 0 {
+			rate = 1
+		}
+		rate *= child.Rate
+	}
+
+	return VoiceParameters{
+		Rate:            rate,
+		Pitch:           p.Pitch + child.Pitch,
+		VolumeDb:        p.VolumeDb + child.VolumeDb,
+		Speaker:         p.Speaker,
+		Style:           p.Style,
+		Phonemes:        child.Phonemes,
+		PhonemeAlphabet: child.PhonemeAlphabet,
+	}
+}
+
+// splits the requested parameters into those sent to the model and those
+// emulated in post-processing, rejecting values the model can't honour
+func resolveVoiceParameters(capabilities *model.ModelCapabilities, params VoiceParameters) (VoiceParameters, postProcessing, error) {
+	native := VoiceParameters{
+		Speaker:         params.Speaker,
+		Style:           params.Style,
+		Phonemes:        params.Phonemes,
+		PhonemeAlphabet: params.PhonemeAlphabet,
+	}
+	post := postProcessing{Stretch: 1}
+
+	if params.Rate != 0 && params.Rate != 1 {
+		switch {
+		case capabilities.Rate != nil && !capabilities.Rate.Contains(params.Rate):
+			return native, post, outOfRange("rate", params.Rate, capabilities.Rate)
+		case capabilities.Rate != nil:
+			native.Rate = params.Rate
+		case params.Rate < minRate || params.Rate > maxRate:
+			return native, post, fmt.Errorf("rate %g is outside the supported range [%g, %g]", params.Rate, minRate, maxRate)
+		default:
+			post.Stretch = params.Rate
+		}
+	}
+
+	if params.Pitch != 0 {
+		if capabilities.Pitch == nil {
+			return native, post, fmt.Errorf("model does not support pitch adjustment")
+		}
+
+		if !capabilities.Pitch.Contains(params.Pitch) {
+			return native, post, outOfRange("pitch", params.Pitch, capabilities.Pitch)
+		}
+		native.Pitch = params.Pitch
+	}
+
+	if params.VolumeDb != 0 {
+		switch {
+		case capabilities.Volume != nil && !capabilities.Volume.Contains(params.VolumeDb):
+			return native, post, outOfRange("volume", params.VolumeDb, capabilities.Volume)
+		case capabilities.Volume != nil:
+			native.VolumeDb = params.VolumeDb
+		default:
+			post.GainDb = params.VolumeDb
+		}
+	}
+
+	if params.Speaker != "" && !capabilities.SupportsSpeaker(params.Speaker) {
+		return native, post, fmt.Errorf("model does not support speaker %q", params.Speaker)
+	}
+
+	if params.Style != "" && !capabilities.SupportsStyle(params.Style) {
+		return native, post, fmt.Errorf("model does not support style %q", params.Style)
+	}
+
+	return native, post, nil
+}
+
+func outOfRange(name string, value float64, allowed *model.ParameterRange) error {
+	return fmt.Errorf("%s %g is outside the model's supported range [%g, %g]", name, value, allowed.Min, allowed.Max)
+}
+
+func (p postProcessing) apply(samples []float32, samplingRate int) []float32 {
+	return applyGain(timeStretch(samples, samplingRate, p.Stretch), p.GainDb)
+}
+
+// applies post processing to a model response, keeping timings in step with stretched audio
+func (p postProcessing) response(response *SynthesisResponse) *SynthesisResponse {
+	processed := &SynthesisResponse{
+		Samples:      p.apply(response.Samples, response.SamplingRate),
+		SamplingRate: response.SamplingRate,
+		Words:        response.Words,
+		Sentences:    response.Sentences,
+	}
+
+	// audio too short to stretch comes back as is, and so do its timings
+	if p.Stretch != 1 && len(processed.Samples) != len(response.Samples) {
+		processed.Words = subtitles.Scale(response.Words, 1/p.Stretch)
+		processed.Sentences = subtitles.Scale(response.Sentences, 1/p.Stretch)
+	}
+
+	return processed
+}
+
+func applyGain(samples []float32, volumeDb float64) []float32 {
+	if volumeDb == 0 {
+		return samples
+	}
+
+	gain := float32(math.Pow(10, volumeDb/20))
+	scaled := make([]float32, len(samples))
+	for i, sample := range samples {
+		// boosting must not push samples past full scale
+		scaled[i] = min(max(sample*gain, -1), 1)
+	}
+
+	return scaled
+}
+
+func silence(duration float64, samplingRate int) []float32 {
+	return make([]float32, int(duration*float64(samplingRate)))
+}
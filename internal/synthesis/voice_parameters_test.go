@@ -0,0 +1,123 @@
+package synthesis
+
+import (
+	"math"
+	"testing"
+	"vitaliiPsl/synthesizer/internal/model"
+	"vitaliiPsl/synthesizer/internal/subtitles"
+)
+
+func TestResolveVoiceParameters(t *testing.T) {
+	native := &model.ModelCapabilities{
+		Rate:     &model.ParameterRange{Min: 0.5, Max: 2},
+		Pitch:    &model.ParameterRange{Min: -5, Max: 5},
+		Volume:   &model.ParameterRange{Min: -10, Max: 10},
+		Speakers: []string{"anna"},
+		Styles:   []string{"calm"},
+	}
+	plain := &model.ModelCapabilities{}
+
+	cases := []struct {
+		name         string
+		capabilities *model.ModelCapabilities
+		params       VoiceParameters
+		native       VoiceParameters
+		post         postProcessing
+		fails        bool
+	}{
+		{name: "defaults", capabilities: plain, post: postProcessing{Stretch: 1}},
+		{name: "unit rate", capabilities: plain, params: VoiceParameters{Rate: 1}, post: postProcessing{Stretch: 1}},
+		{name: "native rate", capabilities: native, params: VoiceParameters{Rate: 1.5}, native: VoiceParameters{Rate: 1.5}, post: postProcessing{Stretch: 1}},
+		{name: "native rate out of range", capabilities: native, params: VoiceParameters{Rate: 3}, fails: true},
+		{name: "stretched rate", capabilities: plain, params: VoiceParameters{Rate: 3}, post: postProcessing{Stretch: 3}},
+		{name: "stretched rate lower bound", capabilities: plain, params: VoiceParameters{Rate: 0.25}, post: postProcessing{Stretch: 0.25}},
+		{name: "stretched rate too slow", capabilities: plain, params: VoiceParameters{Rate: 0.2}, fails: true},
+		{name: "stretched rate too fast", capabilities: plain, params: VoiceParameters{Rate: 4.5}, fails: true},
+		{name: "native pitch", capabilities: native, params: VoiceParameters{Pitch: -5}, native: VoiceParameters{Pitch: -5}, post: postProcessing{Stretch: 1}},
+		{name: "pitch out of range", capabilities: native, params: VoiceParameters{Pitch: 6}, fails: true},
+		{name: "pitch unsupported", capabilities: plain, params: VoiceParameters{Pitch: 1}, fails: true},
+		{name: "native volume", capabilities: native, params: VoiceParameters{VolumeDb: 10}, native: VoiceParameters{VolumeDb: 10}, post: postProcessing{Stretch: 1}},
+		{name: "volume out of range", capabilities: native, params: VoiceParameters{VolumeDb: -12}, fails: true},
+		{name: "emulated volume", capabilities: plain, params: VoiceParameters{VolumeDb: -12}, post: postProcessing{Stretch: 1, GainDb: -12}},
+		{name: "speaker and style", capabilities: native, params: VoiceParameters{Speaker: "anna", Style: "calm"}, native: VoiceParameters{Speaker: "anna", Style: "calm"}, post: postProcessing{Stretch: 1}},
+		{name: "unknown speaker", capabilities: native, params: VoiceParameters{Speaker: "boris"}, fails: true},
+		{name: "unknown style", capabilities: plain, params: VoiceParameters{Style: "calm"}, fails: true},
+		{name: "mixed", capabilities: &model.ModelCapabilities{Pitch: native.Pitch}, params: VoiceParameters{Rate: 2, Pitch: 2, VolumeDb: 6}, native: VoiceParameters{Pitch: 2}, post: postProcessing{Stretch: 2, GainDb: 6}},
+	}
+
+	for _, c := range cases {
+		resolved, post, err := resolveVoiceParameters(c.capabilities, c.params)
+		if c.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if resolved != c.native {
+			t.Errorf("%s: expected native %+v, got %+v", c.name, c.native, resolved)
+		}
+		if post != c.post {
+			t.Errorf("%s: expected post-processing %+v, got %+v", c.name, c.post, post)
+		}
+	}
+}
+
+func TestApplyGain(t *testing.T) {
+	samples := []float32{0.25, -0.25, 0.9, -0.9, 0}
+
+	cases := []struct {
+		name     string
+		volumeDb float64
+		expected []float32
+	}{
+		{name: "unchanged", volumeDb: 0, expected: samples},
+		{name: "halved", volumeDb: -20 * math.Log10(2), expected: []float32{0.125, -0.125, 0.45, -0.45, 0}},
+		{name: "doubled and clamped", volumeDb: 20 * math.Log10(2), expected: []float32{0.5, -0.5, 1, -1, 0}},
+	}
+
+	for _, c := range cases {
+		gained := applyGain(samples, c.volumeDb)
+		if len(gained) != len(c.expected) {
+			t.Fatalf("%s: expected %d samples, got %d", c.name, len(c.expected), len(gained))
+		}
+
+		for i := range gained {
+			if math.Abs(float64(gained[i]-c.expected[i])) > 1e-6 {
+				t.Errorf("%s: sample %d expected %g, got %g", c.name, i, c.expected[i], gained[i])
+			}
+		}
+	}
+}
+
+func TestPostProcessingScalesTimings(t *testing.T) {
+	samplingRate := 8000
+	timings := []subtitles.Timing{{Text: "hello", StartMs: 0, EndMs: 400}, {Text: "world", StartMs: 400, EndMs: 1000}}
+
+	cases := []struct {
+		name     string
+		stretch  float64
+		samples  int
+		expected []subtitles.Timing
+	}{
+		{name: "unstretched", stretch: 1, samples: samplingRate, expected: timings},
+		{name: "faster", stretch: 2, samples: samplingRate, expected: []subtitles.Timing{{Text: "hello", StartMs: 0, EndMs: 200}, {Text: "world", StartMs: 200, EndMs: 500}}},
+		{name: "slower", stretch: 0.5, samples: samplingRate, expected: []subtitles.Timing{{Text: "hello", StartMs: 0, EndMs: 800}, {Text: "world", StartMs: 800, EndMs: 2000}}},
+		{name: "too short to stretch", stretch: 2, samples: 10, expected: timings},
+	}
+
+	for _, c := range cases {
+		response := &SynthesisResponse{Samples: make([]float32, c.samples), SamplingRate: samplingRate, Words: timings}
+		processed := postProcessing{Stretch: c.stretch}.response(response)
+
+		for i, timing := range processed.Words {
+			if timing != c.expected[i] {
+				t.Errorf("%s: word %d expected %+v, got %+v", c.name, i, c.expected[i], timing)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package synthesis
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"vitaliiPsl/synthesizer/internal/audio"
+	"vitaliiPsl/synthesizer/internal/model"
+)
+
+// WavBackend posts the same payload as JsonBackend and expects a wav file back
+type WavBackend struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+func NewWavBackend(client *http.Client) *WavBackend {
+	return &WavBackend{client: client, maxBytes: maxResponseBytesFromEnv()}
+}
+
+func (b *WavBackend) Synthesize(ctx context.Context, model *model.ModelDto, url, text string, params VoiceParameters) (*SynthesisResponse, error) {
+	body, err := postJson(ctx, b.client, url, newModelPayload(text, params), map[string]string{"Accept": "audio/wav"}, b.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeWavResponse(body)
+}
+
+func decodeWavResponse(body []byte) (*SynthesisResponse, error) {
+	decoded, err := audio.DecodeWav(body)
+	if err != nil {
+		return nil, &upstreamError{err: fmt.Errorf("malformed model response: %v", err)}
+	}
+
+	return &SynthesisResponse{Samples: decoded.Samples, SamplingRate: decoded.SamplingRate, Channels: decoded.Channels}, nil
+}
@@ -18,10 +18,11 @@ type UserService interface {
 
 type UserServiceImpl struct {
 	repository UserRepository
+	listeners  []UserStatusListener
 }
 
-func NewUserService(repository UserRepository) *UserServiceImpl {
-	return &UserServiceImpl{repository: repository}
+func NewUserService(repository UserRepository, listeners ...UserStatusListener) *UserServiceImpl {
+	return &UserServiceImpl{repository: repository, listeners: listeners}
 }
 
 func (s *UserServiceImpl) SaveUser(userDto *UserDto) (*UserDto, error) {
@@ -62,6 +63,7 @@ func (s *UserServiceImpl) UpdateUser(id string, userDto *UserDto) (*UserDto, err
 		return nil, service_errors.NewErrInternalServer("Failed to find user")
 	}
 
+	previousStatus := existingUser.Status
 	if userDto.Email != "" {
 		existingUser.Email = userDto.Email
 	}
@@ -89,6 +91,12 @@ func (s *UserServiceImpl) UpdateUser(id string, userDto *UserDto) (*UserDto, err
 		return nil, service_errors.NewErrInternalServer("Failed to update user")
 	}
 
+	if existingUser.Status != previousStatus {
+		for _, listener := range s.listeners {
+			listener.OnUserStatusChanged(existingUser.Id, existingUser.Status)
+		}
+	}
+
 	logger.Logger.Info("Updated user successfully.", "id", id)
 	updatedDto := ToUserDto(existingUser)
 	return updatedDto, nil
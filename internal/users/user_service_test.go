@@ -0,0 +1,52 @@
+package users
+
+import "testing"
+
+type memoryUserRepository struct {
+	UserRepository
+	users map[string]*User
+}
+
+func (r *memoryUserRepository) FindById(id string) (*User, error) {
+	user := *r.users[id]
+	return &user, nil
+}
+
+func (r *memoryUserRepository) Save(user *User) error {
+	stored := *user
+	r.users[user.Id] = &stored
+	return nil
+}
+
+type recordingStatusListener struct {
+	changes []UserStatus
+}
+
+func (l *recordingStatusListener) OnUserStatusChanged(userId string, status UserStatus) {
+	l.changes = append(l.changes, status)
+}
+
+func TestUpdateUserNotifiesStatusChanges(t *testing.T) {
+	repository := &memoryUserRepository{users: map[string]*User{
+		"user-1": {Id: "user-1", Email: "user@example.com", Status: StatusActive},
+	}}
+	listener := &recordingStatusListener{}
+	service := NewUserService(repository, listener)
+
+	if _, err := service.UpdateUser("user-1", &UserDto{Username: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.UpdateUser("user-1", &UserDto{Status: StatusActive}); err != nil {
+		t.Fatal(err)
+	}
+	if len(listener.changes) != 0 {
+		t.Fatalf("expected no notification without a status change, got %v", listener.changes)
+	}
+
+	if _, err := service.UpdateUser("user-1", &UserDto{Status: StatusBlocked}); err != nil {
+		t.Fatal(err)
+	}
+	if len(listener.changes) != 1 || listener.changes[0] != StatusBlocked {
+		t.Errorf("expected one blocked notification, got %v", listener.changes)
+	}
+}
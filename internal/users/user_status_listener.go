@@ -0,0 +1,5 @@
+package users
+
+type UserStatusListener interface {
+	OnUserStatusChanged(userId string, status UserStatus)
+}
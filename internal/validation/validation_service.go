@@ -67,6 +67,15 @@ func (vs *ValidationService) ValidatePasswordResetRequest(request *requests.Pass
 	return validatePassword(request.Password)
 }
 
+func (vs *ValidationService) ValidateRefreshTokenRequest(request *requests.RefreshTokenRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
 func (vs *ValidationService) ValidateSynthesisRequest(request *requests.SynthesisRequest) error {
 	if err := vs.v.Struct(request); err != nil {
 		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
@@ -76,6 +85,33 @@ func (vs *ValidationService) ValidateSynthesisRequest(request *requests.Synthesi
 	return nil
 }
 
+func (vs *ValidationService) ValidateNormalizationRequest(request *requests.NormalizationRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateSynthesisJobRequest(request *requests.SynthesisJobRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateBatchSynthesisRequest(request *requests.BatchSynthesisRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
 func (vs *ValidationService) ValidateModelRequest(request *requests.ModelRequest) error {
 	if err := vs.v.Struct(request); err != nil {
 		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
@@ -85,6 +121,60 @@ func (vs *ValidationService) ValidateModelRequest(request *requests.ModelRequest
 	return nil
 }
 
+func (vs *ValidationService) ValidateReplicaRequest(request *requests.ReplicaRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateModelVersionRequest(request *requests.ModelVersionRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateCanaryRequest(request *requests.CanaryRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateLanguageRuleRequest(request *requests.LanguageRuleRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateLexiconEntryRequest(request *requests.LexiconEntryRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
+func (vs *ValidationService) ValidateLexiconEntryUpdateRequest(request *requests.LexiconEntryUpdateRequest) error {
+	if err := vs.v.Struct(request); err != nil {
+		logger.Logger.Error("Request didn't pass validation", "message", err.Error())
+		return service_errors.NewErrBadRequest("Request didn't pass validation")
+	}
+
+	return nil
+}
+
 func validatePassword(password string) error {
 	var hasUpper, hasLower, hasNumber, hasSpecial bool
 